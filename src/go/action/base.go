@@ -1,23 +1,61 @@
 package action
 
-import "fmt"
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
 
 type Action string
 
+// Built-in actions, always registered.
 const (
 	ActionRead  Action = "READ"
 	ActionWrite Action = "WRITE"
 )
 
-var ErrInvalidAction = fmt.Errorf("invalid Action")
+var builtinActions = []Action{ActionRead, ActionWrite}
+
+// Action names become relationship types in the graph, so they are restricted to identifiers
+// and may not clash with the relationship types used for the hierarchy.
+var actionNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+var reservedActionNames = []string{"CHILD_OF", "HAS_POLICY"}
+
+var ErrInvalidAction = db.NewError(db.ErrInvalidInput, "invalid Action")
+var ErrInvalidActionName = db.NewError(db.ErrInvalidInput, "invalid Action name: must start with a letter and only contain letters, digits and underscores (max 64 characters)")
+var ErrReservedActionName = db.NewError(db.ErrInvalidInput, "reserved Action name")
+
+// ValidateName checks that the name can be used as an Action.
+func ValidateName(name string) error {
+	if !actionNamePattern.MatchString(name) {
+		return ErrInvalidActionName
+	}
+	if slices.Contains(reservedActionNames, name) {
+		return ErrReservedActionName
+	}
+	return nil
+}
+
+// IsBuiltin reports whether the action is one of the always registered actions.
+func (a Action) IsBuiltin() bool {
+	return slices.Contains(builtinActions, a)
+}
+
+// FromString returns the Action with the given name if it is built-in or registered in the store.
+func FromString(ctx context.Context, s string) (Action, error) {
+	if Action(s).IsBuiltin() {
+		return Action(s), nil
+	}
+
+	actions, err := db.FromContext(ctx).GetActions(ctx)
+	if err != nil {
+		return "", err
+	}
 
-func FromString(s string) (Action, error) {
-	switch s {
-	case "READ":
-		return ActionRead, nil
-	case "WRITE":
-		return ActionWrite, nil
-	default:
+	if !slices.Contains(actions, s) {
 		return "", ErrInvalidAction
 	}
+	return Action(s), nil
 }
@@ -0,0 +1,75 @@
+package action
+
+import (
+	"context"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
+
+var ErrActionExists = db.NewError(db.ErrConflict, "action already registered")
+var ErrBuiltinAction = db.NewError(db.ErrInvalidInput, "built-in actions cannot be deleted")
+var ErrActionInUse = db.NewError(db.ErrConflict, "action is used by existing policies")
+
+// Create registers the action in the store.
+func (a Action) Create(ctx context.Context) (Action, error) {
+	if err := ValidateName(string(a)); err != nil {
+		return "", err
+	}
+
+	// The check and the creation share a transaction, so the action cannot be registered in between
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := FromString(ctx, string(a)); err == nil {
+			return ErrActionExists
+		}
+		return db.FromContext(ctx).CreateAction(ctx, string(a))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return a, nil
+}
+
+// List returns the built-in and registered actions.
+func List(ctx context.Context) ([]Action, error) {
+	names, err := db.FromContext(ctx).GetActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := slices.Clone(builtinActions)
+	for _, name := range names {
+		if !slices.Contains(actions, Action(name)) {
+			actions = append(actions, Action(name))
+		}
+	}
+	slices.Sort(actions)
+
+	return actions, nil
+}
+
+// Delete removes the action from the store. Actions still used by policies cannot be deleted.
+func (a Action) Delete(ctx context.Context) error {
+	if a.IsBuiltin() {
+		return ErrBuiltinAction
+	}
+
+	// The checks and the deletion share a transaction, so no policy can start using the action in between
+	return db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := FromString(ctx, string(a)); err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		policies, err := store.GetPolicies(ctx, db.PolicyFilter{Action: string(a)})
+		if err != nil {
+			return err
+		}
+		if len(policies) > 0 {
+			return ErrActionInUse
+		}
+
+		return store.DeleteAction(ctx, string(a))
+	})
+}
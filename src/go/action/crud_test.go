@@ -0,0 +1,122 @@
+package action_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestActionCrud(t *testing.T) {
+	db.TestContainer(t)
+
+	testActionCrud(t)
+}
+
+func TestActionCrudInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testActionCrud(t)
+}
+
+func testActionCrud(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupIndexes(ctx)
+
+	t.Run("Create validates names", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			action   action.Action
+			expected error
+		}{
+			{"empty", "", action.ErrInvalidActionName},
+			{"leading digit", "1READ", action.ErrInvalidActionName},
+			{"with spaces", "READ ALL", action.ErrInvalidActionName},
+			{"reserved", "CHILD_OF", action.ErrReservedActionName},
+			{"built-in", action.ActionRead, action.ErrActionExists},
+		}
+
+		for _, tc := range testCases {
+			_, err := tc.action.Create(ctx)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, err)
+			}
+		}
+	})
+
+	t.Run("Create, list and delete", func(t *testing.T) {
+		if _, err := action.FromString(ctx, "DEPLOY"); !errors.Is(err, action.ErrInvalidAction) {
+			t.Fatalf("Expected DEPLOY to be invalid before creation, but got %v", err)
+		}
+
+		if _, err := action.Action("DEPLOY").Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := action.Action("DEPLOY").Create(ctx); !errors.Is(err, action.ErrActionExists) {
+			t.Errorf("Expected %v, but got %v", action.ErrActionExists, err)
+		}
+
+		actions, err := action.List(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []action.Action{"DEPLOY", action.ActionRead, action.ActionWrite}
+		if !reflect.DeepEqual(actions, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actions)
+		}
+
+		if err := action.Action("DEPLOY").Delete(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := action.FromString(ctx, "DEPLOY"); !errors.Is(err, action.ErrInvalidAction) {
+			t.Errorf("Expected DEPLOY to be invalid after deletion, but got %v", err)
+		}
+	})
+
+	t.Run("Delete refuses built-in and unknown actions", func(t *testing.T) {
+		if err := action.ActionWrite.Delete(ctx); !errors.Is(err, action.ErrBuiltinAction) {
+			t.Errorf("Expected %v, but got %v", action.ErrBuiltinAction, err)
+		}
+		if err := action.Action("UNKNOWN").Delete(ctx); !errors.Is(err, action.ErrInvalidAction) {
+			t.Errorf("Expected %v, but got %v", action.ErrInvalidAction, err)
+		}
+	})
+
+	t.Run("Custom action in policies and queries", func(t *testing.T) {
+		p1, _ := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.Create(ctx)
+		r1, _ := resource.Resource{Name: "Resource1"}.Create(ctx)
+		rootSpecifier, _ := specifier.NewSpecifier("*", "*").Create(ctx)
+		specifier.NewSpecifier("Env", "*").CreateAsChildOf(ctx, rootSpecifier)
+
+		if _, err := (policy.Policy{Subject: p1, Resource: r1, Action: "APPROVE"}).Create(ctx); !errors.Is(err, action.ErrInvalidAction) {
+			t.Errorf("Expected policy with unregistered action to fail with %v, but got %v", action.ErrInvalidAction, err)
+		}
+
+		approve, err := action.Action("APPROVE").Create(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := (policy.Policy{Subject: p1, Resource: r1, Action: approve}).Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if result := query.Can(p1).Perform(approve).On(r1).Query(ctx); !result.Can || result.Err != nil {
+			t.Errorf("Expected Principal1 to APPROVE Resource1, got %v", result)
+		}
+		if result := query.Can(p1).Perform(action.ActionRead).On(r1).Query(ctx); result.Can {
+			t.Errorf("Expected Principal1 not to READ Resource1")
+		}
+
+		if err := approve.Delete(ctx); !errors.Is(err, action.ErrActionInUse) {
+			t.Errorf("Expected %v, but got %v", action.ErrActionInUse, err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package action
+
+import (
+	"context"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
+
+var ErrActionImplicationCycle = db.NewError(db.ErrConflict, "action implication would create a cycle")
+
+// Implies records that holding the action also grants the implied action,
+// e.g. `ActionWrite.Implies(ctx, ActionRead)`.
+func (a Action) Implies(ctx context.Context, implied Action) error {
+	if _, err := FromString(ctx, string(a)); err != nil {
+		return err
+	}
+	if _, err := FromString(ctx, string(implied)); err != nil {
+		return err
+	}
+
+	// The implied action must not already imply the action, directly or transitively
+	impliedByImplied, err := implied.Implied(ctx)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(impliedByImplied, a) {
+		return ErrActionImplicationCycle
+	}
+
+	return db.FromContext(ctx).AddActionImplication(ctx, string(a), string(implied))
+}
+
+// RemoveImplication removes a direct implication between the two actions.
+func (a Action) RemoveImplication(ctx context.Context, implied Action) error {
+	return db.FromContext(ctx).RemoveActionImplication(ctx, string(a), string(implied))
+}
+
+// Implied returns the action and every action it implies, directly or transitively, sorted by name.
+func (a Action) Implied(ctx context.Context) ([]Action, error) {
+	implications, err := db.FromContext(ctx).GetActionImplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []Action{a}
+	queue := []string{string(a)}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range implications[current] {
+			if !slices.Contains(actions, Action(next)) {
+				actions = append(actions, Action(next))
+				queue = append(queue, next)
+			}
+		}
+	}
+	slices.Sort(actions)
+
+	return actions, nil
+}
+
+// Hierarchy returns the actions directly implied by each action.
+func Hierarchy(ctx context.Context) (map[Action][]Action, error) {
+	implications, err := db.FromContext(ctx).GetActionImplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchy := map[Action][]Action{}
+	for action, implied := range implications {
+		for _, i := range implied {
+			hierarchy[Action(action)] = append(hierarchy[Action(action)], Action(i))
+		}
+	}
+
+	return hierarchy, nil
+}
@@ -0,0 +1,170 @@
+package action_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestActionHierarchy(t *testing.T) {
+	db.TestContainer(t)
+
+	testActionHierarchy(t)
+}
+
+func TestActionHierarchyInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testActionHierarchy(t)
+}
+
+func testActionHierarchy(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupIndexes(ctx)
+
+	admin, _ := action.Action("ADMIN").Create(ctx)
+
+	if err := admin.Implies(ctx, action.ActionWrite); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := action.ActionWrite.Implies(ctx, action.ActionRead); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Implications", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			action   action.Action
+			expected []action.Action
+		}{
+			{"ADMIN", admin, []action.Action{admin, action.ActionRead, action.ActionWrite}},
+			{"WRITE", action.ActionWrite, []action.Action{action.ActionRead, action.ActionWrite}},
+			{"READ", action.ActionRead, []action.Action{action.ActionRead}},
+		}
+
+		for _, tc := range testCases {
+			implied, err := tc.action.Implied(ctx)
+			if err != nil {
+				t.Errorf("Unexpected error for %s: %v", tc.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(implied, tc.expected) {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, implied)
+			}
+		}
+
+		if err := action.ActionRead.Implies(ctx, admin); !errors.Is(err, action.ErrActionImplicationCycle) {
+			t.Errorf("Expected %v, but got %v", action.ErrActionImplicationCycle, err)
+		}
+		if err := admin.Implies(ctx, admin); !errors.Is(err, action.ErrActionImplicationCycle) {
+			t.Errorf("Expected %v, but got %v", action.ErrActionImplicationCycle, err)
+		}
+	})
+
+	g1, _ := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.Create(ctx)
+	p1, _ := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(ctx, g1)
+	rRoot, _ := resource.Resource{Name: "_"}.Create(ctx)
+	r1, _ := resource.Resource{Name: "Resource1"}.CreateAsChildOf(ctx, rRoot)
+	rootSpecifier, _ := specifier.NewSpecifier("*", "*").Create(ctx)
+	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(ctx, rootSpecifier)
+	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(ctx, envRoot)
+	specifier.NewSpecifier("Env", "dev").CreateAsChildOf(ctx, envRoot)
+
+	adminPolicy, err := policy.Policy{
+		Subject:    g1,
+		Resource:   r1,
+		Action:     admin,
+		Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envProd}},
+	}.Create(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prod := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envProd}}
+	dev := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Env", "dev")}}
+
+	t.Run("Can", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			action   action.Action
+			with     specifier.SpecifierGroup
+			expected bool
+		}{
+			{"ADMIN in prod", admin, prod, true},
+			{"WRITE implied in prod", action.ActionWrite, prod, true},
+			{"READ implied in prod", action.ActionRead, prod, true},
+			{"READ implied in dev", action.ActionRead, dev, false},
+		}
+
+		for _, tc := range testCases {
+			result := query.Can(p1).Perform(tc.action).On(r1).With(tc.with).Query(ctx)
+			if result.Err != nil || result.Can != tc.expected {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, result)
+			}
+		}
+	})
+
+	t.Run("WhoCan", func(t *testing.T) {
+		subjects, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r1).With(prod).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []subject.Subject{p1}
+		if !reflect.DeepEqual(subjects, expected) {
+			t.Errorf("Expected %v, but got %v", expected, subjects)
+		}
+	})
+
+	t.Run("WhatCan", func(t *testing.T) {
+		resources, err := query.WhatCan(p1).Perform(action.ActionWrite).Under(rRoot).With(prod).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []resource.Resource{r1}
+		if !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v, but got %v", expected, resources)
+		}
+	})
+
+	t.Run("HowCan", func(t *testing.T) {
+		specifierGroups, err := query.HowCan(p1).Perform(action.ActionRead).On(r1).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(specifierGroups) != 1 || !reflect.DeepEqual(specifierGroups[0].AsMap(), map[string]string{"Env": "prod"}) {
+			t.Errorf("Expected [{Env: prod}], but got %v", specifierGroups)
+		}
+	})
+
+	t.Run("Policy effective actions", func(t *testing.T) {
+		expected := []action.Action{admin, action.ActionRead, action.ActionWrite}
+		if !reflect.DeepEqual(adminPolicy.EffectiveActions, expected) {
+			t.Errorf("Expected created policy to have %v, but got %v", expected, adminPolicy.EffectiveActions)
+		}
+
+		policies, err := policy.Policy{Subject: g1}.Get(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(policies) != 1 || policies[0].Action != admin || !reflect.DeepEqual(policies[0].EffectiveActions, expected) {
+			t.Errorf("Expected one ADMIN policy with effective actions %v, but got %v", expected, policies)
+		}
+	})
+
+	t.Run("Removing an implication", func(t *testing.T) {
+		if err := action.ActionWrite.RemoveImplication(ctx, action.ActionRead); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result := query.Can(p1).Perform(action.ActionRead).On(r1).With(prod).Query(ctx); result.Can {
+			t.Errorf("Expected READ to no longer be implied")
+		}
+	})
+}
@@ -0,0 +1,976 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: otter/v1/authorization.proto
+
+package otterv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Subject struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Principal or Group. Defaults to Principal.
+	Type          string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subject) Reset() {
+	*x = Subject{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subject) ProtoMessage() {}
+
+func (x *Subject) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subject.ProtoReflect.Descriptor instead.
+func (*Subject) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Subject) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Subject) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type CheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       *Subject               `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Resource      string                 `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Specifiers    map[string]string      `protobuf:"bytes,4,rep,name=specifiers,proto3" json:"specifiers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckRequest) GetSubject() *Subject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *CheckRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetSpecifiers() map[string]string {
+	if x != nil {
+		return x.Specifiers
+	}
+	return nil
+}
+
+type CheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Can           bool                   `protobuf:"varint,1,opt,name=can,proto3" json:"can,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckResponse) GetCan() bool {
+	if x != nil {
+		return x.Can
+	}
+	return false
+}
+
+type BatchCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*CheckRequest        `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckRequest) Reset() {
+	*x = BatchCheckRequest{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckRequest) ProtoMessage() {}
+
+func (x *BatchCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckRequest.ProtoReflect.Descriptor instead.
+func (*BatchCheckRequest) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchCheckRequest) GetChecks() []*CheckRequest {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+type BatchCheckResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Can   bool                   `protobuf:"varint,1,opt,name=can,proto3" json:"can,omitempty"`
+	// Set when this check could not be evaluated.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckResult) Reset() {
+	*x = BatchCheckResult{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckResult) ProtoMessage() {}
+
+func (x *BatchCheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckResult.ProtoReflect.Descriptor instead.
+func (*BatchCheckResult) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchCheckResult) GetCan() bool {
+	if x != nil {
+		return x.Can
+	}
+	return false
+}
+
+func (x *BatchCheckResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchCheckResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckResponse) Reset() {
+	*x = BatchCheckResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckResponse) ProtoMessage() {}
+
+func (x *BatchCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckResponse.ProtoReflect.Descriptor instead.
+func (*BatchCheckResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchCheckResponse) GetResults() []*BatchCheckResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LookupSubjectsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Principal or Group. Defaults to Principal.
+	SubjectType   string            `protobuf:"bytes,1,opt,name=subject_type,json=subjectType,proto3" json:"subject_type,omitempty"`
+	Action        string            `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Resource      string            `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Specifiers    map[string]string `protobuf:"bytes,4,rep,name=specifiers,proto3" json:"specifiers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupSubjectsRequest) Reset() {
+	*x = LookupSubjectsRequest{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupSubjectsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupSubjectsRequest) ProtoMessage() {}
+
+func (x *LookupSubjectsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupSubjectsRequest.ProtoReflect.Descriptor instead.
+func (*LookupSubjectsRequest) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LookupSubjectsRequest) GetSubjectType() string {
+	if x != nil {
+		return x.SubjectType
+	}
+	return ""
+}
+
+func (x *LookupSubjectsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *LookupSubjectsRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *LookupSubjectsRequest) GetSpecifiers() map[string]string {
+	if x != nil {
+		return x.Specifiers
+	}
+	return nil
+}
+
+type LookupSubjectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subjects      []*Subject             `protobuf:"bytes,1,rep,name=subjects,proto3" json:"subjects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupSubjectsResponse) Reset() {
+	*x = LookupSubjectsResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupSubjectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupSubjectsResponse) ProtoMessage() {}
+
+func (x *LookupSubjectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupSubjectsResponse.ProtoReflect.Descriptor instead.
+func (*LookupSubjectsResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LookupSubjectsResponse) GetSubjects() []*Subject {
+	if x != nil {
+		return x.Subjects
+	}
+	return nil
+}
+
+type StreamLookupSubjectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       *Subject               `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLookupSubjectsResponse) Reset() {
+	*x = StreamLookupSubjectsResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLookupSubjectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLookupSubjectsResponse) ProtoMessage() {}
+
+func (x *StreamLookupSubjectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLookupSubjectsResponse.ProtoReflect.Descriptor instead.
+func (*StreamLookupSubjectsResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamLookupSubjectsResponse) GetSubject() *Subject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+type LookupResourcesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Subject *Subject               `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Action  string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// Parent resource to look under. Defaults to the root resource `_`.
+	Under         string            `protobuf:"bytes,3,opt,name=under,proto3" json:"under,omitempty"`
+	Specifiers    map[string]string `protobuf:"bytes,4,rep,name=specifiers,proto3" json:"specifiers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResourcesRequest) Reset() {
+	*x = LookupResourcesRequest{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResourcesRequest) ProtoMessage() {}
+
+func (x *LookupResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResourcesRequest.ProtoReflect.Descriptor instead.
+func (*LookupResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LookupResourcesRequest) GetSubject() *Subject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *LookupResourcesRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *LookupResourcesRequest) GetUnder() string {
+	if x != nil {
+		return x.Under
+	}
+	return ""
+}
+
+func (x *LookupResourcesRequest) GetSpecifiers() map[string]string {
+	if x != nil {
+		return x.Specifiers
+	}
+	return nil
+}
+
+type LookupResourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resources     []string               `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResourcesResponse) Reset() {
+	*x = LookupResourcesResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResourcesResponse) ProtoMessage() {}
+
+func (x *LookupResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResourcesResponse.ProtoReflect.Descriptor instead.
+func (*LookupResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LookupResourcesResponse) GetResources() []string {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type StreamLookupResourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLookupResourcesResponse) Reset() {
+	*x = StreamLookupResourcesResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLookupResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLookupResourcesResponse) ProtoMessage() {}
+
+func (x *StreamLookupResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLookupResourcesResponse.ProtoReflect.Descriptor instead.
+func (*StreamLookupResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamLookupResourcesResponse) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+type ExpandSpecifiersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       *Subject               `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Resource      string                 `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Specifiers    map[string]string      `protobuf:"bytes,4,rep,name=specifiers,proto3" json:"specifiers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandSpecifiersRequest) Reset() {
+	*x = ExpandSpecifiersRequest{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandSpecifiersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandSpecifiersRequest) ProtoMessage() {}
+
+func (x *ExpandSpecifiersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandSpecifiersRequest.ProtoReflect.Descriptor instead.
+func (*ExpandSpecifiersRequest) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ExpandSpecifiersRequest) GetSubject() *Subject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *ExpandSpecifiersRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ExpandSpecifiersRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ExpandSpecifiersRequest) GetSpecifiers() map[string]string {
+	if x != nil {
+		return x.Specifiers
+	}
+	return nil
+}
+
+type SpecifierGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Specifiers    map[string]string      `protobuf:"bytes,1,rep,name=specifiers,proto3" json:"specifiers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpecifierGroup) Reset() {
+	*x = SpecifierGroup{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpecifierGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpecifierGroup) ProtoMessage() {}
+
+func (x *SpecifierGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpecifierGroup.ProtoReflect.Descriptor instead.
+func (*SpecifierGroup) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SpecifierGroup) GetSpecifiers() map[string]string {
+	if x != nil {
+		return x.Specifiers
+	}
+	return nil
+}
+
+type ExpandSpecifiersResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SpecifierGroups []*SpecifierGroup      `protobuf:"bytes,1,rep,name=specifier_groups,json=specifierGroups,proto3" json:"specifier_groups,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExpandSpecifiersResponse) Reset() {
+	*x = ExpandSpecifiersResponse{}
+	mi := &file_otter_v1_authorization_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandSpecifiersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandSpecifiersResponse) ProtoMessage() {}
+
+func (x *ExpandSpecifiersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_otter_v1_authorization_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandSpecifiersResponse.ProtoReflect.Descriptor instead.
+func (*ExpandSpecifiersResponse) Descriptor() ([]byte, []int) {
+	return file_otter_v1_authorization_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExpandSpecifiersResponse) GetSpecifierGroups() []*SpecifierGroup {
+	if x != nil {
+		return x.SpecifierGroups
+	}
+	return nil
+}
+
+var File_otter_v1_authorization_proto protoreflect.FileDescriptor
+
+const file_otter_v1_authorization_proto_rawDesc = "" +
+	"\n" +
+	"\x1cotter/v1/authorization.proto\x12\botter.v1\"1\n" +
+	"\aSubject\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"\xf6\x01\n" +
+	"\fCheckRequest\x12+\n" +
+	"\asubject\x18\x01 \x01(\v2\x11.otter.v1.SubjectR\asubject\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1a\n" +
+	"\bresource\x18\x03 \x01(\tR\bresource\x12F\n" +
+	"\n" +
+	"specifiers\x18\x04 \x03(\v2&.otter.v1.CheckRequest.SpecifiersEntryR\n" +
+	"specifiers\x1a=\n" +
+	"\x0fSpecifiersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"!\n" +
+	"\rCheckResponse\x12\x10\n" +
+	"\x03can\x18\x01 \x01(\bR\x03can\"C\n" +
+	"\x11BatchCheckRequest\x12.\n" +
+	"\x06checks\x18\x01 \x03(\v2\x16.otter.v1.CheckRequestR\x06checks\":\n" +
+	"\x10BatchCheckResult\x12\x10\n" +
+	"\x03can\x18\x01 \x01(\bR\x03can\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"J\n" +
+	"\x12BatchCheckResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.otter.v1.BatchCheckResultR\aresults\"\xfe\x01\n" +
+	"\x15LookupSubjectsRequest\x12!\n" +
+	"\fsubject_type\x18\x01 \x01(\tR\vsubjectType\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1a\n" +
+	"\bresource\x18\x03 \x01(\tR\bresource\x12O\n" +
+	"\n" +
+	"specifiers\x18\x04 \x03(\v2/.otter.v1.LookupSubjectsRequest.SpecifiersEntryR\n" +
+	"specifiers\x1a=\n" +
+	"\x0fSpecifiersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"G\n" +
+	"\x16LookupSubjectsResponse\x12-\n" +
+	"\bsubjects\x18\x01 \x03(\v2\x11.otter.v1.SubjectR\bsubjects\"K\n" +
+	"\x1cStreamLookupSubjectsResponse\x12+\n" +
+	"\asubject\x18\x01 \x01(\v2\x11.otter.v1.SubjectR\asubject\"\x84\x02\n" +
+	"\x16LookupResourcesRequest\x12+\n" +
+	"\asubject\x18\x01 \x01(\v2\x11.otter.v1.SubjectR\asubject\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x14\n" +
+	"\x05under\x18\x03 \x01(\tR\x05under\x12P\n" +
+	"\n" +
+	"specifiers\x18\x04 \x03(\v20.otter.v1.LookupResourcesRequest.SpecifiersEntryR\n" +
+	"specifiers\x1a=\n" +
+	"\x0fSpecifiersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"7\n" +
+	"\x17LookupResourcesResponse\x12\x1c\n" +
+	"\tresources\x18\x01 \x03(\tR\tresources\";\n" +
+	"\x1dStreamLookupResourcesResponse\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\"\x8c\x02\n" +
+	"\x17ExpandSpecifiersRequest\x12+\n" +
+	"\asubject\x18\x01 \x01(\v2\x11.otter.v1.SubjectR\asubject\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1a\n" +
+	"\bresource\x18\x03 \x01(\tR\bresource\x12Q\n" +
+	"\n" +
+	"specifiers\x18\x04 \x03(\v21.otter.v1.ExpandSpecifiersRequest.SpecifiersEntryR\n" +
+	"specifiers\x1a=\n" +
+	"\x0fSpecifiersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x01\n" +
+	"\x0eSpecifierGroup\x12H\n" +
+	"\n" +
+	"specifiers\x18\x01 \x03(\v2(.otter.v1.SpecifierGroup.SpecifiersEntryR\n" +
+	"specifiers\x1a=\n" +
+	"\x0fSpecifiersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"_\n" +
+	"\x18ExpandSpecifiersResponse\x12C\n" +
+	"\x10specifier_groups\x18\x01 \x03(\v2\x18.otter.v1.SpecifierGroupR\x0fspecifierGroups2\xea\x04\n" +
+	"\x14AuthorizationService\x128\n" +
+	"\x05Check\x12\x16.otter.v1.CheckRequest\x1a\x17.otter.v1.CheckResponse\x12G\n" +
+	"\n" +
+	"BatchCheck\x12\x1b.otter.v1.BatchCheckRequest\x1a\x1c.otter.v1.BatchCheckResponse\x12S\n" +
+	"\x0eLookupSubjects\x12\x1f.otter.v1.LookupSubjectsRequest\x1a .otter.v1.LookupSubjectsResponse\x12a\n" +
+	"\x14StreamLookupSubjects\x12\x1f.otter.v1.LookupSubjectsRequest\x1a&.otter.v1.StreamLookupSubjectsResponse0\x01\x12V\n" +
+	"\x0fLookupResources\x12 .otter.v1.LookupResourcesRequest\x1a!.otter.v1.LookupResourcesResponse\x12d\n" +
+	"\x15StreamLookupResources\x12 .otter.v1.LookupResourcesRequest\x1a'.otter.v1.StreamLookupResourcesResponse0\x01\x12Y\n" +
+	"\x10ExpandSpecifiers\x12!.otter.v1.ExpandSpecifiersRequest\x1a\".otter.v1.ExpandSpecifiersResponseB0Z.github.com/namsnath/otter/api/otter/v1;otterv1b\x06proto3"
+
+var (
+	file_otter_v1_authorization_proto_rawDescOnce sync.Once
+	file_otter_v1_authorization_proto_rawDescData []byte
+)
+
+func file_otter_v1_authorization_proto_rawDescGZIP() []byte {
+	file_otter_v1_authorization_proto_rawDescOnce.Do(func() {
+		file_otter_v1_authorization_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_otter_v1_authorization_proto_rawDesc), len(file_otter_v1_authorization_proto_rawDesc)))
+	})
+	return file_otter_v1_authorization_proto_rawDescData
+}
+
+var file_otter_v1_authorization_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_otter_v1_authorization_proto_goTypes = []any{
+	(*Subject)(nil),                       // 0: otter.v1.Subject
+	(*CheckRequest)(nil),                  // 1: otter.v1.CheckRequest
+	(*CheckResponse)(nil),                 // 2: otter.v1.CheckResponse
+	(*BatchCheckRequest)(nil),             // 3: otter.v1.BatchCheckRequest
+	(*BatchCheckResult)(nil),              // 4: otter.v1.BatchCheckResult
+	(*BatchCheckResponse)(nil),            // 5: otter.v1.BatchCheckResponse
+	(*LookupSubjectsRequest)(nil),         // 6: otter.v1.LookupSubjectsRequest
+	(*LookupSubjectsResponse)(nil),        // 7: otter.v1.LookupSubjectsResponse
+	(*StreamLookupSubjectsResponse)(nil),  // 8: otter.v1.StreamLookupSubjectsResponse
+	(*LookupResourcesRequest)(nil),        // 9: otter.v1.LookupResourcesRequest
+	(*LookupResourcesResponse)(nil),       // 10: otter.v1.LookupResourcesResponse
+	(*StreamLookupResourcesResponse)(nil), // 11: otter.v1.StreamLookupResourcesResponse
+	(*ExpandSpecifiersRequest)(nil),       // 12: otter.v1.ExpandSpecifiersRequest
+	(*SpecifierGroup)(nil),                // 13: otter.v1.SpecifierGroup
+	(*ExpandSpecifiersResponse)(nil),      // 14: otter.v1.ExpandSpecifiersResponse
+	nil,                                   // 15: otter.v1.CheckRequest.SpecifiersEntry
+	nil,                                   // 16: otter.v1.LookupSubjectsRequest.SpecifiersEntry
+	nil,                                   // 17: otter.v1.LookupResourcesRequest.SpecifiersEntry
+	nil,                                   // 18: otter.v1.ExpandSpecifiersRequest.SpecifiersEntry
+	nil,                                   // 19: otter.v1.SpecifierGroup.SpecifiersEntry
+}
+var file_otter_v1_authorization_proto_depIdxs = []int32{
+	0,  // 0: otter.v1.CheckRequest.subject:type_name -> otter.v1.Subject
+	15, // 1: otter.v1.CheckRequest.specifiers:type_name -> otter.v1.CheckRequest.SpecifiersEntry
+	1,  // 2: otter.v1.BatchCheckRequest.checks:type_name -> otter.v1.CheckRequest
+	4,  // 3: otter.v1.BatchCheckResponse.results:type_name -> otter.v1.BatchCheckResult
+	16, // 4: otter.v1.LookupSubjectsRequest.specifiers:type_name -> otter.v1.LookupSubjectsRequest.SpecifiersEntry
+	0,  // 5: otter.v1.LookupSubjectsResponse.subjects:type_name -> otter.v1.Subject
+	0,  // 6: otter.v1.StreamLookupSubjectsResponse.subject:type_name -> otter.v1.Subject
+	0,  // 7: otter.v1.LookupResourcesRequest.subject:type_name -> otter.v1.Subject
+	17, // 8: otter.v1.LookupResourcesRequest.specifiers:type_name -> otter.v1.LookupResourcesRequest.SpecifiersEntry
+	0,  // 9: otter.v1.ExpandSpecifiersRequest.subject:type_name -> otter.v1.Subject
+	18, // 10: otter.v1.ExpandSpecifiersRequest.specifiers:type_name -> otter.v1.ExpandSpecifiersRequest.SpecifiersEntry
+	19, // 11: otter.v1.SpecifierGroup.specifiers:type_name -> otter.v1.SpecifierGroup.SpecifiersEntry
+	13, // 12: otter.v1.ExpandSpecifiersResponse.specifier_groups:type_name -> otter.v1.SpecifierGroup
+	1,  // 13: otter.v1.AuthorizationService.Check:input_type -> otter.v1.CheckRequest
+	3,  // 14: otter.v1.AuthorizationService.BatchCheck:input_type -> otter.v1.BatchCheckRequest
+	6,  // 15: otter.v1.AuthorizationService.LookupSubjects:input_type -> otter.v1.LookupSubjectsRequest
+	6,  // 16: otter.v1.AuthorizationService.StreamLookupSubjects:input_type -> otter.v1.LookupSubjectsRequest
+	9,  // 17: otter.v1.AuthorizationService.LookupResources:input_type -> otter.v1.LookupResourcesRequest
+	9,  // 18: otter.v1.AuthorizationService.StreamLookupResources:input_type -> otter.v1.LookupResourcesRequest
+	12, // 19: otter.v1.AuthorizationService.ExpandSpecifiers:input_type -> otter.v1.ExpandSpecifiersRequest
+	2,  // 20: otter.v1.AuthorizationService.Check:output_type -> otter.v1.CheckResponse
+	5,  // 21: otter.v1.AuthorizationService.BatchCheck:output_type -> otter.v1.BatchCheckResponse
+	7,  // 22: otter.v1.AuthorizationService.LookupSubjects:output_type -> otter.v1.LookupSubjectsResponse
+	8,  // 23: otter.v1.AuthorizationService.StreamLookupSubjects:output_type -> otter.v1.StreamLookupSubjectsResponse
+	10, // 24: otter.v1.AuthorizationService.LookupResources:output_type -> otter.v1.LookupResourcesResponse
+	11, // 25: otter.v1.AuthorizationService.StreamLookupResources:output_type -> otter.v1.StreamLookupResourcesResponse
+	14, // 26: otter.v1.AuthorizationService.ExpandSpecifiers:output_type -> otter.v1.ExpandSpecifiersResponse
+	20, // [20:27] is the sub-list for method output_type
+	13, // [13:20] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_otter_v1_authorization_proto_init() }
+func file_otter_v1_authorization_proto_init() {
+	if File_otter_v1_authorization_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_otter_v1_authorization_proto_rawDesc), len(file_otter_v1_authorization_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_otter_v1_authorization_proto_goTypes,
+		DependencyIndexes: file_otter_v1_authorization_proto_depIdxs,
+		MessageInfos:      file_otter_v1_authorization_proto_msgTypes,
+	}.Build()
+	File_otter_v1_authorization_proto = out.File
+	file_otter_v1_authorization_proto_goTypes = nil
+	file_otter_v1_authorization_proto_depIdxs = nil
+}
@@ -0,0 +1,374 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: otter/v1/authorization.proto
+
+package otterv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AuthorizationService_Check_FullMethodName                 = "/otter.v1.AuthorizationService/Check"
+	AuthorizationService_BatchCheck_FullMethodName            = "/otter.v1.AuthorizationService/BatchCheck"
+	AuthorizationService_LookupSubjects_FullMethodName        = "/otter.v1.AuthorizationService/LookupSubjects"
+	AuthorizationService_StreamLookupSubjects_FullMethodName  = "/otter.v1.AuthorizationService/StreamLookupSubjects"
+	AuthorizationService_LookupResources_FullMethodName       = "/otter.v1.AuthorizationService/LookupResources"
+	AuthorizationService_StreamLookupResources_FullMethodName = "/otter.v1.AuthorizationService/StreamLookupResources"
+	AuthorizationService_ExpandSpecifiers_FullMethodName      = "/otter.v1.AuthorizationService/ExpandSpecifiers"
+)
+
+// AuthorizationServiceClient is the client API for AuthorizationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AuthorizationService answers the Can, WhoCan, WhatCan and HowCan questions.
+type AuthorizationServiceClient interface {
+	// Check answers `Can <subject> perform <action> on <resource> with <specifiers>?`
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// BatchCheck runs many Checks in a single store round trip. Results are returned in input order.
+	BatchCheck(ctx context.Context, in *BatchCheckRequest, opts ...grpc.CallOption) (*BatchCheckResponse, error)
+	// LookupSubjects answers `WhoCan <action> on <resource> with <specifiers>?`
+	LookupSubjects(ctx context.Context, in *LookupSubjectsRequest, opts ...grpc.CallOption) (*LookupSubjectsResponse, error)
+	// StreamLookupSubjects is LookupSubjects sending one message per subject.
+	StreamLookupSubjects(ctx context.Context, in *LookupSubjectsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLookupSubjectsResponse], error)
+	// LookupResources answers `WhatCan <subject> perform <action> with <specifiers> under <resource>?`
+	LookupResources(ctx context.Context, in *LookupResourcesRequest, opts ...grpc.CallOption) (*LookupResourcesResponse, error)
+	// StreamLookupResources is LookupResources sending one message per resource.
+	StreamLookupResources(ctx context.Context, in *LookupResourcesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLookupResourcesResponse], error)
+	// ExpandSpecifiers answers `HowCan <subject> perform <action> on <resource> with <specifiers>?`
+	ExpandSpecifiers(ctx context.Context, in *ExpandSpecifiersRequest, opts ...grpc.CallOption) (*ExpandSpecifiersResponse, error)
+}
+
+type authorizationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuthorizationServiceClient(cc grpc.ClientConnInterface) AuthorizationServiceClient {
+	return &authorizationServiceClient{cc}
+}
+
+func (c *authorizationServiceClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, AuthorizationService_Check_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authorizationServiceClient) BatchCheck(ctx context.Context, in *BatchCheckRequest, opts ...grpc.CallOption) (*BatchCheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCheckResponse)
+	err := c.cc.Invoke(ctx, AuthorizationService_BatchCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authorizationServiceClient) LookupSubjects(ctx context.Context, in *LookupSubjectsRequest, opts ...grpc.CallOption) (*LookupSubjectsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupSubjectsResponse)
+	err := c.cc.Invoke(ctx, AuthorizationService_LookupSubjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authorizationServiceClient) StreamLookupSubjects(ctx context.Context, in *LookupSubjectsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLookupSubjectsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuthorizationService_ServiceDesc.Streams[0], AuthorizationService_StreamLookupSubjects_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LookupSubjectsRequest, StreamLookupSubjectsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthorizationService_StreamLookupSubjectsClient = grpc.ServerStreamingClient[StreamLookupSubjectsResponse]
+
+func (c *authorizationServiceClient) LookupResources(ctx context.Context, in *LookupResourcesRequest, opts ...grpc.CallOption) (*LookupResourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResourcesResponse)
+	err := c.cc.Invoke(ctx, AuthorizationService_LookupResources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authorizationServiceClient) StreamLookupResources(ctx context.Context, in *LookupResourcesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLookupResourcesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuthorizationService_ServiceDesc.Streams[1], AuthorizationService_StreamLookupResources_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LookupResourcesRequest, StreamLookupResourcesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthorizationService_StreamLookupResourcesClient = grpc.ServerStreamingClient[StreamLookupResourcesResponse]
+
+func (c *authorizationServiceClient) ExpandSpecifiers(ctx context.Context, in *ExpandSpecifiersRequest, opts ...grpc.CallOption) (*ExpandSpecifiersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpandSpecifiersResponse)
+	err := c.cc.Invoke(ctx, AuthorizationService_ExpandSpecifiers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthorizationServiceServer is the server API for AuthorizationService service.
+// All implementations must embed UnimplementedAuthorizationServiceServer
+// for forward compatibility.
+//
+// AuthorizationService answers the Can, WhoCan, WhatCan and HowCan questions.
+type AuthorizationServiceServer interface {
+	// Check answers `Can <subject> perform <action> on <resource> with <specifiers>?`
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	// BatchCheck runs many Checks in a single store round trip. Results are returned in input order.
+	BatchCheck(context.Context, *BatchCheckRequest) (*BatchCheckResponse, error)
+	// LookupSubjects answers `WhoCan <action> on <resource> with <specifiers>?`
+	LookupSubjects(context.Context, *LookupSubjectsRequest) (*LookupSubjectsResponse, error)
+	// StreamLookupSubjects is LookupSubjects sending one message per subject.
+	StreamLookupSubjects(*LookupSubjectsRequest, grpc.ServerStreamingServer[StreamLookupSubjectsResponse]) error
+	// LookupResources answers `WhatCan <subject> perform <action> with <specifiers> under <resource>?`
+	LookupResources(context.Context, *LookupResourcesRequest) (*LookupResourcesResponse, error)
+	// StreamLookupResources is LookupResources sending one message per resource.
+	StreamLookupResources(*LookupResourcesRequest, grpc.ServerStreamingServer[StreamLookupResourcesResponse]) error
+	// ExpandSpecifiers answers `HowCan <subject> perform <action> on <resource> with <specifiers>?`
+	ExpandSpecifiers(context.Context, *ExpandSpecifiersRequest) (*ExpandSpecifiersResponse, error)
+	mustEmbedUnimplementedAuthorizationServiceServer()
+}
+
+// UnimplementedAuthorizationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuthorizationServiceServer struct{}
+
+func (UnimplementedAuthorizationServiceServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) BatchCheck(context.Context, *BatchCheckRequest) (*BatchCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCheck not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) LookupSubjects(context.Context, *LookupSubjectsRequest) (*LookupSubjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupSubjects not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) StreamLookupSubjects(*LookupSubjectsRequest, grpc.ServerStreamingServer[StreamLookupSubjectsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLookupSubjects not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) LookupResources(context.Context, *LookupResourcesRequest) (*LookupResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupResources not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) StreamLookupResources(*LookupResourcesRequest, grpc.ServerStreamingServer[StreamLookupResourcesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLookupResources not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) ExpandSpecifiers(context.Context, *ExpandSpecifiersRequest) (*ExpandSpecifiersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExpandSpecifiers not implemented")
+}
+func (UnimplementedAuthorizationServiceServer) mustEmbedUnimplementedAuthorizationServiceServer() {}
+func (UnimplementedAuthorizationServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeAuthorizationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuthorizationServiceServer will
+// result in compilation errors.
+type UnsafeAuthorizationServiceServer interface {
+	mustEmbedUnimplementedAuthorizationServiceServer()
+}
+
+func RegisterAuthorizationServiceServer(s grpc.ServiceRegistrar, srv AuthorizationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAuthorizationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuthorizationService_ServiceDesc, srv)
+}
+
+func _AuthorizationService_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServiceServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorizationService_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServiceServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthorizationService_BatchCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServiceServer).BatchCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorizationService_BatchCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServiceServer).BatchCheck(ctx, req.(*BatchCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthorizationService_LookupSubjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupSubjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServiceServer).LookupSubjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorizationService_LookupSubjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServiceServer).LookupSubjects(ctx, req.(*LookupSubjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthorizationService_StreamLookupSubjects_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LookupSubjectsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AuthorizationServiceServer).StreamLookupSubjects(m, &grpc.GenericServerStream[LookupSubjectsRequest, StreamLookupSubjectsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthorizationService_StreamLookupSubjectsServer = grpc.ServerStreamingServer[StreamLookupSubjectsResponse]
+
+func _AuthorizationService_LookupResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServiceServer).LookupResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorizationService_LookupResources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServiceServer).LookupResources(ctx, req.(*LookupResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthorizationService_StreamLookupResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LookupResourcesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AuthorizationServiceServer).StreamLookupResources(m, &grpc.GenericServerStream[LookupResourcesRequest, StreamLookupResourcesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthorizationService_StreamLookupResourcesServer = grpc.ServerStreamingServer[StreamLookupResourcesResponse]
+
+func _AuthorizationService_ExpandSpecifiers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpandSpecifiersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServiceServer).ExpandSpecifiers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorizationService_ExpandSpecifiers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServiceServer).ExpandSpecifiers(ctx, req.(*ExpandSpecifiersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuthorizationService_ServiceDesc is the grpc.ServiceDesc for AuthorizationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuthorizationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "otter.v1.AuthorizationService",
+	HandlerType: (*AuthorizationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _AuthorizationService_Check_Handler,
+		},
+		{
+			MethodName: "BatchCheck",
+			Handler:    _AuthorizationService_BatchCheck_Handler,
+		},
+		{
+			MethodName: "LookupSubjects",
+			Handler:    _AuthorizationService_LookupSubjects_Handler,
+		},
+		{
+			MethodName: "LookupResources",
+			Handler:    _AuthorizationService_LookupResources_Handler,
+		},
+		{
+			MethodName: "ExpandSpecifiers",
+			Handler:    _AuthorizationService_ExpandSpecifiers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLookupSubjects",
+			Handler:       _AuthorizationService_StreamLookupSubjects_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLookupResources",
+			Handler:       _AuthorizationService_StreamLookupResources_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "otter/v1/authorization.proto",
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create name",
+	Short: "Register a new action",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		a, err := action.Action(args[0]).Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, output.Result{
+			Value:  actionView{Name: string(a), Implies: []string{}},
+			Header: []string{"name", "implies"},
+			Rows:   [][]string{{string(a), ""}},
+			Text:   []string{string(a)},
+		})
+	},
+}
+
+func init() {
+	ActionCmd.AddCommand(createCmd)
+
+	createCmd.Args = cobra.ExactArgs(1)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete name",
+	Short: "Delete a registered action that is not used by any policy",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		return action.Action(args[0]).Delete(ctx)
+	},
+}
+
+func init() {
+	ActionCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Args = cobra.ExactArgs(1)
+}
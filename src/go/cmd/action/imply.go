@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+var implyCmd = &cobra.Command{
+	Use:   "imply action implied",
+	Short: "Make an action imply another, e.g. `imply WRITE READ`",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		a, err := action.FromString(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		implied, err := action.FromString(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		if remove, _ := cmd.Flags().GetBool("remove"); remove {
+			return a.RemoveImplication(ctx, implied)
+		}
+		return a.Implies(ctx, implied)
+	},
+}
+
+func init() {
+	ActionCmd.AddCommand(implyCmd)
+
+	implyCmd.Args = cobra.ExactArgs(2)
+
+	implyCmd.Flags().Bool("remove", false, "Remove the implication instead of adding it")
+}
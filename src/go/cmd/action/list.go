@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+// actionView is the output of an action, with the actions it directly implies.
+type actionView struct {
+	Name    string   `json:"name" yaml:"name"`
+	Implies []string `json:"implies" yaml:"implies"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in and registered actions, with the actions they imply",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		actions, err := action.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		hierarchy, err := action.Hierarchy(ctx)
+		if err != nil {
+			return err
+		}
+
+		views := []actionView{}
+		result := output.Result{Header: []string{"name", "implies"}, Text: []string{}}
+		for _, a := range actions {
+			implied := []string{}
+			for _, i := range hierarchy[a] {
+				implied = append(implied, string(i))
+			}
+			slices.Sort(implied)
+
+			views = append(views, actionView{Name: string(a), Implies: implied})
+			result.Rows = append(result.Rows, []string{string(a), strings.Join(implied, ",")})
+			if len(implied) == 0 {
+				result.Text = append(result.Text, string(a))
+			} else {
+				result.Text = append(result.Text, fmt.Sprintf("%s -> %s", a, strings.Join(implied, ", ")))
+			}
+		}
+		result.Value = views
+
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	ActionCmd.AddCommand(listCmd)
+
+	listCmd.Args = cobra.NoArgs
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/state"
+	"github.com/spf13/cobra"
+)
+
+// planView is the output of a plan, and whether it was applied.
+type planView struct {
+	Changes []state.Change `json:"changes" yaml:"changes"`
+	Applied bool           `json:"applied" yaml:"applied"`
+}
+
+// planResult returns the output of the plan, with one row per change.
+func planResult(plan state.Plan, applied bool) output.Result {
+	changes := plan.Changes
+	if changes == nil {
+		changes = []state.Change{}
+	}
+
+	result := output.Result{
+		Value:  planView{Changes: changes, Applied: applied},
+		Header: []string{"operation", "kind", "name", "detail"},
+		Text:   strings.Split(plan.String(), "\n"),
+	}
+	for _, change := range plan.Changes {
+		result.Rows = append(result.Rows, []string{string(change.Operation), change.Kind, change.Name, change.Detail})
+	}
+	return result
+}
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile the database with a declarative state file",
+	Long: `Reconcile the database with a declarative YAML (or JSON) state file.
+The plan is printed first, and applied once confirmed. Everything missing from the database
+is created, and declared policies that differ are replaced, keeping their ID.
+Nothing that the state does not declare is deleted, unless --prune is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		file := cmd.Flag("file").Value.String()
+		prune, _ := cmd.Flags().GetBool("prune")
+		planOnly, _ := cmd.Flags().GetBool("plan")
+		yes, _ := cmd.Flags().GetBool("yes")
+		defer db.GetInstance().Close()
+
+		s, err := state.Load(file)
+		if err != nil {
+			return err
+		}
+
+		plan, err := s.Plan(ctx, state.Options{Prune: prune})
+		if err != nil {
+			return err
+		}
+
+		result := planResult(plan, false)
+		if plan.Empty() || planOnly {
+			return output.Print(cmd, result)
+		}
+
+		if !yes {
+			if file == "-" {
+				return fmt.Errorf("cannot confirm the plan when reading the state from stdin, use --yes")
+			}
+
+			// The plan is listed before the prompt, and not printed again once applied
+			fmt.Fprintln(output.PromptWriter(cmd), plan)
+			if !output.Confirm(cmd, "\nApply these changes?") {
+				result.Text = []string{"Apply cancelled."}
+				return output.Print(cmd, result)
+			}
+			result.Text = []string{}
+		}
+
+		if err := plan.Apply(ctx); err != nil {
+			return err
+		}
+
+		applied := planResult(plan, true)
+		applied.Text = append(result.Text, fmt.Sprintf("Applied %d changes.", len(plan.Changes)))
+		return output.Print(cmd, applied)
+	},
+}
+
+func init() {
+	ApplyCmd.Args = cobra.NoArgs
+
+	ApplyCmd.Flags().StringP("file", "f", "", "YAML or JSON state file. Reads from stdin with -")
+	ApplyCmd.Flags().Bool("prune", false, "Delete everything the state does not declare")
+	ApplyCmd.Flags().Bool("plan", false, "Only print the plan")
+	ApplyCmd.Flags().BoolP("yes", "y", false, "Apply without asking for confirmation")
+	ApplyCmd.MarkFlagRequired("file")
+}
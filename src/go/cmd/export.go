@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/state"
+	"github.com/spf13/cobra"
+)
+
+// transferFormat returns the format of the export file: the given one, or the one matching its extension.
+func transferFormat(format string, file string) (string, error) {
+	if format == "" {
+		switch filepath.Ext(file) {
+		case ".ndjson", ".jsonl":
+			return "ndjson", nil
+		default:
+			return "json", nil
+		}
+	}
+	if format != "json" && format != "ndjson" {
+		return "", fmt.Errorf("invalid format %q: must be json or ndjson", format)
+	}
+	return format, nil
+}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the whole authorization graph as a versioned JSON or NDJSON document",
+	Long: `Export the whole authorization graph: actions, subjects, resources, specifiers, policies
+and their hierarchy edges. Policy IDs are preserved, so the document can be loaded back with
+otter import, or reconciled with otter apply.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		file := cmd.Flag("output").Value.String()
+		defer db.GetInstance().Close()
+
+		format, err := transferFormat(cmd.Flag("format").Value.String(), file)
+		if err != nil {
+			return err
+		}
+
+		s, err := state.Export(ctx)
+		if err != nil {
+			return err
+		}
+
+		var output io.Writer = cmd.OutOrStdout()
+		if file != "-" {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			output = f
+		}
+
+		if format == "ndjson" {
+			return state.EncodeNDJSON(output, s)
+		}
+
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	},
+}
+
+func init() {
+	ExportCmd.Args = cobra.NoArgs
+
+	ExportCmd.Flags().StringP("output", "o", "-", "File to write the export to. Writes to stdout with -")
+	ExportCmd.Flags().String("format", "", "json or ndjson. Defaults to the format matching the file extension, or json")
+}
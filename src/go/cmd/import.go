@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/state"
+	"github.com/spf13/cobra"
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a document written by otter export",
+	Long: `Import a document written by otter export into an empty or existing database.
+Only what is missing or different is written, keeping the policy IDs of the document,
+so importing the same document twice is a no-op. Nothing is deleted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		file := cmd.Flag("file").Value.String()
+		planOnly, _ := cmd.Flags().GetBool("plan")
+		defer db.GetInstance().Close()
+
+		format, err := transferFormat(cmd.Flag("format").Value.String(), file)
+		if err != nil {
+			return err
+		}
+
+		var input io.Reader = os.Stdin
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+
+		var s state.State
+		if format == "ndjson" {
+			s, err = state.DecodeNDJSON(input)
+		} else {
+			var data []byte
+			data, err = io.ReadAll(input)
+			if err == nil {
+				s, err = state.Parse(data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		plan, err := s.Plan(ctx, state.Options{})
+		if err != nil {
+			return err
+		}
+
+		if plan.Empty() || planOnly {
+			return output.Print(cmd, planResult(plan, false))
+		}
+
+		if err := plan.Apply(ctx); err != nil {
+			return err
+		}
+
+		imported := planResult(plan, true)
+		imported.Text = append(imported.Text, fmt.Sprintf("Imported %d changes.", len(plan.Changes)))
+		return output.Print(cmd, imported)
+	},
+}
+
+func init() {
+	ImportCmd.Args = cobra.NoArgs
+
+	ImportCmd.Flags().StringP("file", "f", "-", "File to import. Reads from stdin with -")
+	ImportCmd.Flags().String("format", "", "json or ndjson. Defaults to the format matching the file extension, or json")
+	ImportCmd.Flags().Bool("plan", false, "Only print the changes the import would make")
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+// Error codes reported in the JSON and YAML shape of errors.
+const (
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeInvalidInput       = "invalid_input"
+	CodeBackendUnavailable = "backend_unavailable"
+	CodeInternal           = "internal"
+)
+
+// ErrorBody is the stable shape of errors in the JSON and YAML formats:
+//
+//	{"error": {"code": "not_found", "message": "Principal9: subject not found"}}
+type ErrorBody struct {
+	Error ErrorDetail `json:"error" yaml:"error"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// Code returns the error code of the error, from the kind of error it wraps.
+func Code(err error) string {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, db.ErrConflict):
+		return CodeConflict
+	case errors.Is(err, db.ErrInvalidInput):
+		return CodeInvalidInput
+	case errors.Is(err, db.ErrBackendUnavailable):
+		return CodeBackendUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// PrintError writes the error to w, as an ErrorBody in the JSON and YAML formats.
+func PrintError(w io.Writer, format Format, err error) {
+	body := ErrorBody{Error: ErrorDetail{Code: Code(err), Message: err.Error()}}
+
+	switch format {
+	case FormatJSON:
+		writeJSON(w, body)
+	case FormatYAML:
+		writeYAML(w, body)
+	default:
+		fmt.Fprintf(w, "Error: %s\n", err)
+	}
+}
+
+// ExitCode returns the exit code of a command that returned the error.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOk
+	case errors.Is(err, ErrDenied):
+		return ExitDenied
+	default:
+		return ExitError
+	}
+}
+
+// AddFlag registers the persistent --output flag on the root command, and validates it before any command runs.
+func AddFlag(root *cobra.Command) {
+	root.PersistentFlags().StringP(FlagName, "o", string(FormatText), "Output format: text, json, yaml or table")
+}
+
+// Validate checks the --output flag of the command. Machine-readable formats do not print the usage on errors.
+func Validate(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup(FlagName)
+	if flag == nil || cmd.LocalNonPersistentFlags().Lookup(FlagName) != nil {
+		return nil
+	}
+
+	format, err := FormatFromString(flag.Value.String())
+	if err != nil {
+		return err
+	}
+	if format != FormatText {
+		cmd.SilenceUsage = true
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"github.com/namsnath/otter/query"
+)
+
+// AccessView is the output of an access.
+type AccessView struct {
+	Subject    string            `json:"subject" yaml:"subject"`
+	Action     string            `json:"action" yaml:"action"`
+	Resource   string            `json:"resource" yaml:"resource"`
+	Specifiers map[string]string `json:"specifiers" yaml:"specifiers"`
+}
+
+// ImpactView is the output of the accesses a change adds and removes.
+type ImpactView struct {
+	Gained []AccessView `json:"gained" yaml:"gained"`
+	Lost   []AccessView `json:"lost" yaml:"lost"`
+}
+
+func accessViews(accesses []query.Access) []AccessView {
+	views := []AccessView{}
+	for _, a := range accesses {
+		views = append(views, AccessView{
+			Subject:    a.Subject.Name,
+			Action:     string(a.Action),
+			Resource:   a.Resource.Name,
+			Specifiers: a.Specifiers.AsMap(),
+		})
+	}
+	return views
+}
+
+// Impact returns the output of the accesses a change adds and removes, one per line prefixed with + or -.
+func Impact(impact query.Impact) Result {
+	result := Result{
+		Value:  ImpactView{Gained: accessViews(impact.Gained), Lost: accessViews(impact.Lost)},
+		Header: []string{"change", "access"},
+		Text:   []string{},
+	}
+	for _, a := range impact.Gained {
+		result.Rows = append(result.Rows, []string{"+", a.String()})
+		result.Text = append(result.Text, "+ "+a.String())
+	}
+	for _, a := range impact.Lost {
+		result.Rows = append(result.Rows, []string{"-", a.String()})
+		result.Text = append(result.Text, "- "+a.String())
+	}
+	if impact.Empty() {
+		result.Text = append(result.Text, "No access changes.")
+	}
+	return result
+}
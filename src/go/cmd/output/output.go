@@ -0,0 +1,147 @@
+// Package output prints the results and errors of the CLI commands in the format selected
+// with the global --output flag: text, json, yaml or table.
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatTable Format = "table"
+)
+
+// FlagName is the name of the persistent flag selecting the format.
+const FlagName = "output"
+
+// Exit codes of the CLI. Commands answering an access question exit with ExitDenied when it is denied.
+const (
+	ExitOk     = 0
+	ExitDenied = 1
+	ExitError  = 2
+)
+
+var ErrInvalidFormat = db.NewError(db.ErrInvalidInput, "invalid output format: must be one of text, json, yaml or table")
+
+// ErrDenied is returned by commands whose result has been printed, but denies access.
+// It is not printed as an error, only turned into ExitDenied.
+var ErrDenied = errors.New("access denied")
+
+func FormatFromString(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, FormatJSON, FormatYAML, FormatTable:
+		return Format(s), nil
+	default:
+		return "", ErrInvalidFormat
+	}
+}
+
+// Of returns the format selected for the command, defaulting to FormatText.
+// Commands with a local --output flag of their own, like export, always print text.
+func Of(cmd *cobra.Command) Format {
+	flag := cmd.Flags().Lookup(FlagName)
+	if flag == nil || cmd.LocalNonPersistentFlags().Lookup(FlagName) != nil {
+		return FormatText
+	}
+
+	format, err := FormatFromString(flag.Value.String())
+	if err != nil {
+		return FormatText
+	}
+	return format
+}
+
+// Result is the output of a command, in every format.
+type Result struct {
+	// Value is encoded as JSON or YAML. It needs both json and yaml tags.
+	Value any
+	// Header and Rows make up the table.
+	Header []string
+	Rows   [][]string
+	// Text holds the lines printed in the text format. The table rows are printed when it is nil.
+	Text []string
+}
+
+// Print writes the result to the output of the command, in its format.
+func Print(cmd *cobra.Command, result Result) error {
+	w := cmd.OutOrStdout()
+
+	switch Of(cmd) {
+	case FormatJSON:
+		return writeJSON(w, result.Value)
+	case FormatYAML:
+		return writeYAML(w, result.Value)
+	case FormatTable:
+		return writeTable(w, result.Header, result.Rows)
+	}
+
+	if result.Text == nil {
+		return writeTable(w, nil, result.Rows)
+	}
+	for _, line := range result.Text {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if len(header) > 0 {
+		upper := make([]string, len(header))
+		for i, h := range header {
+			upper[i] = strings.ToUpper(h)
+		}
+		fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// PromptWriter returns where to write prompts and previews: the command output for text,
+// and stderr for the other formats, which keeps the output parseable.
+func PromptWriter(cmd *cobra.Command) io.Writer {
+	if Of(cmd) == FormatText {
+		return cmd.OutOrStdout()
+	}
+	return cmd.ErrOrStderr()
+}
+
+// Confirm asks the question, and reports whether it was answered with y.
+func Confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(PromptWriter(cmd), "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
@@ -0,0 +1,125 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+type view struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func TestPrint(t *testing.T) {
+	result := output.Result{
+		Value:  []view{{Name: "Resource1"}, {Name: "Resource2"}},
+		Header: []string{"name", "parent"},
+		Rows:   [][]string{{"Resource1", "_"}, {"Resource2", "_"}},
+		Text:   []string{"Resource1 -> _", "Resource2 -> _"},
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{"text", "Resource1 -> _\nResource2 -> _\n"},
+		{"json", "[\n  {\n    \"name\": \"Resource1\"\n  },\n  {\n    \"name\": \"Resource2\"\n  }\n]\n"},
+		{"yaml", "- name: Resource1\n- name: Resource2\n"},
+		{"table", "NAME       PARENT\nResource1  _\nResource2  _\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var out bytes.Buffer
+			root := &cobra.Command{Use: "otter"}
+			output.AddFlag(root)
+			cmd := &cobra.Command{Use: "list", RunE: func(cmd *cobra.Command, args []string) error {
+				return output.Print(cmd, result)
+			}}
+			root.AddCommand(cmd)
+			root.SetOut(&out)
+			root.SetArgs([]string{"list", "--output", tc.format})
+
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	err := fmt.Errorf("Principal9: %w", subject.ErrSubjectNotFound)
+
+	testCases := []struct {
+		format   output.Format
+		expected string
+	}{
+		{output.FormatText, "Error: Principal9: subject not found\n"},
+		{output.FormatTable, "Error: Principal9: subject not found\n"},
+		{output.FormatJSON, "{\n  \"error\": {\n    \"code\": \"not_found\",\n    \"message\": \"Principal9: subject not found\"\n  }\n}\n"},
+		{output.FormatYAML, "error:\n  code: not_found\n  message: 'Principal9: subject not found'\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var out bytes.Buffer
+			output.PrintError(&out, tc.format, err)
+			if out.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"not found", fmt.Errorf("Principal9: %w", subject.ErrSubjectNotFound), output.CodeNotFound},
+		{"conflict", resource.ErrResourceExists, output.CodeConflict},
+		{"invalid input", output.ErrInvalidFormat, output.CodeInvalidInput},
+		{"backend unavailable", db.ErrNotInitialized, output.CodeBackendUnavailable},
+		{"internal", errors.New("unexpected"), output.CodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := output.Code(tc.err); code != tc.expected {
+				t.Errorf("Expected code %q, got %q", tc.expected, code)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, output.ExitOk},
+		{"denied", output.ErrDenied, output.ExitDenied},
+		{"error", errors.New("connection refused"), output.ExitError},
+		{"invalid format", output.ErrInvalidFormat, output.ExitError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := output.ExitCode(tc.err); code != tc.expected {
+				t.Errorf("Expected exit code %d, got %d", tc.expected, code)
+			}
+		})
+	}
+}
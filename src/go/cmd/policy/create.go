@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a policy for a subject on a resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		p, err := applyFlags(cmd, policy.Policy{})
+		if err != nil {
+			return err
+		}
+
+		created, err := p.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, single(created))
+	},
+}
+
+func init() {
+	PolicyCmd.AddCommand(createCmd)
+
+	createCmd.Args = cobra.NoArgs
+
+	addPolicyFlags(createCmd)
+	createCmd.MarkFlagRequired("subject")
+	createCmd.MarkFlagRequired("resource")
+	createCmd.MarkFlagRequired("action")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Short: "Delete a policy by ID, or every policy matching the filter flags",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		defer db.GetInstance().Close()
+
+		filtered := false
+		for _, name := range []string{"subject", "resource", "action", "effect", "with"} {
+			filtered = filtered || cmd.Flags().Changed(name)
+		}
+
+		var policies []policy.Policy
+		switch {
+		case len(args) == 1 && filtered:
+			return fmt.Errorf("delete takes either a policy ID or filter flags, not both")
+		case len(args) == 1:
+			p, err := getById(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			policies = []policy.Policy{p}
+		case filtered:
+			filter, err := filterFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			policies, err = filter.Get(ctx)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("delete needs a policy ID or filter flags")
+		}
+
+		deleted := result([]policy.Policy{})
+		if len(policies) == 0 {
+			deleted.Text = []string{"No policies matched."}
+			return output.Print(cmd, deleted)
+		}
+
+		if !yes {
+			for _, line := range result(policies).Text {
+				fmt.Fprintln(output.PromptWriter(cmd), line)
+			}
+			if !output.Confirm(cmd, fmt.Sprintf("\nDelete %d policies?", len(policies))) {
+				deleted.Text = []string{"Delete cancelled."}
+				return output.Print(cmd, deleted)
+			}
+		}
+
+		// The confirmed policies are deleted together, or none is if one fails
+		err := db.InTx(ctx, func(ctx context.Context) error {
+			for _, p := range policies {
+				if err := p.Delete(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		deleted = result(policies)
+		if !yes {
+			// The policies were already listed before the prompt
+			deleted.Text = []string{}
+		}
+		deleted.Text = append(deleted.Text, fmt.Sprintf("Deleted %d policies.", len(policies)))
+		return output.Print(cmd, deleted)
+	},
+}
+
+func init() {
+	PolicyCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Args = cobra.MaximumNArgs(1)
+
+	addPolicyFlags(deleteCmd)
+	deleteCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
+}
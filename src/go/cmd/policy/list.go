@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+// filterFromFlags returns the policy matching the filter flags, as understood by Policy.Get.
+// Unlike applyFlags, the subject and resource do not need to exist.
+func filterFromFlags(cmd *cobra.Command) (policy.Policy, error) {
+	ctx := cmd.Context()
+
+	filter := policy.Policy{}
+
+	if name := cmd.Flag("subject").Value.String(); name != "" {
+		filter.Subject = subject.Subject{Name: name}
+	}
+
+	if name := cmd.Flag("resource").Value.String(); name != "" {
+		filter.Resource = resource.Resource{Name: name}
+	}
+
+	if actionStr := cmd.Flag("action").Value.String(); actionStr != "" {
+		a, err := action.FromString(ctx, actionStr)
+		if err != nil {
+			return policy.Policy{}, err
+		}
+		filter.Action = a
+	}
+
+	if effectStr := cmd.Flag("effect").Value.String(); effectStr != "" {
+		effect, err := policy.EffectFromString(effectStr)
+		if err != nil {
+			return policy.Policy{}, err
+		}
+		filter.Effect = effect
+	}
+
+	specifierMap, err := cmd.Flags().GetStringToString("with")
+	if err != nil {
+		return policy.Policy{}, err
+	}
+	for k, v := range specifierMap {
+		filter.Specifiers.Specifiers = append(filter.Specifiers.Specifiers, specifier.Specifier{Key: k, Value: v})
+	}
+
+	return filter, nil
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List policies, optionally filtered by subject, resource, action, effect and specifiers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		policies, err := filter.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, result(policies))
+	},
+}
+
+func init() {
+	PolicyCmd.AddCommand(listCmd)
+
+	listCmd.Args = cobra.NoArgs
+
+	addPolicyFlags(listCmd)
+}
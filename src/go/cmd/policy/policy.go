@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var PolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Create, list, show, update and delete policies",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Help()
+			os.Exit(0)
+		}
+	},
+}
+
+// addPolicyFlags adds the flags describing a policy, shared by the subcommands.
+func addPolicyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("subject", "", "Name of the subject holding the policy")
+	cmd.Flags().String("resource", "", "Name of the resource the policy applies to")
+	cmd.Flags().String("action", "", "Action the policy allows or denies")
+	cmd.Flags().String("effect", "", "Effect of the policy: ALLOW or DENY")
+	cmd.Flags().StringToString("with", map[string]string{}, "Map of specifiers of the policy. Format: key1=value1,key2=value2")
+}
+
+// applyFlags overrides the fields of the policy with the flags that were set.
+// The subject and resource have to exist, so that the policy does not reference orphan nodes.
+func applyFlags(cmd *cobra.Command, p policy.Policy) (policy.Policy, error) {
+	ctx := cmd.Context()
+
+	if cmd.Flags().Changed("subject") {
+		name := cmd.Flag("subject").Value.String()
+		s, err := subject.Get(ctx, name)
+		if err != nil {
+			return policy.Policy{}, fmt.Errorf("%s: %w", name, err)
+		}
+		p.Subject = s
+	}
+
+	if cmd.Flags().Changed("resource") {
+		name := cmd.Flag("resource").Value.String()
+		r, err := resource.Get(ctx, name)
+		if err != nil {
+			return policy.Policy{}, fmt.Errorf("%s: %w", name, err)
+		}
+		p.Resource = r
+	}
+
+	if cmd.Flags().Changed("action") {
+		a, err := action.FromString(ctx, cmd.Flag("action").Value.String())
+		if err != nil {
+			return policy.Policy{}, err
+		}
+		p.Action = a
+	}
+
+	if cmd.Flags().Changed("effect") {
+		effect, err := policy.EffectFromString(cmd.Flag("effect").Value.String())
+		if err != nil {
+			return policy.Policy{}, err
+		}
+		p.Effect = effect
+	}
+
+	if cmd.Flags().Changed("with") {
+		specifierMap, err := cmd.Flags().GetStringToString("with")
+		if err != nil {
+			return policy.Policy{}, err
+		}
+
+		specifiers := []specifier.Specifier{}
+		for _, k := range slices.Sorted(maps.Keys(specifierMap)) {
+			specifiers = append(specifiers, specifier.Specifier{Key: k, Value: specifierMap[k]})
+		}
+		p.Specifiers = specifier.SpecifierGroup{Specifiers: specifiers}
+	}
+
+	return p, nil
+}
+
+// policyView is the output of a policy.
+type policyView struct {
+	Id               string            `json:"id" yaml:"id"`
+	Subject          subject.Subject   `json:"subject" yaml:"subject"`
+	Resource         string            `json:"resource" yaml:"resource"`
+	Action           string            `json:"action" yaml:"action"`
+	Effect           string            `json:"effect" yaml:"effect"`
+	Specifiers       map[string]string `json:"specifiers" yaml:"specifiers"`
+	EffectiveActions []string          `json:"effectiveActions" yaml:"effectiveActions"`
+}
+
+func view(p policy.Policy) policyView {
+	effectiveActions := []string{}
+	for _, a := range p.EffectiveActions {
+		effectiveActions = append(effectiveActions, string(a))
+	}
+
+	return policyView{
+		Id:               p.Id,
+		Subject:          p.Subject,
+		Resource:         p.Resource.Name,
+		Action:           string(p.Action),
+		Effect:           string(p.Effect),
+		Specifiers:       p.Specifiers.AsMap(),
+		EffectiveActions: effectiveActions,
+	}
+}
+
+// specifiers returns the specifiers of the policy as key=value pairs, sorted by key.
+func (v policyView) specifiers() string {
+	pairs := []string{}
+	for _, k := range slices.Sorted(maps.Keys(v.Specifiers)) {
+		pairs = append(pairs, k+"="+v.Specifiers[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// describe returns the policy on a single line, with its specifiers sorted by key.
+func (v policyView) describe() string {
+	return fmt.Sprintf("%s %s %s (%s) %s %s with %s",
+		v.Id, v.Effect, v.Subject.Name, v.Subject.Type, v.Action, v.Resource, v.specifiers())
+}
+
+// result returns the output of the policies, with the list of views as its value.
+func result(policies []policy.Policy) output.Result {
+	views := []policyView{}
+	result := output.Result{Header: []string{"id", "effect", "subject", "action", "resource", "specifiers"}, Text: []string{}}
+	for _, p := range policies {
+		v := view(p)
+		views = append(views, v)
+		result.Rows = append(result.Rows, []string{v.Id, v.Effect, v.Subject.Name, v.Action, v.Resource, v.specifiers()})
+		result.Text = append(result.Text, v.describe())
+	}
+	result.Value = views
+	return result
+}
+
+// single returns the output of a single policy, with the view as its value.
+func single(p policy.Policy) output.Result {
+	r := result([]policy.Policy{p})
+	r.Value = view(p)
+	return r
+}
+
+func init() {}
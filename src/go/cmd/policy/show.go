@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+
+	"fmt"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/spf13/cobra"
+)
+
+// getById returns the policy with the ID, failing if there is none.
+func getById(ctx context.Context, id string) (policy.Policy, error) {
+	p, err := policy.Policy{Id: id}.GetById(ctx)
+	if err != nil {
+		return policy.Policy{}, err
+	}
+	if p.Id == "" {
+		return policy.Policy{}, fmt.Errorf("%s: %w", id, policy.ErrPolicyNotFound)
+	}
+	return p, nil
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show id",
+	Short: "Show a policy, along with the actions it implies",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+
+		p, err := getById(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		shown := single(p)
+		shown.Text = append(shown.Text, fmt.Sprintf("Effective actions: %v", p.EffectiveActions))
+		return output.Print(cmd, shown)
+	},
+}
+
+func init() {
+	PolicyCmd.AddCommand(showCmd)
+
+	showCmd.Args = cobra.ExactArgs(1)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update id",
+	Short: "Replace a policy, changing only the fields given as flags",
+	Long: `Replace a policy, changing only the fields given as flags.
+Setting --with replaces all the specifiers of the policy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		existing, err := getById(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		updated, err := applyFlags(cmd, existing)
+		if err != nil {
+			return err
+		}
+
+		updated, err = existing.Update(ctx, updated)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, single(updated))
+	},
+}
+
+func init() {
+	PolicyCmd.AddCommand(updateCmd)
+
+	updateCmd.Args = cobra.ExactArgs(1)
+
+	addPolicyFlags(updateCmd)
+}
@@ -1,20 +1,63 @@
 package cmd
 
 import (
-	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
 	"github.com/namsnath/otter/query"
 	"github.com/namsnath/otter/resource"
-	"github.com/namsnath/otter/specifier"
 	"github.com/namsnath/otter/subject"
 	"github.com/spf13/cobra"
 )
 
+// canView is the output of a Can query.
+type canView struct {
+	Subject    subject.Subject   `json:"subject" yaml:"subject"`
+	Action     string            `json:"action" yaml:"action"`
+	Resource   string            `json:"resource" yaml:"resource"`
+	Specifiers map[string]string `json:"specifiers" yaml:"specifiers"`
+	Allowed    bool              `json:"allowed" yaml:"allowed"`
+}
+
+// explanationResult returns the output of an explanation, with one row per candidate policy.
+func explanationResult(explanation query.CanExplanation) output.Result {
+	result := output.Result{
+		Value:  explanation,
+		Header: []string{"policy", "effect", "action", "status", "failed keys"},
+		Text:   strings.Split(strings.TrimSuffix(explanation.Pretty(), "\n"), "\n"),
+	}
+
+	sections := []struct {
+		status   string
+		policies []query.PolicyExplanation
+	}{
+		{"granting", explanation.Granting},
+		{"denying", explanation.Denying},
+		{"unmatched", explanation.Unmatched},
+	}
+	for _, section := range sections {
+		for _, p := range section.policies {
+			failed := []string{}
+			for _, f := range p.FailedKeys {
+				failed = append(failed, f.Key+"="+f.Value)
+			}
+			result.Rows = append(result.Rows, []string{p.PolicyId, string(p.Effect), string(p.Action), section.status, strings.Join(failed, ",")})
+		}
+	}
+
+	return result
+}
+
 var canCmd = &cobra.Command{
 	Use:   "can subject",
 	Short: "Check if a subject can perform an action on a resource with given specifiers",
+	Long: `Check if a subject can perform an action on a resource with given specifiers.
+Exits with 0 when allowed, 1 when denied and 2 on errors.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
 		if len(args) == 0 {
 			cmd.Help()
 			return nil
@@ -27,7 +70,7 @@ var canCmd = &cobra.Command{
 			return subjectTypeErr
 		}
 
-		action, actionErr := action.FromString(cmd.Flag("perform").Value.String())
+		action, actionErr := action.FromString(ctx, cmd.Flag("perform").Value.String())
 		if actionErr != nil {
 			return actionErr
 		}
@@ -35,24 +78,49 @@ var canCmd = &cobra.Command{
 		on := cmd.Flag("on").Value.String()
 		resource := resource.Resource{Name: on}
 
-		specifierMap, err := cmd.Flags().GetStringToString("with")
+		specifierGroup, err := specifiersFromFlag(cmd)
 		if err != nil {
 			return err
 		}
 
-		specifiers := []specifier.Specifier{}
-		for k, v := range specifierMap {
-			specifiers = append(specifiers, specifier.Specifier{Key: k, Value: v})
+		s := subject.Subject{Name: subjectStr, Type: subjectType}
+		qb := query.Can(s).Perform(action).On(resource).With(specifierGroup)
+
+		// The answer is printed, and a denial only changes the exit code
+		cmd.SilenceUsage = true
+
+		if explain, _ := cmd.Flags().GetBool("explain"); explain {
+			explanation, err := qb.Explain(ctx)
+			if err != nil {
+				return err
+			}
+
+			if err := output.Print(cmd, explanationResult(explanation)); err != nil {
+				return err
+			}
+			if !explanation.Can {
+				return output.ErrDenied
+			}
+			return nil
 		}
-		specifierGroup := specifier.SpecifierGroup{Specifiers: specifiers}
 
-		can := query.Can(subject.Subject{Name: subjectStr, Type: subjectType}).Perform(action).On(resource).With(specifierGroup).Query()
+		can := qb.Query(ctx)
 		if can.Err != nil {
-			return err
+			return can.Err
 		}
 
-		fmt.Println(can.Pretty())
-
+		err = output.Print(cmd, output.Result{
+			Value:  canView{Subject: s, Action: string(action), Resource: on, Specifiers: specifierGroup.AsMap(), Allowed: can.Can},
+			Header: []string{"subject", "action", "resource", "allowed"},
+			Rows:   [][]string{{subjectStr, string(action), on, strconv.FormatBool(can.Can)}},
+			Text:   []string{can.Pretty()},
+		})
+		if err != nil {
+			return err
+		}
+		if !can.Can {
+			return output.ErrDenied
+		}
 		return nil
 	},
 }
@@ -66,4 +134,5 @@ func init() {
 	canCmd.Flags().String("perform", "", "Action to check permission for")
 	canCmd.Flags().String("on", "", "Parent resource under which to check permissions")
 	canCmd.Flags().StringToString("with", map[string]string{}, "Map of specifiers to check permissions with. Format: key1=value1,key2=value2")
+	canCmd.Flags().Bool("explain", false, "Show the policies granting or denying access, and the specifier keys that failed to match")
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+// batchCheck is a single entry of the can-batch input file.
+type batchCheck struct {
+	Subject    string            `json:"subject"`
+	Type       string            `json:"type"`
+	Action     string            `json:"action"`
+	Resource   string            `json:"resource"`
+	Specifiers map[string]string `json:"specifiers"`
+}
+
+// batchResultView is the output of a single check of the batch.
+type batchResultView struct {
+	Index    int    `json:"index" yaml:"index"`
+	Subject  string `json:"subject" yaml:"subject"`
+	Action   string `json:"action" yaml:"action"`
+	Resource string `json:"resource" yaml:"resource"`
+	Allowed  bool   `json:"allowed" yaml:"allowed"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (check batchCheck) toQuery(ctx context.Context) (query.CanQueryBuilder, error) {
+	subjectType := subject.SubjectTypePrincipal
+	if check.Type != "" {
+		var err error
+		subjectType, err = subject.SubjectTypeFromString(check.Type)
+		if err != nil {
+			return query.CanQueryBuilder{}, err
+		}
+	}
+
+	a, err := action.FromString(ctx, check.Action)
+	if err != nil {
+		return query.CanQueryBuilder{}, err
+	}
+
+	specifiers := []specifier.Specifier{}
+	for k, v := range check.Specifiers {
+		specifiers = append(specifiers, specifier.Specifier{Key: k, Value: v})
+	}
+
+	return query.Can(subject.Subject{Name: check.Subject, Type: subjectType}).
+		Perform(a).
+		On(resource.Resource{Name: check.Resource}).
+		With(specifier.SpecifierGroup{Specifiers: specifiers}), nil
+}
+
+var canBatchCmd = &cobra.Command{
+	Use:   "can-batch",
+	Short: "Run many Can checks in a single round trip",
+	Long: `Run many Can checks in a single round trip. The file holds a JSON array of checks:
+[{"subject": "Principal1", "type": "Principal", "action": "READ", "resource": "Resource1", "specifiers": {"Env": "prod"}}]
+Results are printed one per line, in input order.
+Exits with 0 when every check is allowed, 1 when any is denied and 2 when any fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		file := cmd.Flag("file").Value.String()
+
+		var input io.Reader = os.Stdin
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+
+		var checks []batchCheck
+		if err := json.NewDecoder(input).Decode(&checks); err != nil {
+			return fmt.Errorf("invalid checks file: %w", err)
+		}
+
+		queries := make([]query.CanQueryBuilder, len(checks))
+		checkErrors := make([]error, len(checks))
+		for i, check := range checks {
+			queries[i], checkErrors[i] = check.toQuery(ctx)
+		}
+
+		views := []batchResultView{}
+		result := output.Result{Header: []string{"index", "subject", "action", "resource", "allowed", "error"}, Text: []string{}}
+		failed, denied := 0, 0
+		for i, r := range query.CanBatch(ctx, queries) {
+			if checkErrors[i] != nil {
+				r = query.CanResult{Err: checkErrors[i]}
+			}
+
+			v := batchResultView{Index: i, Subject: checks[i].Subject, Action: checks[i].Action, Resource: checks[i].Resource, Allowed: r.Can}
+			switch {
+			case r.Err != nil:
+				v.Error = r.Err.Error()
+				failed++
+			case !r.Can:
+				denied++
+			}
+
+			views = append(views, v)
+			result.Rows = append(result.Rows, []string{strconv.Itoa(i), v.Subject, v.Action, v.Resource, strconv.FormatBool(v.Allowed), v.Error})
+			result.Text = append(result.Text, fmt.Sprintf("%d\t%s\t%s\t%s\t%s", i, v.Subject, v.Action, v.Resource, r.Pretty()))
+		}
+		result.Value = views
+
+		// The results are printed, and failed or denied checks only change the exit code
+		cmd.SilenceUsage = true
+		if err := output.Print(cmd, result); err != nil {
+			return err
+		}
+
+		switch {
+		case failed > 0:
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		case denied > 0:
+			return output.ErrDenied
+		}
+		return nil
+	},
+}
+
+func init() {
+	QueryCmd.AddCommand(canBatchCmd)
+
+	canBatchCmd.Args = cobra.NoArgs
+
+	canBatchCmd.Flags().StringP("file", "f", "-", "JSON file with the checks to run. Reads from stdin with -")
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var howCanCmd = &cobra.Command{
+	Use:   "how-can subject",
+	Short: "List the specifier combinations with which a subject can perform an action on a resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		subjectType, err := subject.SubjectTypeFromString(cmd.Flag("of-type").Value.String())
+		if err != nil {
+			return err
+		}
+
+		action, err := action.FromString(ctx, cmd.Flag("perform").Value.String())
+		if err != nil {
+			return err
+		}
+
+		resource := resource.Resource{Name: cmd.Flag("on").Value.String()}
+
+		specifierGroup, err := specifiersFromFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		groups, err := query.HowCan(subject.Subject{Name: args[0], Type: subjectType}).Perform(action).On(resource).With(specifierGroup).Query(ctx)
+		if err != nil {
+			return err
+		}
+
+		combinations := []map[string]string{}
+		for _, group := range groups {
+			combinations = append(combinations, group.AsMap())
+		}
+
+		// The rows hold the values of every key of the combinations, and are sorted in key order
+		keySet := map[string]bool{}
+		for _, combination := range combinations {
+			for key := range combination {
+				keySet[key] = true
+			}
+		}
+		keys := slices.Sorted(maps.Keys(keySet))
+		rows := [][]string{}
+		for _, combination := range combinations {
+			row := []string{}
+			for _, key := range keys {
+				row = append(row, combination[key])
+			}
+			rows = append(rows, row)
+		}
+		slices.SortFunc(rows, func(a, b []string) int {
+			return slices.Compare(a, b)
+		})
+
+		result := output.Result{Header: keys, Rows: rows, Text: []string{}}
+		values := []map[string]string{}
+		for _, row := range rows {
+			combination := map[string]string{}
+			pairs := []string{}
+			for i, key := range keys {
+				if row[i] == "" {
+					continue
+				}
+				combination[key] = row[i]
+				pairs = append(pairs, key+"="+row[i])
+			}
+			values = append(values, combination)
+			result.Text = append(result.Text, strings.Join(pairs, ","))
+		}
+		result.Value = values
+
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	QueryCmd.AddCommand(howCanCmd)
+
+	howCanCmd.Args = cobra.ExactArgs(1)
+
+	howCanCmd.Flags().String("of-type", string(subject.SubjectTypePrincipal), "The type of subject")
+	howCanCmd.Flags().String("perform", "", "Action to check permission for")
+	howCanCmd.Flags().String("on", "", "Resource to check permissions on")
+	howCanCmd.Flags().StringToString("with", map[string]string{}, "Map of specifiers to fix, listing only the combinations matching them. Format: key1=value1,key2=value2")
+	howCanCmd.MarkFlagRequired("perform")
+	howCanCmd.MarkFlagRequired("on")
+}
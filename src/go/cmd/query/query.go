@@ -3,12 +3,13 @@ package cmd
 import (
 	"os"
 
+	"github.com/namsnath/otter/specifier"
 	"github.com/spf13/cobra"
 )
 
 var QueryCmd = &cobra.Command{
 	Use:   "query",
-	Short: "A brief description of your command",
+	Short: "Answer access questions: can, who-can, what-can and how-can",
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			cmd.Help()
@@ -17,4 +18,18 @@ var QueryCmd = &cobra.Command{
 	},
 }
 
+// specifiersFromFlag returns the specifiers given with the `--with` flag.
+func specifiersFromFlag(cmd *cobra.Command) (specifier.SpecifierGroup, error) {
+	specifierMap, err := cmd.Flags().GetStringToString("with")
+	if err != nil {
+		return specifier.SpecifierGroup{}, err
+	}
+
+	specifiers := []specifier.Specifier{}
+	for k, v := range specifierMap {
+		specifiers = append(specifiers, specifier.Specifier{Key: k, Value: v})
+	}
+	return specifier.SpecifierGroup{Specifiers: specifiers}, nil
+}
+
 func init() {}
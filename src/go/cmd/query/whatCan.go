@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+// accessibleResourceView is the output of a resource, with the values of each key it is accessible with.
+type accessibleResourceView struct {
+	Name       string              `json:"name" yaml:"name"`
+	Specifiers map[string][]string `json:"specifiers" yaml:"specifiers"`
+}
+
+var whatCanCmd = &cobra.Command{
+	Use:   "what-can subject",
+	Short: "List the resources under a parent on which a subject can perform an action",
+	Long: `List the resources under a parent on which a subject can perform an action.
+With --all-specifiers, the keys missing from --with are expanded, and every resource
+is listed along with the values of each key it is accessible with.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		subjectType, err := subject.SubjectTypeFromString(cmd.Flag("of-type").Value.String())
+		if err != nil {
+			return err
+		}
+
+		action, err := action.FromString(ctx, cmd.Flag("perform").Value.String())
+		if err != nil {
+			return err
+		}
+
+		under := resource.Resource{Name: cmd.Flag("under").Value.String()}
+
+		specifierGroup, err := specifiersFromFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		qb := query.WhatCan(subject.Subject{Name: args[0], Type: subjectType}).Perform(action).Under(under).With(specifierGroup)
+
+		if allSpecifiers, _ := cmd.Flags().GetBool("all-specifiers"); allSpecifiers {
+			resources, err := qb.QueryWithoutAllSpecifiers(ctx)
+			if err != nil {
+				return err
+			}
+
+			names := map[string]resource.Resource{}
+			for r := range resources {
+				names[r.Name] = r
+			}
+
+			views := []accessibleResourceView{}
+			result := output.Result{Header: []string{"name", "specifiers"}, Text: []string{}}
+			for _, name := range slices.Sorted(maps.Keys(names)) {
+				specifiers := resources[names[name]]
+				v := accessibleResourceView{Name: name, Specifiers: map[string][]string{}}
+				pairs := []string{}
+				for _, key := range slices.Sorted(maps.Keys(specifiers)) {
+					values := []string{}
+					for _, s := range specifiers[key] {
+						values = append(values, s.Value)
+					}
+					slices.Sort(values)
+					v.Specifiers[key] = values
+					pairs = append(pairs, key+"="+strings.Join(values, "|"))
+				}
+
+				views = append(views, v)
+				result.Rows = append(result.Rows, []string{name, strings.Join(pairs, ",")})
+				result.Text = append(result.Text, fmt.Sprintf("%s with %s", name, strings.Join(pairs, ",")))
+			}
+			result.Value = views
+
+			return output.Print(cmd, result)
+		}
+
+		resources, err := qb.Query(ctx)
+		if err != nil {
+			return err
+		}
+
+		result := output.Result{Value: resources, Header: []string{"name"}}
+		for _, r := range resources {
+			result.Rows = append(result.Rows, []string{r.Name})
+		}
+
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	QueryCmd.AddCommand(whatCanCmd)
+
+	whatCanCmd.Args = cobra.ExactArgs(1)
+
+	whatCanCmd.Flags().String("of-type", string(subject.SubjectTypePrincipal), "The type of subject")
+	whatCanCmd.Flags().String("perform", "", "Action to check permission for")
+	whatCanCmd.Flags().String("under", "_", "Parent resource under which to list resources")
+	whatCanCmd.Flags().StringToString("with", map[string]string{}, "Map of specifiers to check permissions with. Format: key1=value1,key2=value2")
+	whatCanCmd.Flags().Bool("all-specifiers", false, "Expand the keys missing from --with, listing the values each resource is accessible with")
+	whatCanCmd.MarkFlagRequired("perform")
+}
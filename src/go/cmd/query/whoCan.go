@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var whoCanCmd = &cobra.Command{
+	Use:   "who-can",
+	Short: "List the subjects of a type that can perform an action on a resource with given specifiers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		subjectType, err := subject.SubjectTypeFromString(cmd.Flag("of-type").Value.String())
+		if err != nil {
+			return err
+		}
+
+		action, err := action.FromString(ctx, cmd.Flag("perform").Value.String())
+		if err != nil {
+			return err
+		}
+
+		resource := resource.Resource{Name: cmd.Flag("on").Value.String()}
+
+		specifierGroup, err := specifiersFromFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		subjects, err := query.WhoCan(subjectType).Perform(action).On(resource).With(specifierGroup).Query(ctx)
+		if err != nil {
+			return err
+		}
+
+		result := output.Result{Value: subjects, Header: []string{"name", "type"}, Text: []string{}}
+		for _, s := range subjects {
+			result.Rows = append(result.Rows, []string{s.Name, string(s.Type)})
+			result.Text = append(result.Text, fmt.Sprintf("%s (%s)", s.Name, s.Type))
+		}
+
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	QueryCmd.AddCommand(whoCanCmd)
+
+	whoCanCmd.Args = cobra.NoArgs
+
+	whoCanCmd.Flags().String("of-type", string(subject.SubjectTypePrincipal), "The type of subjects to list")
+	whoCanCmd.Flags().String("perform", "", "Action to check permission for")
+	whoCanCmd.Flags().String("on", "", "Resource to check permissions on")
+	whoCanCmd.Flags().StringToString("with", map[string]string{}, "Map of specifiers to check permissions with. Format: key1=value1,key2=value2")
+	whoCanCmd.MarkFlagRequired("perform")
+	whoCanCmd.MarkFlagRequired("on")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create name",
+	Short: "Create a resource, optionally under an existing parent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		parentName := cmd.Flag("parent").Value.String()
+
+		if _, err := resource.Get(ctx, args[0]); err == nil {
+			return resource.ErrResourceExists
+		} else if !errors.Is(err, resource.ErrResourceNotFound) {
+			return err
+		}
+
+		r := resource.NewResource(args[0])
+		v := resourceView{Name: r.Name, Parents: []string{}}
+		if parentName == "" {
+			if _, err := r.Create(ctx); err != nil {
+				return err
+			}
+		} else {
+			// Creating the resource checks its parent, and creates nothing if it is missing
+			parent := resource.NewResource(parentName)
+			if _, err := r.CreateAsChildOf(ctx, parent); err != nil {
+				return err
+			}
+			v.Parents = append(v.Parents, parent.Name)
+		}
+
+		created := result([]resourceView{v})
+		created.Value = v
+		created.Text = []string{r.Name}
+		return output.Print(cmd, created)
+	},
+}
+
+func init() {
+	ResourceCmd.AddCommand(createCmd)
+
+	createCmd.Args = cobra.ExactArgs(1)
+
+	createCmd.Flags().String("parent", "", "Parent resource. The resource is a root if empty")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete name",
+	Short: "Delete a resource, refusing if it holds policies or has children unless --mode says otherwise",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		modeName, _ := cmd.Flags().GetString("mode")
+		mode, err := db.DeleteModeFromString(modeName)
+		if err != nil {
+			return err
+		}
+
+		return resource.NewResource(args[0]).Delete(ctx, mode)
+	},
+}
+
+func init() {
+	ResourceCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Args = cobra.ExactArgs(1)
+	deleteCmd.Flags().String("mode", string(db.DeleteRefuse), "refuse, cascade (delete its policies and detach its children) or reparent (delete its policies and attach its children to its parents)")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/spf13/cobra"
+)
+
+// resourceView is the output of a resource, with its direct parents.
+type resourceView struct {
+	Name    string   `json:"name" yaml:"name"`
+	Parents []string `json:"parents" yaml:"parents"`
+}
+
+// describe returns the resource, followed by its parents if it has any.
+func (v resourceView) describe() string {
+	if len(v.Parents) == 0 {
+		return v.Name
+	}
+	return fmt.Sprintf("%s -> %s", v.Name, strings.Join(v.Parents, ", "))
+}
+
+// result returns the output of the resources, with the list of views as its value.
+func result(views []resourceView) output.Result {
+	result := output.Result{Value: views, Header: []string{"name", "parents"}, Text: []string{}}
+	for _, v := range views {
+		result.Rows = append(result.Rows, []string{v.Name, strings.Join(v.Parents, ",")})
+		result.Text = append(result.Text, v.describe())
+	}
+	return result
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List resources, with their direct parents",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		resources, err := resource.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		views := []resourceView{}
+		for _, r := range resources {
+			parents, err := r.Parents(ctx)
+			if err != nil {
+				return err
+			}
+
+			names := []string{}
+			for _, parent := range parents {
+				names = append(names, parent.Name)
+			}
+			views = append(views, resourceView{Name: r.Name, Parents: names})
+		}
+
+		return output.Print(cmd, result(views))
+	},
+}
+
+func init() {
+	ResourceCmd.AddCommand(listCmd)
+
+	listCmd.Args = cobra.NoArgs
+}
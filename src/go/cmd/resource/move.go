@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/spf13/cobra"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move name",
+	Short: "Move a resource under another parent, after previewing the access changes",
+	Long: `Move a resource under another parent, replacing its current parents.
+The accesses gained and lost by the move are printed first, and the move is made once confirmed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		defer db.GetInstance().Close()
+
+		r, err := resource.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		parentName := cmd.Flag("to").Value.String()
+		parent, err := resource.Get(ctx, parentName)
+		if err != nil {
+			return fmt.Errorf("parent %s: %w", parentName, err)
+		}
+
+		impact, err := query.ResourceMoveImpact(ctx, r, parent)
+		if err != nil {
+			return err
+		}
+
+		result := output.Impact(impact)
+		if dryRun {
+			return output.Print(cmd, result)
+		}
+
+		if !yes {
+			// The changes are listed before the prompt, and not printed again once moved
+			for _, line := range result.Text {
+				fmt.Fprintln(output.PromptWriter(cmd), line)
+			}
+			if !output.Confirm(cmd, fmt.Sprintf("\nMove %s under %s?", r.Name, parent.Name)) {
+				result.Text = []string{"Move cancelled."}
+				return output.Print(cmd, result)
+			}
+			result.Text = []string{}
+		}
+
+		if err := r.MoveTo(ctx, parent); err != nil {
+			return err
+		}
+
+		result.Text = append(result.Text, fmt.Sprintf("Moved %s under %s.", r.Name, parent.Name))
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	ResourceCmd.AddCommand(moveCmd)
+
+	moveCmd.Args = cobra.ExactArgs(1)
+
+	moveCmd.Flags().String("to", "", "New parent resource")
+	moveCmd.MarkFlagRequired("to")
+	moveCmd.Flags().Bool("dry-run", false, "Only print the accesses gained and lost by the move")
+	moveCmd.Flags().BoolP("yes", "y", false, "Move without asking for confirmation")
+}
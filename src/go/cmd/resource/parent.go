@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach name parent",
+	Short: "Add a parent to a resource, keeping its other parents",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		r, err := resource.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		parent, err := resource.Get(ctx, args[1])
+		if err != nil {
+			return fmt.Errorf("parent %s: %w", args[1], err)
+		}
+
+		return r.AttachTo(ctx, parent)
+	},
+}
+
+var detachCmd = &cobra.Command{
+	Use:   "detach name parent",
+	Short: "Remove a direct parent from a resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		r, err := resource.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		return r.DetachFrom(ctx, resource.NewResource(args[1]))
+	},
+}
+
+func init() {
+	ResourceCmd.AddCommand(attachCmd)
+	ResourceCmd.AddCommand(detachCmd)
+
+	attachCmd.Args = cobra.ExactArgs(2)
+	detachCmd.Args = cobra.ExactArgs(2)
+}
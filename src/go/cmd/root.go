@@ -1,15 +1,48 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
 
+	action "github.com/namsnath/otter/cmd/action"
+	"github.com/namsnath/otter/cmd/output"
+	policy "github.com/namsnath/otter/cmd/policy"
 	query "github.com/namsnath/otter/cmd/query"
+	resource "github.com/namsnath/otter/cmd/resource"
+	specifier "github.com/namsnath/otter/cmd/specifier"
+	subject "github.com/namsnath/otter/cmd/subject"
+	"github.com/namsnath/otter/config"
+	"github.com/namsnath/otter/db"
 	"github.com/spf13/cobra"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "otter",
 	Short: "otter: graph-based authorization system",
+	Long: `otter: graph-based authorization system
+
+Every command prints its result in the format selected with --output.
+Errors are printed to stderr, as {"error": {"code", "message"}} in the json and yaml formats.
+The exit code is 0 on success, 1 when a query denies access, and 2 on errors.
+
+The database connection is configured with flags, OTTER_* environment variables
+and the otter.yaml config file, in that order of precedence.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.Validate(cmd); err != nil {
+			return err
+		}
+		// Arguments and flags are valid at this point, so errors are not about usage
+		cmd.SilenceUsage = true
+
+		dbConfig, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		return db.SetupInstance(cmd.Context(), dbConfig)
+	},
+	SilenceErrors: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			cmd.Help()
@@ -18,14 +51,30 @@ var RootCmd = &cobra.Command{
 	},
 }
 
+// Execute runs the command line. Interrupting it cancels the context of the running command.
 func Execute() {
-	err := RootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	cmd, err := RootCmd.ExecuteContextC(ctx)
+	stop()
+	if err != nil && !errors.Is(err, output.ErrDenied) {
+		output.PrintError(cmd.ErrOrStderr(), output.Of(cmd), err)
 	}
+	os.Exit(output.ExitCode(err))
 }
 
 func init() {
+	output.AddFlag(RootCmd)
+	config.AddFlags(RootCmd.PersistentFlags())
+
+	RootCmd.AddCommand(action.ActionCmd)
+	RootCmd.AddCommand(ApplyCmd)
+	RootCmd.AddCommand(ExportCmd)
+	RootCmd.AddCommand(ImportCmd)
+	RootCmd.AddCommand(policy.PolicyCmd)
 	RootCmd.AddCommand(query.QueryCmd)
+	RootCmd.AddCommand(resource.ResourceCmd)
+	RootCmd.AddCommand(specifier.SpecifierCmd)
+	RootCmd.AddCommand(subject.SubjectCmd)
 	RootCmd.AddCommand(SetupCmd)
+	RootCmd.AddCommand(ServeCmd)
 }
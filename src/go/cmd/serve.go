@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/server"
+	"github.com/spf13/cobra"
+)
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the authorization and policy APIs over HTTP and gRPC",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := cmd.Flag("addr").Value.String()
+		grpcAddr := cmd.Flag("grpc-addr").Value.String()
+		defer db.GetInstance().Close()
+
+		errs := make(chan error, 2)
+
+		go func() {
+			slog.Info("Serving HTTP API", "addr", addr)
+			errs <- http.ListenAndServe(addr, server.NewHandler())
+		}()
+
+		if grpcAddr != "" {
+			listener, err := net.Listen("tcp", grpcAddr)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				slog.Info("Serving gRPC API", "addr", grpcAddr)
+				errs <- server.NewGRPCServer().Serve(listener)
+			}()
+		}
+
+		return <-errs
+	},
+}
+
+func init() {
+	ServeCmd.Flags().String("addr", ":8080", "Address to listen on for HTTP requests")
+	ServeCmd.Flags().String("grpc-addr", "", "Address to listen on for gRPC requests. Disabled if empty")
+}
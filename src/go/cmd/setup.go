@@ -9,11 +9,14 @@ import (
 var SetupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Delete everything and set up test state in the database",
-	Run: func(cmd *cobra.Command, args []string) {
-		instance := db.GetInstance()
-		query.DeleteEverything()
-		query.SetupTestState()
-		instance.Close()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		if err := query.DeleteEverything(ctx); err != nil {
+			return err
+		}
+		return query.SetupTestState(ctx)
 	},
 }
 
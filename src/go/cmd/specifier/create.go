@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/specifier"
+	"github.com/spf13/cobra"
+)
+
+// parseSpecifier parses a `key=value` argument.
+func parseSpecifier(arg string) (specifier.Specifier, error) {
+	key, value, found := strings.Cut(arg, "=")
+	if !found || key == "" || value == "" {
+		return specifier.Specifier{}, fmt.Errorf("invalid specifier %q: expected key=value", arg)
+	}
+	return specifier.NewSpecifier(key, value), nil
+}
+
+// printCreated prints the created specifier, along with its parent if it has one.
+func printCreated(cmd *cobra.Command, s specifier.Specifier, parents ...specifier.Specifier) error {
+	v := specifierView{Key: s.Key, Value: s.Value, Parents: []string{}}
+	for _, parent := range parents {
+		v.Parents = append(v.Parents, parent.Key+"="+parent.Value)
+	}
+
+	created := result([]specifierView{v})
+	created.Value = v
+	created.Text = []string{s.Key + "=" + s.Value}
+	return output.Print(cmd, created)
+}
+
+var createCmd = &cobra.Command{
+	Use:   "create key=value",
+	Short: "Create a specifier under an existing parent",
+	Long: `Create a specifier under an existing parent.
+The root specifier *=* has no parent, and the root of a key, key=*, is created under it.
+Other values are created under key=*, or under the value given with --parent.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		s, err := parseSpecifier(args[0])
+		if err != nil {
+			return err
+		}
+
+		if _, err := specifier.Get(ctx, s.Key, s.Value); err == nil {
+			return specifier.ErrSpecifierExists
+		} else if !errors.Is(err, specifier.ErrSpecifierNotFound) {
+			return err
+		}
+
+		if s.Key == "*" && s.Value == "*" {
+			if _, err := s.Create(ctx); err != nil {
+				return err
+			}
+			return printCreated(cmd, s)
+		}
+
+		parent := specifier.NewSpecifier(s.Key, "*")
+		if s.Value == "*" {
+			parent = specifier.NewSpecifier("*", "*")
+		}
+		if parentValue := cmd.Flag("parent").Value.String(); parentValue != "" {
+			parent = specifier.NewSpecifier(s.Key, parentValue)
+		}
+
+		// Creating the specifier checks its parent, and creates nothing if it is missing
+		if _, err := s.CreateAsChildOf(ctx, parent); err != nil {
+			return err
+		}
+
+		return printCreated(cmd, s, parent)
+	},
+}
+
+func init() {
+	SpecifierCmd.AddCommand(createCmd)
+
+	createCmd.Args = cobra.ExactArgs(1)
+
+	createCmd.Flags().String("parent", "", "Value of the parent specifier, with the same key. Defaults to *")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete key=value",
+	Short: "Delete a specifier, refusing if policies point at it or it has children unless --mode says otherwise",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		modeName, _ := cmd.Flags().GetString("mode")
+		mode, err := db.DeleteModeFromString(modeName)
+		if err != nil {
+			return err
+		}
+
+		s, err := parseSpecifier(args[0])
+		if err != nil {
+			return err
+		}
+
+		return s.Delete(ctx, mode)
+	},
+}
+
+func init() {
+	SpecifierCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Args = cobra.ExactArgs(1)
+	deleteCmd.Flags().String("mode", string(db.DeleteRefuse), "refuse, cascade (delete its children too, with the policies pointing at any of them) or reparent (delete the policies pointing at it and give its children its parents)")
+}
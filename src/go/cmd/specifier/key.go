@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/specifier"
+	"github.com/spf13/cobra"
+)
+
+// keyResult returns the output of the specifier keys, with the list of keys as its value.
+func keyResult(keys []specifier.SpecifierKey) output.Result {
+	result := output.Result{Value: keys, Header: []string{"name", "type", "values", "default", "required", "description"}, Text: []string{}}
+	for _, k := range keys {
+		result.Rows = append(result.Rows, []string{k.Name, k.Type.String(), strings.Join(k.Values, ","), k.Default, strconv.FormatBool(k.Required), k.Description})
+
+		values := "any value"
+		if k.Type.Typed() {
+			values = fmt.Sprintf("%s constraints", k.Type)
+		} else if len(k.Values) > 0 {
+			values = strings.Join(k.Values, ", ")
+		}
+		line := fmt.Sprintf("%s: %s", k.Name, values)
+		if k.Default != "" {
+			line += fmt.Sprintf(" (default %s)", k.Default)
+		}
+		if k.Required {
+			line += " (required)"
+		}
+		if k.Description != "" {
+			line += " - " + k.Description
+		}
+		result.Text = append(result.Text, line)
+	}
+	return result
+}
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage the registered specifier keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Help()
+			os.Exit(0)
+		}
+	},
+}
+
+var keyCreateCmd = &cobra.Command{
+	Use:   "create name",
+	Short: "Register a specifier key, creating its root and values if missing",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		values, err := cmd.Flags().GetStringSlice("values")
+		if err != nil {
+			return err
+		}
+		required, _ := cmd.Flags().GetBool("required")
+		specifierType, err := specifier.SpecifierTypeFromString(cmd.Flag("type").Value.String())
+		if err != nil {
+			return err
+		}
+
+		key, err := specifier.SpecifierKey{
+			Name:        args[0],
+			Type:        specifierType,
+			Description: cmd.Flag("description").Value.String(),
+			Values:      values,
+			Default:     cmd.Flag("default").Value.String(),
+			Required:    required,
+		}.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		created := keyResult([]specifier.SpecifierKey{key})
+		created.Value = key
+		return output.Print(cmd, created)
+	},
+}
+
+var keyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the registered specifier keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		keys, err := specifier.ListKeys(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, keyResult(keys))
+	},
+}
+
+var keyDeleteCmd = &cobra.Command{
+	Use:   "delete name",
+	Short: "Unregister a specifier key, keeping its specifiers and the policies pointing at them",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		return specifier.SpecifierKey{Name: args[0]}.Delete(ctx)
+	},
+}
+
+func init() {
+	SpecifierCmd.AddCommand(keyCmd)
+	keyCmd.AddCommand(keyCreateCmd, keyListCmd, keyDeleteCmd)
+
+	keyCreateCmd.Args = cobra.ExactArgs(1)
+	keyListCmd.Args = cobra.NoArgs
+	keyDeleteCmd.Args = cobra.ExactArgs(1)
+
+	keyCreateCmd.Flags().String("description", "", "What the key describes")
+	keyCreateCmd.Flags().String("type", "string", "Type of the key: string, or int, time, date, cidr or semver for keys whose specifiers are constraints on the values of queries")
+	keyCreateCmd.Flags().StringSlice("values", []string{}, "Values the key accepts, any value if empty. Format: value1,value2")
+	keyCreateCmd.Flags().String("default", "", "Value used by queries that do not pass the key, instead of *")
+	keyCreateCmd.Flags().Bool("required", false, "Fail queries that do not pass the key and have no default")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/specifier"
+	"github.com/spf13/cobra"
+)
+
+// specifierView is the output of a specifier, with its direct parents as key=value.
+type specifierView struct {
+	Key     string   `json:"key" yaml:"key"`
+	Value   string   `json:"value" yaml:"value"`
+	Parents []string `json:"parents" yaml:"parents"`
+}
+
+// describe returns the specifier, followed by its parents if it has any.
+func (v specifierView) describe() string {
+	if len(v.Parents) == 0 {
+		return v.Key + "=" + v.Value
+	}
+	return fmt.Sprintf("%s=%s -> %s", v.Key, v.Value, strings.Join(v.Parents, ", "))
+}
+
+// result returns the output of the specifiers, with the list of views as its value.
+func result(views []specifierView) output.Result {
+	result := output.Result{Value: views, Header: []string{"key", "value", "parents"}, Text: []string{}}
+	for _, v := range views {
+		result.Rows = append(result.Rows, []string{v.Key, v.Value, strings.Join(v.Parents, ",")})
+		result.Text = append(result.Text, v.describe())
+	}
+	return result
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List specifiers, with their direct parents",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		specifiers, err := specifier.List(ctx, cmd.Flag("key").Value.String())
+		if err != nil {
+			return err
+		}
+
+		views := []specifierView{}
+		for _, s := range specifiers {
+			parents, err := s.Parents(ctx)
+			if err != nil {
+				return err
+			}
+
+			names := []string{}
+			for _, parent := range parents {
+				names = append(names, parent.Key+"="+parent.Value)
+			}
+			views = append(views, specifierView{Key: s.Key, Value: s.Value, Parents: names})
+		}
+
+		return output.Print(cmd, result(views))
+	},
+}
+
+func init() {
+	SpecifierCmd.AddCommand(listCmd)
+
+	listCmd.Args = cobra.NoArgs
+
+	listCmd.Flags().String("key", "", "Only list specifiers of the key")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create name",
+	Short: "Create a subject, optionally as a member of existing groups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		subjectType, err := subject.SubjectTypeFromString(cmd.Flag("type").Value.String())
+		if err != nil {
+			return err
+		}
+
+		groupNames, err := cmd.Flags().GetStringSlice("group")
+		if err != nil {
+			return err
+		}
+
+		if _, err := subject.Get(ctx, args[0]); err == nil {
+			return subject.ErrSubjectExists
+		} else if !errors.Is(err, subject.ErrSubjectNotFound) {
+			return err
+		}
+
+		// The groups are looked up in the transaction creating the subject, so none can disappear in between
+		s := subject.Subject{Name: args[0], Type: subjectType}
+		err = db.InTx(ctx, func(ctx context.Context) error {
+			groups := []subject.Subject{}
+			for _, name := range groupNames {
+				group, err := subject.GetGroup(ctx, name)
+				if err != nil {
+					return fmt.Errorf("group %s: %w", name, err)
+				}
+				groups = append(groups, group)
+			}
+
+			if len(groups) == 0 {
+				_, err := s.Create(ctx)
+				return err
+			}
+
+			if _, err := s.CreateAsChildOf(ctx, groups[0]); err != nil {
+				return err
+			}
+			for _, group := range groups[1:] {
+				if err := s.AddToGroup(ctx, group); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		created := single(subjectView{Name: s.Name, Type: string(s.Type), Groups: groupNames})
+		created.Text = []string{fmt.Sprintf("%s (%s)", s.Name, s.Type)}
+		return output.Print(cmd, created)
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(createCmd)
+
+	createCmd.Args = cobra.ExactArgs(1)
+
+	createCmd.Flags().String("type", string(subject.SubjectTypePrincipal), "The type of subject: Principal or Group")
+	createCmd.Flags().StringSlice("group", []string{}, "Groups the subject is a member of. Format: group1,group2")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete name",
+	Short: "Delete a subject, refusing if it holds policies or has members unless --mode says otherwise",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		modeName, _ := cmd.Flags().GetString("mode")
+		mode, err := db.DeleteModeFromString(modeName)
+		if err != nil {
+			return err
+		}
+
+		s, err := subject.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		return s.Delete(ctx, mode)
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Args = cobra.ExactArgs(1)
+	deleteCmd.Flags().String("mode", string(db.DeleteRefuse), "refuse, cascade (delete its policies and remove its members) or reparent (delete its policies and add its members to its groups)")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get name",
+	Short: "Show a subject with the groups it is a direct member of",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		s, err := subject.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		v, err := view(ctx, s)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(cmd, single(v))
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(getCmd)
+
+	getCmd.Args = cobra.ExactArgs(1)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var addToGroupCmd = &cobra.Command{
+	Use:   "add-to-group name group",
+	Short: "Make a subject a member of an existing group",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		s, err := subject.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		group, err := subject.GetGroup(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		return s.AddToGroup(ctx, group)
+	},
+}
+
+var removeFromGroupCmd = &cobra.Command{
+	Use:   "remove-from-group name group",
+	Short: "Remove a subject from a group it is a direct member of",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		s, err := subject.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		group, err := subject.GetGroup(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		return s.RemoveFromGroup(ctx, group)
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(addToGroupCmd)
+	SubjectCmd.AddCommand(removeFromGroupCmd)
+
+	addToGroupCmd.Args = cobra.ExactArgs(2)
+	removeFromGroupCmd.Args = cobra.ExactArgs(2)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+
+	"fmt"
+	"strings"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+// subjectView is the output of a subject, with the groups it is a direct member of.
+type subjectView struct {
+	Name   string   `json:"name" yaml:"name"`
+	Type   string   `json:"type" yaml:"type"`
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+func view(ctx context.Context, s subject.Subject) (subjectView, error) {
+	groups, err := s.Groups(ctx)
+	if err != nil {
+		return subjectView{}, err
+	}
+
+	names := []string{}
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return subjectView{Name: s.Name, Type: string(s.Type), Groups: names}, nil
+}
+
+// describe returns the subject with its type, followed by its groups if it has any.
+func (v subjectView) describe() string {
+	description := fmt.Sprintf("%s (%s)", v.Name, v.Type)
+	if len(v.Groups) == 0 {
+		return description
+	}
+	return fmt.Sprintf("%s -> %s", description, strings.Join(v.Groups, ", "))
+}
+
+// result returns the output of the subjects, with the list of views as its value.
+func result(views []subjectView) output.Result {
+	result := output.Result{Value: views, Header: []string{"name", "type", "groups"}, Text: []string{}}
+	for _, v := range views {
+		result.Rows = append(result.Rows, []string{v.Name, v.Type, strings.Join(v.Groups, ",")})
+		result.Text = append(result.Text, v.describe())
+	}
+	return result
+}
+
+// single returns the output of a single subject, with the view as its value.
+func single(v subjectView) output.Result {
+	r := result([]subjectView{v})
+	r.Value = v
+	return r
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subjects, with the groups they are direct members of",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer db.GetInstance().Close()
+		ctx := cmd.Context()
+
+		var subjectType subject.SubjectType
+		if typeStr := cmd.Flag("type").Value.String(); typeStr != "" {
+			var err error
+			subjectType, err = subject.SubjectTypeFromString(typeStr)
+			if err != nil {
+				return err
+			}
+		}
+
+		subjects, err := subject.List(ctx, subjectType)
+		if err != nil {
+			return err
+		}
+
+		views := []subjectView{}
+		for _, s := range subjects {
+			v, err := view(ctx, s)
+			if err != nil {
+				return err
+			}
+			views = append(views, v)
+		}
+
+		return output.Print(cmd, result(views))
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(listCmd)
+
+	listCmd.Args = cobra.NoArgs
+
+	listCmd.Flags().String("type", "", "Only list subjects of the type: Principal or Group")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/namsnath/otter/cmd/output"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/subject"
+	"github.com/spf13/cobra"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move name",
+	Short: "Move a subject into another group, after previewing the access changes",
+	Long: `Move a subject into another group, replacing the groups it is a direct member of.
+The accesses gained and lost by the subject and its members are printed first, and the move
+is made once confirmed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		defer db.GetInstance().Close()
+
+		s, err := subject.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		groupName := cmd.Flag("to").Value.String()
+		group, err := subject.GetGroup(ctx, groupName)
+		if err != nil {
+			return fmt.Errorf("group %s: %w", groupName, err)
+		}
+
+		impact, err := query.SubjectMoveImpact(ctx, s, group)
+		if err != nil {
+			return err
+		}
+
+		result := output.Impact(impact)
+		if dryRun {
+			return output.Print(cmd, result)
+		}
+
+		if !yes {
+			// The changes are listed before the prompt, and not printed again once moved
+			for _, line := range result.Text {
+				fmt.Fprintln(output.PromptWriter(cmd), line)
+			}
+			if !output.Confirm(cmd, fmt.Sprintf("\nMove %s into %s?", s.Name, group.Name)) {
+				result.Text = []string{"Move cancelled."}
+				return output.Print(cmd, result)
+			}
+			result.Text = []string{}
+		}
+
+		if err := s.MoveTo(ctx, group); err != nil {
+			return err
+		}
+
+		result.Text = append(result.Text, fmt.Sprintf("Moved %s into %s.", s.Name, group.Name))
+		return output.Print(cmd, result)
+	},
+}
+
+func init() {
+	SubjectCmd.AddCommand(moveCmd)
+
+	moveCmd.Args = cobra.ExactArgs(1)
+
+	moveCmd.Flags().String("to", "", "New group")
+	moveCmd.MarkFlagRequired("to")
+	moveCmd.Flags().Bool("dry-run", false, "Only print the accesses gained and lost by the move")
+	moveCmd.Flags().BoolP("yes", "y", false, "Move without asking for confirmation")
+}
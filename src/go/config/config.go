@@ -0,0 +1,192 @@
+// Package config resolves the database connection settings of the CLI.
+// Flags take precedence over environment variables, which take precedence over the config file.
+//
+// The config file holds base settings and named profiles overriding them:
+//
+//	profile: dev
+//	username: neo4j
+//	profiles:
+//	  dev:
+//	    uri: bolt://localhost:7687
+//	    password: password
+//	  prod:
+//	    uri: neo4j+s://neo4j.example.com:7687
+//	    database: otter
+//	    max_connection_pool_size: 50
+//	    tls:
+//	      ca_file: /etc/otter/ca.pem
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file read when none is given. It is optional.
+const DefaultPath = "otter.yaml"
+
+// EnvPrefix prefixes the environment variables of every setting, e.g. OTTER_DB_URI.
+const EnvPrefix = "OTTER_"
+
+const (
+	configFlag  = "config"
+	profileFlag = "profile"
+)
+
+var ErrInvalidConfig = db.NewError(db.ErrInvalidInput, "invalid config")
+var ErrUnknownProfile = db.NewError(db.ErrInvalidInput, "unknown profile")
+
+// file is the layout of the config file.
+type file struct {
+	Profile   string               `yaml:"profile"`
+	Profiles  map[string]yaml.Node `yaml:"profiles"`
+	db.Config `yaml:",inline"`
+}
+
+// setting is a connection setting that can be given as a flag or an environment variable.
+type setting struct {
+	name  string
+	usage string
+	set   func(c *db.Config, value string) error
+}
+
+// envName returns the environment variable of the flag, e.g. OTTER_DB_URI for db-uri.
+func envName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+func stringSetting(field func(c *db.Config) *string) func(c *db.Config, value string) error {
+	return func(c *db.Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func intSetting(field func(c *db.Config) *int) func(c *db.Config, value string) error {
+	return func(c *db.Config, value string) error {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = i
+		return nil
+	}
+}
+
+func durationSetting(field func(c *db.Config) *time.Duration) func(c *db.Config, value string) error {
+	return func(c *db.Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+var settings = []setting{
+	{"db-uri", "Neo4j URI, e.g. bolt://localhost:7687. The +s schemes use TLS", stringSetting(func(c *db.Config) *string { return &c.URI })},
+	{"db-username", "Neo4j username", stringSetting(func(c *db.Config) *string { return &c.Username })},
+	{"db-password", "Neo4j password. Prefer the environment variable", stringSetting(func(c *db.Config) *string { return &c.Password })},
+	{"db-database", "Neo4j database name. The default database of the server if empty", stringSetting(func(c *db.Config) *string { return &c.Database })},
+	{"db-tls-ca-file", "PEM file of the certificate authorities to trust", stringSetting(func(c *db.Config) *string { return &c.TLS.CAFile })},
+	{"db-tls-cert-file", "Client certificate, for mutual TLS", stringSetting(func(c *db.Config) *string { return &c.TLS.CertFile })},
+	{"db-tls-key-file", "Client key, for mutual TLS", stringSetting(func(c *db.Config) *string { return &c.TLS.KeyFile })},
+	{"db-max-connection-pool-size", "Maximum number of connections to the server", intSetting(func(c *db.Config) *int { return &c.MaxConnectionPoolSize })},
+	{"db-connect-timeout", "Timeout of establishing a connection, e.g. 5s", durationSetting(func(c *db.Config) *time.Duration { return &c.ConnectTimeout })},
+	{"db-acquisition-timeout", "Timeout of acquiring a connection from the pool, e.g. 1m", durationSetting(func(c *db.Config) *time.Duration { return &c.AcquisitionTimeout })},
+	{"db-max-transaction-retry-time", "Maximum time spent retrying transient failures, e.g. 30s", durationSetting(func(c *db.Config) *time.Duration { return &c.MaxTransactionRetryTime })},
+}
+
+// AddFlags registers the --config and --profile flags, and a flag for every connection setting.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String(configFlag, "", fmt.Sprintf("Config file. Defaults to %s, or %s if it exists", envName(configFlag), DefaultPath))
+	flags.String(profileFlag, "", fmt.Sprintf("Profile of the config file. Defaults to %s, or the profile set in the file", envName(profileFlag)))
+	for _, s := range settings {
+		flags.String(s.name, "", fmt.Sprintf("%s (env %s)", s.usage, envName(s.name)))
+	}
+}
+
+// Parse reads the settings of the profile from a config file, on top of the defaults.
+// The profile set in the file is used when the profile is empty, and the base settings when neither is set.
+func Parse(data []byte, profile string) (db.Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	f := file{Config: db.DefaultConfig()}
+	if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return db.Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	if profile == "" {
+		profile = f.Profile
+	}
+	if profile == "" {
+		return f.Config, nil
+	}
+
+	node, exists := f.Profiles[profile]
+	if !exists {
+		return db.Config{}, fmt.Errorf("%w: %s", ErrUnknownProfile, profile)
+	}
+
+	// Decoding onto the base settings only replaces those the profile sets
+	config := f.Config
+	if err := node.Decode(&config); err != nil {
+		return db.Config{}, fmt.Errorf("%w: profile %s: %w", ErrInvalidConfig, profile, err)
+	}
+	return config, nil
+}
+
+// Load returns the settings selected by the flags, from the flags, the environment and the config file.
+func Load(flags *pflag.FlagSet) (db.Config, error) {
+	path, required := lookup(flags, configFlag)
+	if path == "" {
+		path = DefaultPath
+	}
+	profile, _ := lookup(flags, profileFlag)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		data, err = nil, nil
+	}
+	if err != nil {
+		return db.Config{}, err
+	}
+
+	config, err := Parse(data, profile)
+	if err != nil {
+		return db.Config{}, err
+	}
+
+	for _, s := range settings {
+		value, found := lookup(flags, s.name)
+		if !found {
+			continue
+		}
+		if err := s.set(&config, value); err != nil {
+			return db.Config{}, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, s.name, err)
+		}
+	}
+
+	return config, nil
+}
+
+// lookup returns the value of the setting from its flag if set, or from its environment variable,
+// and whether it was found in either.
+func lookup(flags *pflag.FlagSet, name string) (string, bool) {
+	if flag := flags.Lookup(name); flag != nil && flag.Changed {
+		return flag.Value.String(), true
+	}
+	return os.LookupEnv(envName(name))
+}
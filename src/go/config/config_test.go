@@ -0,0 +1,134 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/namsnath/otter/config"
+	"github.com/namsnath/otter/db"
+	"github.com/spf13/pflag"
+)
+
+const testConfig = `
+profile: dev
+username: otter
+profiles:
+  dev:
+    uri: bolt://localhost:7687
+    password: dev-password
+  prod:
+    uri: neo4j+s://neo4j.example.com:7687
+    database: otter
+    max_connection_pool_size: 50
+    connect_timeout: 10s
+    tls:
+      ca_file: /etc/otter/ca.pem
+`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		profile  string
+		expected db.Config
+	}{
+		{"empty file", "", "", db.DefaultConfig()},
+		{"profile of the file", testConfig, "", db.Config{
+			URI:      "bolt://localhost:7687",
+			Username: "otter",
+			Password: "dev-password",
+			Database: "neo4j",
+		}},
+		{"profile overriding the base settings", testConfig, "prod", db.Config{
+			URI:                   "neo4j+s://neo4j.example.com:7687",
+			Username:              "otter",
+			Password:              "password",
+			Database:              "otter",
+			TLS:                   db.TLSConfig{CAFile: "/etc/otter/ca.pem"},
+			MaxConnectionPoolSize: 50,
+			ConnectTimeout:        10 * time.Second,
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := config.Parse([]byte(tc.data), tc.profile)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := config.Parse([]byte(testConfig), "staging"); !errors.Is(err, config.ErrUnknownProfile) {
+		t.Errorf("Expected ErrUnknownProfile, got %v", err)
+	}
+	if _, err := config.Parse([]byte("url: bolt://localhost:7687"), ""); !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an unknown field, got %v", err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otter.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("OTTER_CONFIG", path)
+	t.Setenv("OTTER_PROFILE", "prod")
+	t.Setenv("OTTER_DB_PASSWORD", "env-password")
+	t.Setenv("OTTER_DB_DATABASE", "env-database")
+
+	flags := pflag.NewFlagSet("otter", pflag.ContinueOnError)
+	config.AddFlags(flags)
+	if err := flags.Parse([]string{"--db-database", "flag-database", "--db-acquisition-timeout", "1m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := config.Load(flags)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := db.Config{
+		URI:                   "neo4j+s://neo4j.example.com:7687",
+		Username:              "otter",
+		Password:              "env-password",
+		Database:              "flag-database",
+		TLS:                   db.TLSConfig{CAFile: "/etc/otter/ca.pem"},
+		MaxConnectionPoolSize: 50,
+		ConnectTimeout:        10 * time.Second,
+		AcquisitionTimeout:    time.Minute,
+	}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	flags := pflag.NewFlagSet("otter", pflag.ContinueOnError)
+	config.AddFlags(flags)
+
+	// The default config file is optional
+	result, err := config.Load(flags)
+	if err != nil || result != db.DefaultConfig() {
+		t.Errorf("Expected the defaults without a config file, got %+v, %v", result, err)
+	}
+
+	// A config file that is given is not
+	if err := flags.Parse([]string{"--config", "missing.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(flags); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the missing config file to fail, got %v", err)
+	}
+}
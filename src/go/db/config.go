@@ -0,0 +1,100 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/auth"
+	neo4jConfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+)
+
+// Config holds the settings of the connection to Neo4j.
+// Zero pool sizes and timeouts keep the defaults of the driver.
+type Config struct {
+	URI      string    `yaml:"uri"`
+	Username string    `yaml:"username"`
+	Password string    `yaml:"password"`
+	Database string    `yaml:"database"`
+	TLS      TLSConfig `yaml:"tls"`
+
+	MaxConnectionPoolSize int `yaml:"max_connection_pool_size"`
+	// ConnectTimeout bounds establishing a connection to the server.
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	// AcquisitionTimeout bounds waiting for a connection from the pool, including establishing it.
+	AcquisitionTimeout time.Duration `yaml:"acquisition_timeout"`
+	// MaxTransactionRetryTime bounds the retries of queries failing with transient errors.
+	MaxTransactionRetryTime time.Duration `yaml:"max_transaction_retry_time"`
+}
+
+// TLSConfig holds the certificates used with the encrypted URI schemes, `bolt+s` and `neo4j+s`.
+// Whether the connection is encrypted only depends on the scheme.
+type TLSConfig struct {
+	// CAFile is a PEM file of the certificate authorities to trust, instead of the system ones.
+	CAFile string `yaml:"ca_file"`
+	// CertFile and KeyFile are the client certificate and key, for mutual TLS.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// DefaultConfig returns the settings of a local Neo4j server with the default credentials.
+func DefaultConfig() Config {
+	return Config{
+		URI:      "bolt://localhost:7687",
+		Username: "neo4j",
+		Password: "password",
+		Database: "neo4j",
+	}
+}
+
+// driverConfig returns the function applying the settings to the driver configuration.
+func (c Config) driverConfig() (func(*neo4jConfig.Config), error) {
+	var tlsConfig *tls.Config
+	if c.TLS.CAFile != "" {
+		pem, err := os.ReadFile(c.TLS.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.TLS.CAFile)
+		}
+		tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	var clientCertificate auth.ClientCertificateProvider
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		provider, err := auth.NewStaticClientCertificateProvider(auth.ClientCertificate{
+			CertFile: c.TLS.CertFile,
+			KeyFile:  c.TLS.KeyFile,
+		})
+		if err != nil {
+			return nil, err
+		}
+		clientCertificate = provider
+	}
+
+	return func(config *neo4jConfig.Config) {
+		if tlsConfig != nil {
+			config.TlsConfig = tlsConfig
+		}
+		if clientCertificate != nil {
+			config.ClientCertificateProvider = clientCertificate
+		}
+		if c.MaxConnectionPoolSize > 0 {
+			config.MaxConnectionPoolSize = c.MaxConnectionPoolSize
+		}
+		if c.ConnectTimeout > 0 {
+			config.SocketConnectTimeout = c.ConnectTimeout
+		}
+		if c.AcquisitionTimeout > 0 {
+			config.ConnectionAcquisitionTimeout = c.AcquisitionTimeout
+		}
+		if c.MaxTransactionRetryTime > 0 {
+			config.MaxTransactionRetryTime = c.MaxTransactionRetryTime
+		}
+	}, nil
+}
@@ -0,0 +1,30 @@
+package db
+
+// DeleteMode selects what deleting a subject, resource or specifier does with the policies
+// pointing at it and with its children.
+type DeleteMode string
+
+const (
+	// DeleteRefuse fails with an ErrConflict error if policies point at the node or it has children.
+	DeleteRefuse DeleteMode = "refuse"
+	// DeleteCascade deletes the policies pointing at the node. Its children lose it as a parent.
+	DeleteCascade DeleteMode = "cascade"
+	// DeleteReparent deletes the policies pointing at the node, and gives its children its parents.
+	DeleteReparent DeleteMode = "reparent"
+)
+
+var ErrInvalidDeleteMode = NewError(ErrInvalidInput, "invalid delete mode: must be refuse, cascade or reparent")
+
+// DeleteModeFromString parses the mode, defaulting to DeleteRefuse when empty.
+func DeleteModeFromString(s string) (DeleteMode, error) {
+	switch DeleteMode(s) {
+	case "", DeleteRefuse:
+		return DeleteRefuse, nil
+	case DeleteCascade:
+		return DeleteCascade, nil
+	case DeleteReparent:
+		return DeleteReparent, nil
+	default:
+		return "", ErrInvalidDeleteMode
+	}
+}
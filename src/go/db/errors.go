@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Kinds of errors. Every error of the entities, queries and stores wraps one of them when it applies,
+// so callers can tell them apart with errors.Is, e.g. errors.Is(err, db.ErrNotFound).
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrBackendUnavailable = errors.New("backend unavailable")
+)
+
+// Error is an error of a known kind. Use errors.As to get the kind of an error, or errors.Is to check for it.
+type Error struct {
+	// Kind is one of ErrNotFound, ErrConflict, ErrInvalidInput or ErrBackendUnavailable.
+	Kind error
+	Err  error
+}
+
+// NewError returns an error of the kind with the message, to be used as a sentinel.
+func NewError(kind error, message string) error {
+	return &Error{Kind: kind, Err: errors.New(message)}
+}
+
+// Errorf returns an error of the kind with the formatted message, which may wrap another error with %w.
+func Errorf(kind error, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// ErrNotInitialized is returned by every method of the store when no instance has been set up.
+var ErrNotInitialized = NewError(ErrBackendUnavailable, "store instance not initialized: call SetupInstance or SetInstance first")
+
+// ErrNotNeo4J is returned by ExecuteQuery when the instance is not backed by Neo4j.
+var ErrNotNeo4J = NewError(ErrInvalidInput, "ExecuteQuery requires a Neo4J instance")
+
+// neo4jError gives the error of the driver its kind: connectivity, authentication and transient failures
+// leave the backend unavailable, and constraint violations conflict with existing data.
+func neo4jError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var connectivityErr *neo4j.ConnectivityError
+	var limitErr *neo4j.TransactionExecutionLimit
+	var dbErr *neo4j.Neo4jError
+	switch {
+	case errors.As(err, &connectivityErr), errors.As(err, &limitErr):
+		return &Error{Kind: ErrBackendUnavailable, Err: err}
+	case errors.As(err, &dbErr) && dbErr.Code == "Neo.ClientError.Schema.ConstraintValidationFailed":
+		return &Error{Kind: ErrConflict, Err: err}
+	case errors.As(err, &dbErr) && (dbErr.Classification() == "TransientError" || dbErr.IsAuthenticationFailed()):
+		return &Error{Kind: ErrBackendUnavailable, Err: err}
+	}
+	return err
+}
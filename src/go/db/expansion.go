@@ -0,0 +1,82 @@
+package db
+
+import (
+	"slices"
+
+	"github.com/namsnath/otter/utils/hashset"
+)
+
+// resourceExpansions collects, for WhatCanWithoutAllSpecifiers, the values each policy on a
+// resource has for the specifier keys missing from the query, by resource and policy ID.
+type resourceExpansions map[ResourceRecord]map[string]map[string]*hashset.HashSet[string]
+
+// add records the policy on the resource, along with a value of one of its keys unless key is empty.
+func (e resourceExpansions) add(resource ResourceRecord, policyId string, key string, value string) {
+	if _, exists := e[resource]; !exists {
+		e[resource] = map[string]map[string]*hashset.HashSet[string]{}
+	}
+	if _, exists := e[resource][policyId]; !exists {
+		e[resource][policyId] = map[string]*hashset.HashSet[string]{}
+	}
+	if key == "" {
+		return
+	}
+	if _, exists := e[resource][policyId][key]; !exists {
+		e[resource][policyId][key] = hashset.New[string]()
+	}
+	e[resource][policyId][key].Add(value)
+}
+
+// withoutDenied merges the values of the ALLOW policies of each resource, and removes what the DENY
+// policies on it carve out, like Can would for the same query:
+//   - a DENY policy with the wildcard for every key drops the resource,
+//   - a DENY policy restricting a single key drops its values, and the resource once none is left.
+//
+// A DENY policy restricting several keys only denies their combinations, which the values of each
+// key cannot express, so it is left to Can.
+func withoutDenied(allowed resourceExpansions, denied resourceExpansions) map[ResourceRecord]map[string][]string {
+	result := map[ResourceRecord]map[string][]string{}
+	for resource, policies := range allowed {
+		values := map[string]*hashset.HashSet[string]{}
+		for _, expansion := range policies {
+			for key, keyValues := range expansion {
+				if _, exists := values[key]; !exists {
+					values[key] = hashset.New[string]()
+				}
+				values[key] = values[key].Union(keyValues)
+			}
+		}
+
+		dropped := false
+		for _, expansion := range denied[resource] {
+			restricted := []string{}
+			for key, keyValues := range expansion {
+				if !keyValues.Contains("*") {
+					restricted = append(restricted, key)
+				}
+			}
+
+			switch len(restricted) {
+			case 0:
+				dropped = true
+			case 1:
+				if keyValues, exists := values[restricted[0]]; exists {
+					values[restricted[0]] = keyValues.Difference(expansion[restricted[0]])
+					dropped = values[restricted[0]].Len() == 0
+				}
+			}
+			if dropped {
+				break
+			}
+		}
+		if dropped || len(values) == 0 {
+			continue
+		}
+
+		result[resource] = map[string][]string{}
+		for key, keyValues := range values {
+			result[resource][key] = slices.Sorted(keyValues.All())
+		}
+	}
+	return result
+}
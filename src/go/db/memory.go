@@ -0,0 +1,928 @@
+package db
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/namsnath/otter/utils/hashset"
+)
+
+// MemoryStore is a pure-Go, in-memory implementation of Store.
+// It mirrors the semantics of the Cypher queries run by Neo4J, and is meant for
+// unit tests and small embedded deployments.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	subjects         map[string]SubjectRecord
+	subjectParents   map[string][]string
+	resources        map[string]ResourceRecord
+	resourceParents  map[string][]string
+	specifiers       map[SpecifierRecord]struct{}
+	specifierParents map[SpecifierRecord][]SpecifierRecord
+	specifierKeys    map[string]SpecifierKeyRecord
+	actions          *hashset.HashSet[string]
+	actionImplies    map[string][]string
+	policies         map[string]*memoryPolicy
+
+	// version counts the writes, so that InTx can tell whether the store changed under a transaction.
+	version uint64
+}
+
+type memoryPolicy struct {
+	id       string
+	subject  string
+	resource string
+	effect   string
+	edges    []memoryPolicyEdge
+}
+
+// memoryPolicyEdge is the equivalent of the `(policy)-[:<action>]->(specifier)` relationship.
+type memoryPolicyEdge struct {
+	action    string
+	specifier SpecifierRecord
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	store := &MemoryStore{}
+	store.reset()
+	return store
+}
+
+func SetupMemoryInstance() {
+	SetInstance(NewMemoryStore())
+}
+
+func (m *MemoryStore) reset() {
+	m.subjects = map[string]SubjectRecord{}
+	m.subjectParents = map[string][]string{}
+	m.resources = map[string]ResourceRecord{}
+	m.resourceParents = map[string][]string{}
+	m.specifiers = map[SpecifierRecord]struct{}{}
+	m.specifierParents = map[SpecifierRecord][]SpecifierRecord{}
+	m.specifierKeys = map[string]SpecifierKeyRecord{}
+	m.actions = hashset.New[string]()
+	m.actionImplies = map[string][]string{}
+	m.policies = map[string]*memoryPolicy{}
+}
+
+// lockForWrite takes the write lock and records that the contents change.
+func (m *MemoryStore) lockForWrite() {
+	m.mu.Lock()
+	m.version++
+}
+
+func cloneEdges[K comparable, V any](edges map[K][]V) map[K][]V {
+	cloned := make(map[K][]V, len(edges))
+	for k, v := range edges {
+		cloned[k] = slices.Clone(v)
+	}
+	return cloned
+}
+
+// clone returns a copy of the contents, sharing nothing with the store. The caller holds the lock.
+func (m *MemoryStore) clone() *MemoryStore {
+	policies := make(map[string]*memoryPolicy, len(m.policies))
+	for id, policy := range m.policies {
+		cloned := *policy
+		cloned.edges = slices.Clone(policy.edges)
+		policies[id] = &cloned
+	}
+
+	return &MemoryStore{
+		subjects:         maps.Clone(m.subjects),
+		subjectParents:   cloneEdges(m.subjectParents),
+		resources:        maps.Clone(m.resources),
+		resourceParents:  cloneEdges(m.resourceParents),
+		specifiers:       maps.Clone(m.specifiers),
+		specifierParents: cloneEdges(m.specifierParents),
+		specifierKeys:    maps.Clone(m.specifierKeys),
+		actions:          hashset.New[string]().Union(m.actions),
+		actionImplies:    cloneEdges(m.actionImplies),
+		policies:         policies,
+	}
+}
+
+// InTx runs fn against a copy of the contents, which replaces them if fn succeeds. If another
+// write happened in the meantime, fn runs again on the new contents, like Neo4j retries transactions.
+func (m *MemoryStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		m.mu.RLock()
+		tx := m.clone()
+		version := m.version
+		m.mu.RUnlock()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		m.mu.Lock()
+		if m.version == version {
+			m.subjects, m.subjectParents = tx.subjects, tx.subjectParents
+			m.resources, m.resourceParents = tx.resources, tx.resourceParents
+			m.specifiers, m.specifierParents, m.specifierKeys = tx.specifiers, tx.specifierParents, tx.specifierKeys
+			m.actions, m.actionImplies = tx.actions, tx.actionImplies
+			m.policies = tx.policies
+			m.version++
+			m.mu.Unlock()
+			return nil
+		}
+		m.mu.Unlock()
+	}
+}
+
+// reachable returns start and every node reachable from it by following edges.
+func reachable[K comparable](edges map[K][]K, start K) *hashset.HashSet[K] {
+	visited := hashset.InitWith(start)
+	queue := []K{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if !visited.Contains(next) {
+				visited.Add(next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
+
+// invert turns a child -> parents adjacency map into a parent -> children one.
+func invert[K comparable](edges map[K][]K) map[K][]K {
+	inverted := map[K][]K{}
+	for child, parents := range edges {
+		for _, parent := range parents {
+			inverted[parent] = append(inverted[parent], child)
+		}
+	}
+	return inverted
+}
+
+func (m *MemoryStore) subjectAncestors(name string) *hashset.HashSet[string] {
+	if _, exists := m.subjects[name]; !exists {
+		return hashset.New[string]()
+	}
+	return reachable(m.subjectParents, name)
+}
+
+func (m *MemoryStore) resourceAncestors(name string) *hashset.HashSet[string] {
+	if _, exists := m.resources[name]; !exists {
+		return hashset.New[string]()
+	}
+	return reachable(m.resourceParents, name)
+}
+
+func (m *MemoryStore) CreateSubject(ctx context.Context, subject SubjectRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.subjects[subject.Name]; exists {
+		return Errorf(ErrConflict, "subject %s already exists", subject.Name)
+	}
+	m.subjects[subject.Name] = subject
+	return nil
+}
+
+func (m *MemoryStore) CreateSubjectAsChildOf(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.subjects[subject.Name]; exists {
+		return Errorf(ErrConflict, "subject %s already exists", subject.Name)
+	}
+	if existing, exists := m.subjects[parent.Name]; !exists || existing.Type != parent.Type {
+		return Errorf(ErrNotFound, "parent subject %s not found", parent.Name)
+	}
+	m.subjects[subject.Name] = subject
+	m.subjectParents[subject.Name] = append(m.subjectParents[subject.Name], parent.Name)
+	return nil
+}
+
+func (m *MemoryStore) CreateResource(ctx context.Context, resource ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.resources[resource.Name]; exists {
+		return Errorf(ErrConflict, "resource %s already exists", resource.Name)
+	}
+	m.resources[resource.Name] = resource
+	return nil
+}
+
+func (m *MemoryStore) CreateResourceAsChildOf(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.resources[resource.Name]; exists {
+		return Errorf(ErrConflict, "resource %s already exists", resource.Name)
+	}
+	if _, exists := m.resources[parent.Name]; !exists {
+		return Errorf(ErrNotFound, "parent resource %s not found", parent.Name)
+	}
+	m.resources[resource.Name] = resource
+	m.resourceParents[resource.Name] = append(m.resourceParents[resource.Name], parent.Name)
+	return nil
+}
+
+func (m *MemoryStore) CreateSpecifier(ctx context.Context, specifier SpecifierRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.specifiers[specifier]; exists {
+		return Errorf(ErrConflict, "specifier %s=%s already exists", specifier.Key, specifier.Value)
+	}
+	m.specifiers[specifier] = struct{}{}
+	return nil
+}
+
+func (m *MemoryStore) CreateSpecifierAsChildOf(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.specifiers[specifier]; exists {
+		return Errorf(ErrConflict, "specifier %s=%s already exists", specifier.Key, specifier.Value)
+	}
+	if _, exists := m.specifiers[parent]; !exists {
+		return Errorf(ErrNotFound, "parent specifier %s=%s not found", parent.Key, parent.Value)
+	}
+	m.specifiers[specifier] = struct{}{}
+	m.specifierParents[specifier] = append(m.specifierParents[specifier], parent)
+	return nil
+}
+
+func (m *MemoryStore) subjectNode(name string) SubjectNode {
+	node := SubjectNode{Subject: m.subjects[name], Parents: []SubjectRecord{}}
+	for _, parent := range m.subjectParents[name] {
+		node.Parents = append(node.Parents, m.subjects[parent])
+	}
+	slices.SortFunc(node.Parents, func(a, b SubjectRecord) int { return strings.Compare(a.Name, b.Name) })
+	return node
+}
+
+func (m *MemoryStore) resourceNode(name string) ResourceNode {
+	node := ResourceNode{Resource: m.resources[name], Parents: []ResourceRecord{}}
+	for _, parent := range m.resourceParents[name] {
+		node.Parents = append(node.Parents, m.resources[parent])
+	}
+	slices.SortFunc(node.Parents, func(a, b ResourceRecord) int { return strings.Compare(a.Name, b.Name) })
+	return node
+}
+
+func (m *MemoryStore) specifierNode(specifier SpecifierRecord) SpecifierNode {
+	node := SpecifierNode{Specifier: specifier, Parents: slices.Clone(m.specifierParents[specifier])}
+	if node.Parents == nil {
+		node.Parents = []SpecifierRecord{}
+	}
+	slices.SortFunc(node.Parents, compareSpecifiers)
+	return node
+}
+
+func (m *MemoryStore) GetSubjects(ctx context.Context) ([]SubjectNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]SubjectNode, 0, len(m.subjects))
+	for name := range m.subjects {
+		nodes = append(nodes, m.subjectNode(name))
+	}
+	slices.SortFunc(nodes, func(a, b SubjectNode) int { return strings.Compare(a.Subject.Name, b.Subject.Name) })
+	return nodes, nil
+}
+
+func (m *MemoryStore) GetSubject(ctx context.Context, name string) (SubjectNode, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return SubjectNode{}, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.subjects[name]; !exists {
+		return SubjectNode{}, false, nil
+	}
+	return m.subjectNode(name), true, nil
+}
+
+func (m *MemoryStore) GetResources(ctx context.Context) ([]ResourceNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]ResourceNode, 0, len(m.resources))
+	for name := range m.resources {
+		nodes = append(nodes, m.resourceNode(name))
+	}
+	slices.SortFunc(nodes, func(a, b ResourceNode) int { return strings.Compare(a.Resource.Name, b.Resource.Name) })
+	return nodes, nil
+}
+
+func (m *MemoryStore) GetResource(ctx context.Context, name string) (ResourceNode, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return ResourceNode{}, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.resources[name]; !exists {
+		return ResourceNode{}, false, nil
+	}
+	return m.resourceNode(name), true, nil
+}
+
+func (m *MemoryStore) GetSpecifiers(ctx context.Context) ([]SpecifierNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]SpecifierNode, 0, len(m.specifiers))
+	for specifier := range m.specifiers {
+		nodes = append(nodes, m.specifierNode(specifier))
+	}
+	slices.SortFunc(nodes, func(a, b SpecifierNode) int { return compareSpecifiers(a.Specifier, b.Specifier) })
+	return nodes, nil
+}
+
+func (m *MemoryStore) GetSpecifier(ctx context.Context, specifier SpecifierRecord) (SpecifierNode, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return SpecifierNode{}, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.specifiers[specifier]; !exists {
+		return SpecifierNode{}, false, nil
+	}
+	return m.specifierNode(specifier), true, nil
+}
+
+func (m *MemoryStore) AddSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if existing, exists := m.subjects[subject.Name]; !exists || existing.Type != subject.Type {
+		return nil
+	}
+	if existing, exists := m.subjects[parent.Name]; !exists || existing.Type != parent.Type {
+		return nil
+	}
+	if !slices.Contains(m.subjectParents[subject.Name], parent.Name) {
+		m.subjectParents[subject.Name] = append(m.subjectParents[subject.Name], parent.Name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RemoveSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.subjectParents[subject.Name] = slices.DeleteFunc(m.subjectParents[subject.Name], func(p string) bool { return p == parent.Name })
+	if len(m.subjectParents[subject.Name]) == 0 {
+		delete(m.subjectParents, subject.Name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) AddResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.resources[resource.Name]; !exists {
+		return nil
+	}
+	if _, exists := m.resources[parent.Name]; !exists {
+		return nil
+	}
+	if !slices.Contains(m.resourceParents[resource.Name], parent.Name) {
+		m.resourceParents[resource.Name] = append(m.resourceParents[resource.Name], parent.Name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RemoveResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.resourceParents[resource.Name] = slices.DeleteFunc(m.resourceParents[resource.Name], func(p string) bool { return p == parent.Name })
+	if len(m.resourceParents[resource.Name]) == 0 {
+		delete(m.resourceParents, resource.Name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) AddSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.specifiers[specifier]; !exists {
+		return nil
+	}
+	if _, exists := m.specifiers[parent]; !exists {
+		return nil
+	}
+	if !slices.Contains(m.specifierParents[specifier], parent) {
+		m.specifierParents[specifier] = append(m.specifierParents[specifier], parent)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RemoveSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.specifierParents[specifier] = slices.DeleteFunc(m.specifierParents[specifier], func(p SpecifierRecord) bool { return p == parent })
+	if len(m.specifierParents[specifier]) == 0 {
+		delete(m.specifierParents, specifier)
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteSubject(ctx context.Context, subject SubjectRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if existing, exists := m.subjects[subject.Name]; !exists || existing.Type != subject.Type {
+		return nil
+	}
+
+	delete(m.subjects, subject.Name)
+	delete(m.subjectParents, subject.Name)
+	for child, parents := range m.subjectParents {
+		m.subjectParents[child] = slices.DeleteFunc(parents, func(p string) bool { return p == subject.Name })
+	}
+	// Policies held by the subject cannot be reached anymore
+	for id, policy := range m.policies {
+		if policy.subject == subject.Name {
+			delete(m.policies, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteResource(ctx context.Context, resource ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	delete(m.resources, resource.Name)
+	delete(m.resourceParents, resource.Name)
+	for child, parents := range m.resourceParents {
+		m.resourceParents[child] = slices.DeleteFunc(parents, func(p string) bool { return p == resource.Name })
+	}
+	// Policies held by the resource cannot be reached anymore
+	for id, policy := range m.policies {
+		if policy.resource == resource.Name {
+			delete(m.policies, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteSpecifier(ctx context.Context, specifier SpecifierRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	delete(m.specifiers, specifier)
+	delete(m.specifierParents, specifier)
+	for child, parents := range m.specifierParents {
+		m.specifierParents[child] = slices.DeleteFunc(parents, func(p SpecifierRecord) bool { return p == specifier })
+	}
+	// Policies pointing at the specifier would grant something else without it
+	for id, policy := range m.policies {
+		if slices.ContainsFunc(policy.edges, func(edge memoryPolicyEdge) bool { return edge.specifier == specifier }) {
+			delete(m.policies, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateSpecifierKey(ctx context.Context, key SpecifierKeyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.specifierKeys[key.Name]; exists {
+		return Errorf(ErrConflict, "specifier key %s already exists", key.Name)
+	}
+	key.Values = slices.Clone(key.Values)
+	m.specifierKeys[key.Name] = key
+	return nil
+}
+
+func (m *MemoryStore) GetSpecifierKeys(ctx context.Context) ([]SpecifierKeyRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]SpecifierKeyRecord, 0, len(m.specifierKeys))
+	for _, name := range slices.Sorted(maps.Keys(m.specifierKeys)) {
+		key := m.specifierKeys[name]
+		key.Values = append([]string{}, key.Values...)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *MemoryStore) DeleteSpecifierKey(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	delete(m.specifierKeys, name)
+	return nil
+}
+
+func (m *MemoryStore) CreateAction(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.actions.Add(name)
+	return nil
+}
+
+func (m *MemoryStore) GetActions(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Sorted(m.actions.All()), nil
+}
+
+func (m *MemoryStore) DeleteAction(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.actions.Delete(name)
+	delete(m.actionImplies, name)
+	for action, implied := range m.actionImplies {
+		m.actionImplies[action] = slices.DeleteFunc(implied, func(i string) bool { return i == name })
+	}
+	return nil
+}
+
+func (m *MemoryStore) AddActionImplication(ctx context.Context, action string, implied string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.actions.Add(action)
+	m.actions.Add(implied)
+	if !slices.Contains(m.actionImplies[action], implied) {
+		m.actionImplies[action] = append(m.actionImplies[action], implied)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RemoveActionImplication(ctx context.Context, action string, implied string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.actionImplies[action] = slices.DeleteFunc(m.actionImplies[action], func(i string) bool { return i == implied })
+	if len(m.actionImplies[action]) == 0 {
+		delete(m.actionImplies, action)
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetActionImplications(ctx context.Context) (map[string][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	implications := map[string][]string{}
+	for action, implied := range m.actionImplies {
+		implications[action] = slices.Sorted(slices.Values(implied))
+	}
+	return implications, nil
+}
+
+// actionsImplying returns the action and every action implying it, directly or transitively.
+func (m *MemoryStore) actionsImplying(action string) *hashset.HashSet[string] {
+	return reachable(invert(m.actionImplies), action)
+}
+
+// normalizeSpecifiers fills every specifier key known to the store, but missing
+// from the input, with the `*` wildcard.
+func (m *MemoryStore) normalizeSpecifiers(specifiers map[string]string) map[string]string {
+	normalized := map[string]string{}
+	for specifier := range m.specifiers {
+		if specifier.Key != "*" {
+			normalized[specifier.Key] = "*"
+		}
+	}
+	for k, v := range specifiers {
+		normalized[k] = v
+	}
+	return normalized
+}
+
+func (m *MemoryStore) CreatePolicy(ctx context.Context, policy PolicyRecord) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	if _, exists := m.subjects[policy.Subject.Name]; !exists {
+		return "", nil
+	}
+	if _, exists := m.resources[policy.Resource.Name]; !exists {
+		return "", nil
+	}
+
+	specifierMap := map[string]string{}
+	for _, specifier := range policy.Specifiers {
+		specifierMap[specifier.Key] = specifier.Value
+	}
+
+	newPolicy := &memoryPolicy{
+		id:       policy.Id,
+		subject:  policy.Subject.Name,
+		resource: policy.Resource.Name,
+		effect:   EffectAllow,
+	}
+	if newPolicy.id == "" {
+		newPolicy.id = uuid.NewString()
+	} else if _, exists := m.policies[newPolicy.id]; exists {
+		return "", Errorf(ErrConflict, "policy %s already exists", newPolicy.id)
+	}
+	if policy.Effect != "" {
+		newPolicy.effect = policy.Effect
+	}
+	for k, v := range m.normalizeSpecifiers(specifierMap) {
+		specifier := SpecifierRecord{Key: k, Value: v}
+		if _, exists := m.specifiers[specifier]; !exists {
+			return "", nil
+		}
+		newPolicy.edges = append(newPolicy.edges, memoryPolicyEdge{action: policy.Action, specifier: specifier})
+	}
+
+	if len(newPolicy.edges) == 0 {
+		return "", nil
+	}
+
+	m.policies[newPolicy.id] = newPolicy
+	return newPolicy.id, nil
+}
+
+// policyRecords groups the edges of the policy by action, keeping only the edges accepted by keep.
+func (m *MemoryStore) policyRecords(policy *memoryPolicy, keep func(memoryPolicyEdge) bool) []PolicyRecord {
+	byAction := map[string][]SpecifierRecord{}
+	actions := []string{}
+	for _, edge := range policy.edges {
+		if !keep(edge) {
+			continue
+		}
+		if _, exists := byAction[edge.action]; !exists {
+			actions = append(actions, edge.action)
+		}
+		byAction[edge.action] = append(byAction[edge.action], edge.specifier)
+	}
+
+	records := make([]PolicyRecord, 0, len(actions))
+	for _, action := range actions {
+		specifiers := byAction[action]
+		slices.SortFunc(specifiers, compareSpecifiers)
+		records = append(records, PolicyRecord{
+			Id:         policy.id,
+			Subject:    m.subjects[policy.subject],
+			Resource:   m.resources[policy.resource],
+			Action:     action,
+			Effect:     policy.effect,
+			Specifiers: specifiers,
+		})
+	}
+	return records
+}
+
+func compareSpecifiers(a, b SpecifierRecord) int {
+	if c := strings.Compare(a.Key, b.Key); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Value, b.Value)
+}
+
+func (m *MemoryStore) sortedPolicies() []*memoryPolicy {
+	policies := make([]*memoryPolicy, 0, len(m.policies))
+	for _, policy := range m.policies {
+		policies = append(policies, policy)
+	}
+	slices.SortFunc(policies, func(a, b *memoryPolicy) int {
+		return strings.Compare(a.id, b.id)
+	})
+	return policies
+}
+
+func (m *MemoryStore) GetPolicies(ctx context.Context, filter PolicyFilter) ([]PolicyRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var normalized map[string]string
+	if filter.Specifiers != nil {
+		normalized = m.normalizeSpecifiers(filter.Specifiers)
+	}
+
+	records := []PolicyRecord{}
+	for _, policy := range m.sortedPolicies() {
+		if filter.SubjectName != "" && policy.subject != filter.SubjectName {
+			continue
+		}
+		if filter.ResourceName != "" && policy.resource != filter.ResourceName {
+			continue
+		}
+		if filter.Effect != "" && policy.effect != filter.Effect {
+			continue
+		}
+
+		candidates := m.policyRecords(policy, func(edge memoryPolicyEdge) bool {
+			if filter.Action != "" && edge.action != filter.Action {
+				return false
+			}
+			return normalized == nil || normalized[edge.specifier.Key] == edge.specifier.Value
+		})
+
+		for _, candidate := range candidates {
+			// Every key of the normalized map must be matched exactly
+			if normalized != nil && (len(normalized) == 0 || len(candidate.Specifiers) != len(normalized)) {
+				continue
+			}
+			records = append(records, candidate)
+		}
+	}
+
+	return records, nil
+}
+
+func (m *MemoryStore) GetPolicyById(ctx context.Context, id string) (PolicyRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return PolicyRecord{}, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policy, exists := m.policies[id]
+	if !exists {
+		return PolicyRecord{}, false, nil
+	}
+
+	records := m.policyRecords(policy, func(memoryPolicyEdge) bool { return true })
+	if len(records) == 0 {
+		return PolicyRecord{}, false, nil
+	}
+	return records[0], true, nil
+}
+
+func (m *MemoryStore) DeletePolicy(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	delete(m.policies, id)
+	return nil
+}
+
+func (m *MemoryStore) SetupIndexes(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) DeleteEverything(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lockForWrite()
+	defer m.mu.Unlock()
+
+	m.reset()
+	return nil
+}
+
+func (m *MemoryStore) Close() error {
+	if instance == m {
+		instance = nil
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// shortestPath returns the nodes on the shortest path from start to target following edges,
+// both ends included. It returns nil if the target is not reachable.
+func shortestPath[K comparable](edges map[K][]K, start K, target K) []K {
+	previous := map[K]K{}
+	visited := map[K]bool{start: true}
+	queue := []K{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current == target {
+			path := []K{current}
+			for current != start {
+				current = previous[current]
+				path = append(path, current)
+			}
+			slices.Reverse(path)
+			return path
+		}
+
+		for _, next := range edges[current] {
+			if !visited[next] {
+				visited[next] = true
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) ExplainCan(ctx context.Context, q AccessQuery) ([]PolicyMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	normalized := m.normalizeSpecifiers(q.Specifiers)
+	subjects := m.subjectAncestors(q.Subject.Name)
+	resources := m.resourceAncestors(q.Resource.Name)
+	actions := m.actionsImplying(q.Action)
+
+	matches := []PolicyMatch{}
+	for _, policy := range m.sortedPolicies() {
+		if !subjects.Contains(policy.subject) || !resources.Contains(policy.resource) {
+			continue
+		}
+
+		for _, record := range m.policyRecords(policy, func(edge memoryPolicyEdge) bool { return actions.Contains(edge.action) }) {
+			match := PolicyMatch{
+				PolicyId:        policy.id,
+				Effect:          policy.effect,
+				Action:          record.Action,
+				SubjectChain:    []SubjectRecord{},
+				ResourceChain:   []ResourceRecord{},
+				Specifiers:      map[string]SpecifierRecord{},
+				InputSpecifiers: normalized,
+			}
+
+			for _, name := range shortestPath(m.subjectParents, q.Subject.Name, policy.subject) {
+				match.SubjectChain = append(match.SubjectChain, m.subjects[name])
+			}
+			for _, name := range shortestPath(m.resourceParents, q.Resource.Name, policy.resource) {
+				match.ResourceChain = append(match.ResourceChain, m.resources[name])
+			}
+
+			for k, v := range normalized {
+				ancestors := m.inputAncestors(SpecifierRecord{Key: k, Value: v}, q.Constraints)
+				if ancestors == nil {
+					continue
+				}
+
+				for _, specifier := range record.Specifiers {
+					if ancestors.Contains(specifier) {
+						match.Specifiers[k] = specifier
+						break
+					}
+				}
+			}
+
+			matches = append(matches, match)
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b PolicyMatch) int {
+		if c := strings.Compare(a.PolicyId, b.PolicyId); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Action, b.Action)
+	})
+	return matches, nil
+}
@@ -0,0 +1,363 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/utils/hashset"
+)
+
+// inputAncestors returns the specifiers a policy may point at to match the input specifier: its
+// ancestors, and those of the constraints of the key the input satisfies. It returns nil if none exists.
+func (m *MemoryStore) inputAncestors(input SpecifierRecord, constraints map[string][]string) *hashset.HashSet[SpecifierRecord] {
+	var ancestors *hashset.HashSet[SpecifierRecord]
+	for _, value := range append([]string{input.Value}, constraints[input.Key]...) {
+		specifier := SpecifierRecord{Key: input.Key, Value: value}
+		if _, exists := m.specifiers[specifier]; !exists {
+			continue
+		}
+		if ancestors == nil {
+			ancestors = hashset.New[SpecifierRecord]()
+		}
+		ancestors = ancestors.Union(reachable(m.specifierParents, specifier))
+	}
+	return ancestors
+}
+
+// matchesSpecifiers reports whether, for every key of the specifier map, the policy has
+// an edge of one of the actions pointing at the input specifier, one of the constraints it
+// satisfies, or one of their ancestors.
+func (m *MemoryStore) matchesSpecifiers(policy *memoryPolicy, actions *hashset.HashSet[string], specifiers map[string]string, constraints map[string][]string) bool {
+	if len(specifiers) == 0 {
+		return false
+	}
+
+	for k, v := range specifiers {
+		ancestors := m.inputAncestors(SpecifierRecord{Key: k, Value: v}, constraints)
+		if ancestors == nil {
+			return false
+		}
+
+		matched := slices.ContainsFunc(policy.edges, func(edge memoryPolicyEdge) bool {
+			return actions.Contains(edge.action) && ancestors.Contains(edge.specifier)
+		})
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// byEffect splits the policies into the ALLOW and DENY ones.
+func byEffect(policies []*memoryPolicy) (allow []*memoryPolicy, deny []*memoryPolicy) {
+	for _, policy := range policies {
+		if policy.effect == EffectDeny {
+			deny = append(deny, policy)
+		} else {
+			allow = append(allow, policy)
+		}
+	}
+	return allow, deny
+}
+
+// holders returns the subjects holding the policies.
+func holders(policies []*memoryPolicy) *hashset.HashSet[string] {
+	subjects := hashset.New[string]()
+	for _, policy := range policies {
+		subjects.Add(policy.subject)
+	}
+	return subjects
+}
+
+// matchingPolicies returns the policies granting the action, or an action implying it, with the
+// given specifiers and constraints, held by one of the subjects and one of the resources. A nil set
+// matches any holder.
+func (m *MemoryStore) matchingPolicies(action string, specifiers map[string]string, constraints map[string][]string, subjects, resources *hashset.HashSet[string]) []*memoryPolicy {
+	actions := m.actionsImplying(action)
+	policies := []*memoryPolicy{}
+	for _, policy := range m.sortedPolicies() {
+		if subjects != nil && !subjects.Contains(policy.subject) {
+			continue
+		}
+		if resources != nil && !resources.Contains(policy.resource) {
+			continue
+		}
+		if m.matchesSpecifiers(policy, actions, specifiers, constraints) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+func (m *MemoryStore) Can(ctx context.Context, q AccessQuery) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.can(q), nil
+}
+
+func (m *MemoryStore) CanBatch(ctx context.Context, qs []AccessQuery) ([]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	answers := make([]bool, 0, len(qs))
+	for _, q := range qs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		answers = append(answers, m.can(q))
+	}
+	return answers, nil
+}
+
+func (m *MemoryStore) can(q AccessQuery) bool {
+	policies := m.matchingPolicies(
+		q.Action,
+		m.normalizeSpecifiers(q.Specifiers),
+		q.Constraints,
+		m.subjectAncestors(q.Subject.Name),
+		m.resourceAncestors(q.Resource.Name),
+	)
+
+	// Any matching DENY policy overrides the ALLOW policies
+	allow, deny := byEffect(policies)
+	return len(allow) > 0 && len(deny) == 0
+}
+
+func (m *MemoryStore) WhoCan(ctx context.Context, q AccessQuery) ([]SubjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policies := m.matchingPolicies(q.Action, m.normalizeSpecifiers(q.Specifiers), q.Constraints, nil, m.resourceAncestors(q.Resource.Name))
+	allow, deny := byEffect(policies)
+	allowHolders, denyHolders := holders(allow), holders(deny)
+
+	subjects := []SubjectRecord{}
+	for name, subject := range m.subjects {
+		if subject.Type != q.SubjectType {
+			continue
+		}
+		// Subjects holding any matching DENY policy are dropped
+		ancestors := m.subjectAncestors(name)
+		if ancestors.Intersection(allowHolders).Len() > 0 && ancestors.Intersection(denyHolders).Len() == 0 {
+			subjects = append(subjects, subject)
+		}
+	}
+
+	slices.SortFunc(subjects, func(a, b SubjectRecord) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return subjects, nil
+}
+
+// resourcesUnder returns the resources below the parent that inherit one of the policies.
+func (m *MemoryStore) resourcesUnder(parent string, policies []*memoryPolicy) []string {
+	holders := hashset.New[string]()
+	for _, policy := range policies {
+		holders.Add(policy.resource)
+	}
+
+	resources := []string{}
+	for name := range m.resources {
+		ancestors := m.resourceAncestors(name)
+		if ancestors.Contains(parent) && ancestors.Intersection(holders).Len() > 0 {
+			resources = append(resources, name)
+		}
+	}
+
+	slices.Sort(resources)
+	return resources
+}
+
+func (m *MemoryStore) WhatCan(ctx context.Context, q AccessQuery) ([]ResourceRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policies := m.matchingPolicies(q.Action, m.normalizeSpecifiers(q.Specifiers), q.Constraints, m.subjectAncestors(q.Subject.Name), nil)
+	allow, deny := byEffect(policies)
+
+	// Resources inheriting any matching DENY policy are dropped
+	denied := hashset.InitWith(m.resourcesUnder(q.Resource.Name, deny)...)
+	resources := []ResourceRecord{}
+	for _, name := range m.resourcesUnder(q.Resource.Name, allow) {
+		if !denied.Contains(name) {
+			resources = append(resources, m.resources[name])
+		}
+	}
+	return resources, nil
+}
+
+// StreamWhoCan yields the answers of WhoCan once it has them all, so that yield runs without the lock.
+func (m *MemoryStore) StreamWhoCan(ctx context.Context, q AccessQuery, yield func(SubjectRecord) error) error {
+	subjects, err := m.WhoCan(ctx, q)
+	if err != nil {
+		return err
+	}
+	for _, subject := range subjects {
+		if err := yield(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamWhatCan yields the answers of WhatCan once it has them all, so that yield runs without the lock.
+func (m *MemoryStore) StreamWhatCan(ctx context.Context, q AccessQuery, yield func(ResourceRecord) error) error {
+	resources, err := m.WhatCan(ctx, q)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if err := yield(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) WhatCanWithoutAllSpecifiers(ctx context.Context, q AccessQuery) (map[ResourceRecord]map[string][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	specifierChildren := invert(m.specifierParents)
+	subjects := m.subjectAncestors(q.Subject.Name)
+	actions := m.actionsImplying(q.Action)
+
+	allowed, denied := resourceExpansions{}, resourceExpansions{}
+	for _, policy := range m.matchingPolicies(q.Action, q.Specifiers, q.Constraints, subjects, nil) {
+		expansions := allowed
+		if policy.effect == EffectDeny {
+			expansions = denied
+		}
+
+		for _, name := range m.resourcesUnder(q.Resource.Name, []*memoryPolicy{policy}) {
+			resource := m.resources[name]
+			expansions.add(resource, policy.id, "", "")
+
+			for _, edge := range policy.edges {
+				if !actions.Contains(edge.action) || edge.specifier.Key == "*" {
+					continue
+				}
+				for other := range reachable(specifierChildren, edge.specifier).All() {
+					if _, provided := q.Specifiers[other.Key]; !provided {
+						expansions.add(resource, policy.id, other.Key, other.Value)
+					}
+				}
+			}
+		}
+	}
+
+	return withoutDenied(allowed, denied), nil
+}
+
+func (m *MemoryStore) HowCan(ctx context.Context, q AccessQuery) (map[string]PolicyExpansion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if subject, exists := m.subjects[q.Subject.Name]; !exists || subject.Type != q.Subject.Type {
+		return map[string]PolicyExpansion{}, nil
+	}
+
+	specifierChildren := invert(m.specifierParents)
+	subjects := m.subjectAncestors(q.Subject.Name)
+	resources := m.resourceAncestors(q.Resource.Name)
+	actions := m.actionsImplying(q.Action)
+
+	policyMap := map[string]PolicyExpansion{}
+	for _, policy := range m.sortedPolicies() {
+		// Expanding the specifiers of every policy is the slow part, so stop as soon as the context is done.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !subjects.Contains(policy.subject) || !resources.Contains(policy.resource) {
+			continue
+		}
+
+		rootSpecs := []SpecifierRecord{}
+		for _, edge := range policy.edges {
+			if actions.Contains(edge.action) {
+				rootSpecs = append(rootSpecs, edge.specifier)
+			}
+		}
+		if len(rootSpecs) == 0 {
+			continue
+		}
+
+		// The policy is only valid if it covers ALL provided keys
+		covers := true
+		for inputKey, inputValue := range q.Specifiers {
+			covers = slices.ContainsFunc(rootSpecs, func(rootSpec SpecifierRecord) bool {
+				if rootSpec.Key != inputKey {
+					return false
+				}
+				for descendant := range reachable(specifierChildren, rootSpec).All() {
+					if descendant.Value == inputValue || slices.Contains(q.Constraints[inputKey], descendant.Value) {
+						return true
+					}
+				}
+				return false
+			})
+			if !covers {
+				break
+			}
+		}
+		if !covers {
+			continue
+		}
+
+		expanded := map[string]*hashset.HashSet[string]{}
+		for _, rootSpec := range rootSpecs {
+			if _, provided := q.Specifiers[rootSpec.Key]; provided {
+				continue
+			}
+			if _, exists := expanded[rootSpec.Key]; !exists {
+				expanded[rootSpec.Key] = hashset.New[string]()
+			}
+
+			// A wildcard root only yields itself, a specific one yields all its children
+			if rootSpec.Value == "*" {
+				expanded[rootSpec.Key].Add(rootSpec.Value)
+				continue
+			}
+			for child := range reachable(specifierChildren, rootSpec).All() {
+				expanded[rootSpec.Key].Add(child.Value)
+			}
+		}
+
+		if len(expanded) == 0 {
+			continue
+		}
+		policyMap[policy.id] = PolicyExpansion{Effect: policy.effect, Specifiers: map[string][]string{}}
+		for key, values := range expanded {
+			policyMap[policy.id].Specifiers[key] = slices.Sorted(values.All())
+		}
+	}
+
+	return policyMap, nil
+}
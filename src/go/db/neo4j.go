@@ -1,69 +1,134 @@
 package db
 
 import (
+	"cmp"
 	"context"
 	"fmt"
-	"sync"
+	"os"
+	"testing"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/testcontainers/testcontainers-go"
 	tcNeo4j "github.com/testcontainers/testcontainers-go/modules/neo4j"
 )
 
-var (
-	instance *Neo4J
-	once     sync.Once
-)
-
 type Neo4J struct {
-	ctx    context.Context
-	driver neo4j.DriverWithContext
+	driver   neo4j.DriverWithContext
+	database string
+	// tx is set on the store passed to the function of InTx, whose queries all run in it.
+	tx neo4j.ManagedTransaction
 }
 
-func SetupInstance(dbUri, dbUser, dbPassword string) {
-	once.Do(func() {
-		ctx := context.Background()
+var _ Store = (*Neo4J)(nil)
 
-		driver, err := neo4j.NewDriverWithContext(
-			dbUri,
-			neo4j.BasicAuth(dbUser, dbPassword, ""))
+// NewNeo4J connects to Neo4j with the config. The context bounds the connectivity check.
+func NewNeo4J(ctx context.Context, config Config) (*Neo4J, error) {
+	driverConfig, err := config.driverConfig()
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidInput, Err: err}
+	}
 
-		if err != nil {
-			panic(err)
-		}
+	driver, err := neo4j.NewDriverWithContext(
+		config.URI,
+		neo4j.BasicAuth(config.Username, config.Password, ""),
+		driverConfig)
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidInput, Err: err}
+	}
 
-		err = driver.VerifyConnectivity(ctx)
-		if err != nil {
-			panic(err)
+	err = driver.VerifyConnectivity(ctx)
+	if err != nil {
+		driver.Close(context.Background())
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
 		}
+		return nil, &Error{Kind: ErrBackendUnavailable, Err: err}
+	}
 
-		instance = &Neo4J{
-			ctx:    ctx,
-			driver: driver,
-		}
-	})
+	return &Neo4J{
+		driver:   driver,
+		database: config.Database,
+	}, nil
+}
+
+// SetupInstance connects to Neo4j with the config, and makes it the store used by the entities and queries.
+func SetupInstance(ctx context.Context, config Config) error {
+	store, err := NewNeo4J(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	SetInstance(store)
+	return nil
 }
 
-func GetInstance() *Neo4J {
-	if instance == nil {
-		panic("Neo4J instance not initialized. Call SetupInstance first.")
+func (s *Neo4J) InTx(ctx context.Context, fn func(tx Store) error) error {
+	if s.tx != nil {
+		return fn(s)
 	}
 
-	return instance
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: s.database})
+	defer session.Close(context.Background())
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, fn(&Neo4J{driver: s.driver, database: s.database, tx: tx})
+	})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return neo4jError(err)
+	}
+	return nil
 }
 
 func (s *Neo4J) Close() error {
-	err := s.driver.Close(s.ctx)
+	err := s.driver.Close(context.Background())
 	if err != nil {
 		return err
 	}
-	instance = nil
+	if instance == s {
+		instance = nil
+	}
 	return nil
 }
 
-func TestContainer() (context.Context, *tcNeo4j.Neo4jContainer) {
+// TestContainer makes Neo4j the store used by the entities and queries for the test. It connects to
+// the server at OTTER_TEST_NEO4J_URI when set, such as a CI service shared by the tests, which must
+// then run one package at a time. Otherwise it starts one in a container, terminated when the test ends.
+// Without a container provider the test is skipped, unless OTTER_TEST_NEO4J_REQUIRED is set.
+func TestContainer(t *testing.T) {
+	t.Helper()
+
 	ctx := context.Background()
 	testPassword := "password"
 
+	if uri := os.Getenv("OTTER_TEST_NEO4J_URI"); uri != "" {
+		config := DefaultConfig()
+		config.URI = uri
+		config.Password = cmp.Or(os.Getenv("OTTER_TEST_NEO4J_PASSWORD"), testPassword)
+		store, err := NewNeo4J(ctx, config)
+		if err != nil {
+			t.Fatalf("connecting to Neo4j at %s: %v", uri, err)
+		}
+		SetInstance(store)
+		t.Cleanup(func() {
+			store.Close()
+		})
+		return
+	}
+
+	if os.Getenv("OTTER_TEST_NEO4J_REQUIRED") == "" {
+		testcontainers.SkipIfProviderIsNotHealthy(t)
+	} else {
+		// Testcontainers panics without a provider
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("starting Neo4j container: %v", r)
+			}
+		}()
+	}
+
 	container, err := tcNeo4j.Run(
 		ctx,
 		"neo4j:latest",
@@ -71,22 +136,26 @@ func TestContainer() (context.Context, *tcNeo4j.Neo4jContainer) {
 		tcNeo4j.WithAdminPassword(testPassword),
 	)
 	if err != nil {
-		panic(err)
+		t.Fatalf("starting Neo4j container: %v", err)
 	}
-
-	container.Start(ctx)
+	t.Cleanup(func() {
+		container.Terminate(ctx)
+	})
 
 	host, err := container.Host(ctx)
 	if err != nil {
-		panic(err)
+		t.Fatalf("getting Neo4j container host: %v", err)
 	}
 
 	mappedPort, err := container.MappedPort(ctx, "7687/tcp") // Default Bolt port
 	if err != nil {
-		panic(err)
+		t.Fatalf("getting Neo4j container port: %v", err)
 	}
 
-	SetupInstance(fmt.Sprintf("bolt://%s:%d", host, mappedPort.Int()), "neo4j", testPassword)
-
-	return ctx, container
+	config := DefaultConfig()
+	config.URI = fmt.Sprintf("bolt://%s:%d", host, mappedPort.Int())
+	config.Password = testPassword
+	if err := SetupInstance(ctx, config); err != nil {
+		t.Fatalf("connecting to Neo4j container: %v", err)
+	}
 }
@@ -0,0 +1,334 @@
+package db
+
+import "context"
+
+func (s *Neo4J) CreateSubject(ctx context.Context, subject SubjectRecord) error {
+	_, err := s.executeQuery(ctx, `
+		CREATE (s:Subject {name: $name, type: $type})
+		`,
+		map[string]any{
+			"name": subject.Name,
+			"type": subject.Type,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) CreateSubjectAsChildOf(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	// The parent is matched first, so nothing is created without it
+	result, err := s.executeQuery(ctx, `
+		MATCH (p:Subject {name: $parentName, type: $parentType})
+		CREATE (s:Subject {name: $name, type: $type})-[:CHILD_OF]->(p)
+		RETURN s.name AS name
+		`,
+		map[string]any{
+			"name":       subject.Name,
+			"type":       subject.Type,
+			"parentName": parent.Name,
+			"parentType": parent.Type,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(result.Records) == 0 {
+		return Errorf(ErrNotFound, "parent subject %s not found", parent.Name)
+	}
+	return nil
+}
+
+func (s *Neo4J) CreateResource(ctx context.Context, resource ResourceRecord) error {
+	_, err := s.executeQuery(ctx, `
+		CREATE (r:Resource {name: $name})
+		`,
+		map[string]any{
+			"name": resource.Name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) CreateResourceAsChildOf(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	result, err := s.executeQuery(ctx, `
+		MATCH (p:Resource {name: $parentName})
+		CREATE (r:Resource {name: $name})-[:CHILD_OF]->(p)
+		RETURN r.name AS name
+		`,
+		map[string]any{
+			"name":       resource.Name,
+			"parentName": parent.Name,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(result.Records) == 0 {
+		return Errorf(ErrNotFound, "parent resource %s not found", parent.Name)
+	}
+	return nil
+}
+
+func (s *Neo4J) CreateSpecifier(ctx context.Context, specifier SpecifierRecord) error {
+	_, err := s.executeQuery(ctx,
+		"CREATE (r:Specifier {key: $key, value: $value})",
+		map[string]any{
+			"key":   specifier.Key,
+			"value": specifier.Value,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) CreateSpecifierAsChildOf(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	result, err := s.executeQuery(ctx, `
+		MATCH (p:Specifier {key: $parentKey, value: $parentValue})
+		CREATE (s:Specifier {key: $key, value: $value})-[:CHILD_OF]->(p)
+		RETURN s.key AS key
+		`,
+		map[string]any{
+			"key":         specifier.Key,
+			"value":       specifier.Value,
+			"parentKey":   parent.Key,
+			"parentValue": parent.Value,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(result.Records) == 0 {
+		return Errorf(ErrNotFound, "parent specifier %s=%s not found", parent.Key, parent.Value)
+	}
+	return nil
+}
+
+func (s *Neo4J) CreateSpecifierKey(ctx context.Context, key SpecifierKeyRecord) error {
+	_, err := s.executeQuery(ctx, `
+		CREATE (k:SpecifierKey {name: $name, type: $type, description: $description, values: $values, default: $default, required: $required})
+		`,
+		map[string]any{
+			"name":        key.Name,
+			"type":        key.Type,
+			"description": key.Description,
+			"values":      key.Values,
+			"default":     key.Default,
+			"required":    key.Required,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) GetSpecifierKeys(ctx context.Context) ([]SpecifierKeyRecord, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (k:SpecifierKey)
+		RETURN k.name AS name, k.type AS type, k.description AS description, k.values AS values, k.default AS default, k.required AS required
+		ORDER BY name
+		`,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]SpecifierKeyRecord, 0, len(result.Records))
+	for _, record := range result.Records {
+		key := SpecifierKeyRecord{Values: []string{}}
+		nameVal, _ := record.Get("name")
+		key.Name, _ = nameVal.(string)
+		typeVal, _ := record.Get("type")
+		key.Type, _ = typeVal.(string)
+		descriptionVal, _ := record.Get("description")
+		key.Description, _ = descriptionVal.(string)
+		valuesVal, _ := record.Get("values")
+		if values, ok := valuesVal.([]any); ok {
+			for _, value := range values {
+				if v, ok := value.(string); ok {
+					key.Values = append(key.Values, v)
+				}
+			}
+		}
+		defaultVal, _ := record.Get("default")
+		key.Default, _ = defaultVal.(string)
+		requiredVal, _ := record.Get("required")
+		key.Required, _ = requiredVal.(bool)
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *Neo4J) DeleteSpecifierKey(ctx context.Context, name string) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (k:SpecifierKey {name: $name})
+		DELETE k
+		`,
+		map[string]any{
+			"name": name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) CreateAction(ctx context.Context, name string) error {
+	_, err := s.executeQuery(ctx, `
+		MERGE (a:Action {name: $name})
+		`,
+		map[string]any{
+			"name": name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) GetActions(ctx context.Context) ([]string, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (a:Action)
+		RETURN a.name AS name
+		ORDER BY name
+		`,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]string, 0, len(result.Records))
+	for _, record := range result.Records {
+		nameVal, _ := record.Get("name")
+		if name, ok := nameVal.(string); ok {
+			actions = append(actions, name)
+		}
+	}
+
+	return actions, nil
+}
+
+func (s *Neo4J) DeleteAction(ctx context.Context, name string) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (a:Action {name: $name})
+		DETACH DELETE a
+		`,
+		map[string]any{
+			"name": name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) AddActionImplication(ctx context.Context, action string, implied string) error {
+	_, err := s.executeQuery(ctx, `
+		MERGE (a:Action {name: $action})
+		MERGE (i:Action {name: $implied})
+		MERGE (a)-[:IMPLIES]->(i)
+		`,
+		map[string]any{
+			"action":  action,
+			"implied": implied,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) RemoveActionImplication(ctx context.Context, action string, implied string) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (:Action {name: $action})-[e:IMPLIES]->(:Action {name: $implied})
+		DELETE e
+		`,
+		map[string]any{
+			"action":  action,
+			"implied": implied,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) GetActionImplications(ctx context.Context) (map[string][]string, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (a:Action)-[:IMPLIES]->(i:Action)
+		RETURN a.name AS action, i.name AS implied
+		ORDER BY action, implied
+		`,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	implications := map[string][]string{}
+	for _, record := range result.Records {
+		actionVal, _ := record.Get("action")
+		impliedVal, _ := record.Get("implied")
+		action, actionOk := actionVal.(string)
+		implied, impliedOk := impliedVal.(string)
+		if actionOk && impliedOk {
+			implications[action] = append(implications[action], implied)
+		}
+	}
+
+	return implications, nil
+}
+
+// actionsImplying returns the action and every action implying it, directly or transitively.
+func (s *Neo4J) actionsImplying(ctx context.Context, action string) ([]string, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (a:Action)-[:IMPLIES*1..]->(:Action {name: $action})
+		RETURN DISTINCT a.name AS name
+		`,
+		map[string]any{
+			"action": action,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []string{action}
+	for _, record := range result.Records {
+		nameVal, _ := record.Get("name")
+		if name, ok := nameVal.(string); ok && name != action {
+			actions = append(actions, name)
+		}
+	}
+
+	return actions, nil
+}
+
+// SetupIndexes creates the uniqueness constraints, along with their backing indexes, and the
+// remaining plain indexes. The plain indexes created on the same properties by earlier versions
+// are dropped first, since Neo4j refuses a constraint on an already indexed property.
+func (s *Neo4J) SetupIndexes(ctx context.Context) error {
+	for _, index := range []string{"subject_name_index", "resource_name_index", "specifier_key_value_index", "policy_id_index", "action_name_index"} {
+		if _, err := s.executeQuery(ctx, "DROP INDEX "+index+" IF EXISTS", nil); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT subject_name_unique IF NOT EXISTS FOR (s:Subject) REQUIRE s.name IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE INDEX subject_name_type_index IF NOT EXISTS FOR (s:Subject) ON (s.name, s.type)`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT resource_name_unique IF NOT EXISTS FOR (r:Resource) REQUIRE r.name IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT specifier_key_value_unique IF NOT EXISTS FOR (s:Specifier) REQUIRE (s.key, s.value) IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT specifier_key_name_unique IF NOT EXISTS FOR (k:SpecifierKey) REQUIRE k.name IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT policy_id_unique IF NOT EXISTS FOR (p:Policy) REQUIRE p.id IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	if _, err := s.executeQuery(ctx, `CREATE CONSTRAINT action_name_unique IF NOT EXISTS FOR (a:Action) REQUIRE a.name IS UNIQUE`, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Neo4J) DeleteEverything(ctx context.Context) error {
+	if _, err := s.executeQuery(ctx, `MATCH (n) DETACH DELETE n`, nil); err != nil {
+		return err
+	}
+	return nil
+}
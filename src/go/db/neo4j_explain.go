@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+func (s *Neo4J) ExplainCan(ctx context.Context, q AccessQuery) ([]PolicyMatch, error) {
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH collect(DISTINCT specifier.key) AS allKeys
+		WITH reduce(specMap = $specifiers, k IN allKeys |
+			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
+		) AS normalizedSpecifiers
+
+		// Candidate policies: held along the subject and resource hierarchies, for the action
+		MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(sHolder:Subject)-[:HAS_POLICY]->(p:Policy)
+		MATCH (resource:Resource {name: $resource})-[:CHILD_OF*0..]->(rHolder:Resource)-[:HAS_POLICY]->(p)
+		MATCH (p)-[e]->(ps:Specifier)
+		WHERE type(e) IN $actions
+
+		WITH DISTINCT normalizedSpecifiers, subject, resource, sHolder, rHolder, p, type(e) AS action, ps
+		WITH normalizedSpecifiers, subject, resource, sHolder, rHolder, p, action, collect(ps) AS policySpecs
+
+		// Shortest CHILD_OF chains to the holders of the policy
+		CALL (subject, sHolder) {
+			MATCH path = (subject)-[:CHILD_OF*0..]->(sHolder)
+			RETURN nodes(path) AS subjectChain
+			ORDER BY length(path)
+			LIMIT 1
+		}
+		CALL (resource, rHolder) {
+			MATCH path = (resource)-[:CHILD_OF*0..]->(rHolder)
+			RETURN nodes(path) AS resourceChain
+			ORDER BY length(path)
+			LIMIT 1
+		}
+
+		// For every input key, the policy specifier it matched through, if any
+		CALL (normalizedSpecifiers, policySpecs) {
+			UNWIND keys(normalizedSpecifiers) AS k
+			OPTIONAL MATCH (input:Specifier {key: k})-[:CHILD_OF*0..]->(matched:Specifier)
+			WHERE (input.value = normalizedSpecifiers[k] OR input.value IN coalesce($constraints[k], [])) AND matched IN policySpecs
+			WITH k, collect(matched.value)[0] AS matchedValue
+			RETURN collect({key: k, value: matchedValue}) AS specifierMatches
+		}
+
+		RETURN
+			p.id AS policyId,
+			coalesce(p.effect, "ALLOW") AS effect,
+			action,
+			[n IN subjectChain | {name: n.name, type: n.type}] AS subjectChain,
+			[n IN resourceChain | n.name] AS resourceChain,
+			specifierMatches,
+			normalizedSpecifiers
+		ORDER BY policyId, action
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]any{
+		"subject":     q.Subject.Name,
+		"resource":    q.Resource.Name,
+		"actions":     actions,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+	}
+
+	if q.Specifiers == nil {
+		params["specifiers"] = map[string]string{}
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]PolicyMatch, 0, len(result.Records))
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+
+		policyId, policyIdOk := recordMap["policyId"].(string)
+		effect, effectOk := recordMap["effect"].(string)
+		action, actionOk := recordMap["action"].(string)
+		subjectChain, subjectChainOk := recordMap["subjectChain"].([]any)
+		resourceChain, resourceChainOk := recordMap["resourceChain"].([]any)
+		specifierMatches, specifierMatchesOk := recordMap["specifierMatches"].([]any)
+		normalizedSpecifiers, normalizedSpecifiersOk := recordMap["normalizedSpecifiers"].(map[string]any)
+
+		if !policyIdOk || !effectOk || !actionOk || !subjectChainOk || !resourceChainOk || !specifierMatchesOk || !normalizedSpecifiersOk {
+			return nil, fmt.Errorf("unexpected result types from ExplainCan query")
+		}
+
+		match := PolicyMatch{
+			PolicyId:        policyId,
+			Effect:          effect,
+			Action:          action,
+			SubjectChain:    make([]SubjectRecord, 0, len(subjectChain)),
+			ResourceChain:   make([]ResourceRecord, 0, len(resourceChain)),
+			Specifiers:      map[string]SpecifierRecord{},
+			InputSpecifiers: map[string]string{},
+		}
+
+		for _, val := range subjectChain {
+			node, nodeOk := val.(map[string]any)
+			name, nameOk := node["name"].(string)
+			subjectType, typeOk := node["type"].(string)
+			if !nodeOk || !nameOk || !typeOk {
+				return nil, fmt.Errorf("unexpected subject chain from ExplainCan query")
+			}
+			match.SubjectChain = append(match.SubjectChain, SubjectRecord{Name: name, Type: subjectType})
+		}
+
+		for _, val := range resourceChain {
+			name, nameOk := val.(string)
+			if !nameOk {
+				return nil, fmt.Errorf("unexpected resource chain from ExplainCan query")
+			}
+			match.ResourceChain = append(match.ResourceChain, ResourceRecord{Name: name})
+		}
+
+		for _, val := range specifierMatches {
+			specifierMatch, specifierMatchOk := val.(map[string]any)
+			key, keyOk := specifierMatch["key"].(string)
+			if !specifierMatchOk || !keyOk {
+				return nil, fmt.Errorf("unexpected specifier match from ExplainCan query")
+			}
+			// A nil value means the key did not match any specifier of the policy
+			if value, valueOk := specifierMatch["value"].(string); valueOk {
+				match.Specifiers[key] = SpecifierRecord{Key: key, Value: value}
+			}
+		}
+
+		for key, val := range normalizedSpecifiers {
+			value, valueOk := val.(string)
+			if !valueOk {
+				return nil, fmt.Errorf("unexpected specifier value type from ExplainCan query")
+			}
+			match.InputSpecifiers[key] = value
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+func (s *Neo4J) GetSubjects(ctx context.Context) ([]SubjectNode, error) {
+	return s.getSubjects(ctx, nil)
+}
+
+func (s *Neo4J) GetSubject(ctx context.Context, name string) (SubjectNode, bool, error) {
+	nodes, err := s.getSubjects(ctx, name)
+	if err != nil || len(nodes) == 0 {
+		return SubjectNode{}, false, err
+	}
+	return nodes[0], true, nil
+}
+
+// getSubjects returns the subject with the name, or all of them if the name is nil.
+func (s *Neo4J) getSubjects(ctx context.Context, name any) ([]SubjectNode, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (s:Subject)
+		WHERE $name IS NULL OR s.name = $name
+		OPTIONAL MATCH (s)-[:CHILD_OF]->(p:Subject)
+		WITH s, p
+		ORDER BY p.name
+		RETURN s.name AS name, s.type AS type, [x IN collect(p) | {name: x.name, type: x.type}] AS parents
+		ORDER BY name
+		`,
+		map[string]any{
+			"name": name,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]SubjectNode, 0, len(result.Records))
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+		name, nameOk := recordMap["name"].(string)
+		subjectType, typeOk := recordMap["type"].(string)
+		parents, parentsOk := recordMap["parents"].([]any)
+		if !nameOk || !typeOk || !parentsOk {
+			return nil, fmt.Errorf("unexpected result types from GetSubjects query")
+		}
+
+		node := SubjectNode{Subject: SubjectRecord{Name: name, Type: subjectType}, Parents: []SubjectRecord{}}
+		for _, val := range parents {
+			parent, parentOk := val.(map[string]any)
+			parentName, parentNameOk := parent["name"].(string)
+			parentType, parentTypeOk := parent["type"].(string)
+			if !parentOk || !parentNameOk || !parentTypeOk {
+				return nil, fmt.Errorf("unexpected parent from GetSubjects query")
+			}
+			node.Parents = append(node.Parents, SubjectRecord{Name: parentName, Type: parentType})
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (s *Neo4J) GetResources(ctx context.Context) ([]ResourceNode, error) {
+	return s.getResources(ctx, nil)
+}
+
+func (s *Neo4J) GetResource(ctx context.Context, name string) (ResourceNode, bool, error) {
+	nodes, err := s.getResources(ctx, name)
+	if err != nil || len(nodes) == 0 {
+		return ResourceNode{}, false, err
+	}
+	return nodes[0], true, nil
+}
+
+// getResources returns the resource with the name, or all of them if the name is nil.
+func (s *Neo4J) getResources(ctx context.Context, name any) ([]ResourceNode, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (r:Resource)
+		WHERE $name IS NULL OR r.name = $name
+		OPTIONAL MATCH (r)-[:CHILD_OF]->(p:Resource)
+		WITH r, p
+		ORDER BY p.name
+		RETURN r.name AS name, collect(p.name) AS parents
+		ORDER BY name
+		`,
+		map[string]any{
+			"name": name,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]ResourceNode, 0, len(result.Records))
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+		name, nameOk := recordMap["name"].(string)
+		parents, parentsOk := recordMap["parents"].([]any)
+		if !nameOk || !parentsOk {
+			return nil, fmt.Errorf("unexpected result types from GetResources query")
+		}
+
+		node := ResourceNode{Resource: ResourceRecord{Name: name}, Parents: []ResourceRecord{}}
+		for _, val := range parents {
+			parentName, parentNameOk := val.(string)
+			if !parentNameOk {
+				return nil, fmt.Errorf("unexpected parent from GetResources query")
+			}
+			node.Parents = append(node.Parents, ResourceRecord{Name: parentName})
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (s *Neo4J) GetSpecifiers(ctx context.Context) ([]SpecifierNode, error) {
+	return s.getSpecifiers(ctx, nil)
+}
+
+func (s *Neo4J) GetSpecifier(ctx context.Context, specifier SpecifierRecord) (SpecifierNode, bool, error) {
+	nodes, err := s.getSpecifiers(ctx, map[string]any{"key": specifier.Key, "value": specifier.Value})
+	if err != nil || len(nodes) == 0 {
+		return SpecifierNode{}, false, err
+	}
+	return nodes[0], true, nil
+}
+
+// getSpecifiers returns the specifier matching the key and value of the map, or all of them if the map is nil.
+func (s *Neo4J) getSpecifiers(ctx context.Context, specifier map[string]any) ([]SpecifierNode, error) {
+	result, err := s.executeQuery(ctx, `
+		MATCH (s:Specifier)
+		WHERE $specifier IS NULL OR (s.key = $specifier.key AND s.value = $specifier.value)
+		OPTIONAL MATCH (s)-[:CHILD_OF]->(p:Specifier)
+		WITH s, p
+		ORDER BY p.key, p.value
+		RETURN s.key AS key, s.value AS value, [x IN collect(p) | {key: x.key, value: x.value}] AS parents
+		ORDER BY key, value
+		`,
+		map[string]any{
+			"specifier": specifier,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]SpecifierNode, 0, len(result.Records))
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+		key, keyOk := recordMap["key"].(string)
+		value, valueOk := recordMap["value"].(string)
+		parents, parentsOk := recordMap["parents"].([]any)
+		if !keyOk || !valueOk || !parentsOk {
+			return nil, fmt.Errorf("unexpected result types from GetSpecifiers query")
+		}
+
+		node := SpecifierNode{Specifier: SpecifierRecord{Key: key, Value: value}, Parents: []SpecifierRecord{}}
+		for _, val := range parents {
+			parent, parentOk := val.(map[string]any)
+			parentKey, parentKeyOk := parent["key"].(string)
+			parentValue, parentValueOk := parent["value"].(string)
+			if !parentOk || !parentKeyOk || !parentValueOk {
+				return nil, fmt.Errorf("unexpected parent from GetSpecifiers query")
+			}
+			node.Parents = append(node.Parents, SpecifierRecord{Key: parentKey, Value: parentValue})
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (s *Neo4J) AddSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (s:Subject {name: $name, type: $type})
+		MATCH (p:Subject {name: $parentName, type: $parentType})
+		MERGE (s)-[:CHILD_OF]->(p)
+		`,
+		map[string]any{
+			"name":       subject.Name,
+			"type":       subject.Type,
+			"parentName": parent.Name,
+			"parentType": parent.Type,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) RemoveSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (:Subject {name: $name, type: $type})-[e:CHILD_OF]->(:Subject {name: $parentName, type: $parentType})
+		DELETE e
+		`,
+		map[string]any{
+			"name":       subject.Name,
+			"type":       subject.Type,
+			"parentName": parent.Name,
+			"parentType": parent.Type,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) AddResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (r:Resource {name: $name})
+		MATCH (p:Resource {name: $parentName})
+		MERGE (r)-[:CHILD_OF]->(p)
+		`,
+		map[string]any{
+			"name":       resource.Name,
+			"parentName": parent.Name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) RemoveResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (:Resource {name: $name})-[e:CHILD_OF]->(:Resource {name: $parentName})
+		DELETE e
+		`,
+		map[string]any{
+			"name":       resource.Name,
+			"parentName": parent.Name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) AddSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (s:Specifier {key: $key, value: $value})
+		MATCH (p:Specifier {key: $parentKey, value: $parentValue})
+		MERGE (s)-[:CHILD_OF]->(p)
+		`,
+		map[string]any{
+			"key":         specifier.Key,
+			"value":       specifier.Value,
+			"parentKey":   parent.Key,
+			"parentValue": parent.Value,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) RemoveSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error {
+	_, err := s.executeQuery(ctx, `
+		MATCH (:Specifier {key: $key, value: $value})-[e:CHILD_OF]->(:Specifier {key: $parentKey, value: $parentValue})
+		DELETE e
+		`,
+		map[string]any{
+			"key":         specifier.Key,
+			"value":       specifier.Value,
+			"parentKey":   parent.Key,
+			"parentValue": parent.Value,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) DeleteSubject(ctx context.Context, subject SubjectRecord) error {
+	// Policies held by the subject cannot be reached anymore, and are deleted along with it
+	_, err := s.executeQuery(ctx, `
+		MATCH (s:Subject {name: $name, type: $type})
+		OPTIONAL MATCH (s)-[:HAS_POLICY]->(p:Policy)
+		DETACH DELETE s, p
+		`,
+		map[string]any{
+			"name": subject.Name,
+			"type": subject.Type,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) DeleteResource(ctx context.Context, resource ResourceRecord) error {
+	// Policies held by the resource cannot be reached anymore, and are deleted along with it
+	_, err := s.executeQuery(ctx, `
+		MATCH (r:Resource {name: $name})
+		OPTIONAL MATCH (r)-[:HAS_POLICY]->(p:Policy)
+		DETACH DELETE r, p
+		`,
+		map[string]any{
+			"name": resource.Name,
+		},
+	)
+	return err
+}
+
+func (s *Neo4J) DeleteSpecifier(ctx context.Context, specifier SpecifierRecord) error {
+	// Policies pointing at the specifier would grant something else without it, and are deleted along with it
+	_, err := s.executeQuery(ctx, `
+		MATCH (s:Specifier {key: $key, value: $value})
+		OPTIONAL MATCH (p:Policy)-->(s)
+		DETACH DELETE s, p
+		`,
+		map[string]any{
+			"key":   specifier.Key,
+			"value": specifier.Value,
+		},
+	)
+	return err
+}
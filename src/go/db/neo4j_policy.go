@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+var errUnexpectedPolicyRecord = fmt.Errorf("unexpected result types from policy query")
+
+func policyFromRecord(record *neo4j.Record) (PolicyRecord, error) {
+	recordMap := record.AsMap()
+
+	policyId, policyIdOk := recordMap["policyId"].(string)
+	action, actionOk := recordMap["action"].(string)
+	subjectNode, subjectOk := recordMap["subject"].(neo4j.Node)
+	resourceNode, resourceOk := recordMap["resource"].(neo4j.Node)
+	if !policyIdOk || !actionOk || !subjectOk || !resourceOk {
+		return PolicyRecord{}, errUnexpectedPolicyRecord
+	}
+
+	subjectName, subjectNameOk := subjectNode.Props["name"].(string)
+	subjectType, subjectTypeOk := subjectNode.Props["type"].(string)
+	resourceName, resourceNameOk := resourceNode.Props["name"].(string)
+	if !subjectNameOk || !subjectTypeOk || !resourceNameOk {
+		return PolicyRecord{}, errUnexpectedPolicyRecord
+	}
+
+	policy := PolicyRecord{
+		Id:         policyId,
+		Subject:    SubjectRecord{Name: subjectName, Type: subjectType},
+		Resource:   ResourceRecord{Name: resourceName},
+		Action:     action,
+		Effect:     EffectAllow,
+		Specifiers: []SpecifierRecord{},
+	}
+
+	if effectVal := recordMap["effect"]; effectVal != nil {
+		effect, effectOk := effectVal.(string)
+		if !effectOk {
+			return PolicyRecord{}, errUnexpectedPolicyRecord
+		}
+		policy.Effect = effect
+	}
+
+	if specifiersVal := recordMap["specifiers"]; specifiersVal != nil {
+		specifierNodes, specifiersOk := specifiersVal.([]any)
+		if !specifiersOk {
+			return PolicyRecord{}, errUnexpectedPolicyRecord
+		}
+		for _, val := range specifierNodes {
+			specifierNode, specifierOk := val.(neo4j.Node)
+			if !specifierOk {
+				return PolicyRecord{}, errUnexpectedPolicyRecord
+			}
+			key, keyOk := specifierNode.Props["key"].(string)
+			value, valueOk := specifierNode.Props["value"].(string)
+			if !keyOk || !valueOk {
+				return PolicyRecord{}, errUnexpectedPolicyRecord
+			}
+			policy.Specifiers = append(policy.Specifiers, SpecifierRecord{Key: key, Value: value})
+		}
+	}
+
+	return policy, nil
+}
+
+func (s *Neo4J) CreatePolicy(ctx context.Context, policy PolicyRecord) (string, error) {
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH collect(DISTINCT specifier.key) AS allKeys
+		WITH reduce(specMap = $specifiers, k IN allKeys |
+			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
+		) AS normalizedSpecifiers
+
+		// Everything is matched before creating anything, so a missing node leaves no orphan policy
+		MATCH (subject:Subject {name: $subjectName})
+		MATCH (resource:Resource {name: $resourceName})
+		UNWIND keys(normalizedSpecifiers) AS k
+		MATCH (specifier:Specifier {key: k, value: normalizedSpecifiers[k]})
+		WITH subject, resource, normalizedSpecifiers, collect(specifier) AS specifiers
+		WHERE size(specifiers) = size(keys(normalizedSpecifiers))
+
+		CREATE (policy:Policy {id: coalesce($id, randomUUID()), effect: $effect})
+		CREATE (subject)-[:HAS_POLICY]->(policy)<-[:HAS_POLICY]-(resource)
+
+		WITH policy, specifiers
+		UNWIND specifiers AS specifier
+		CREATE (policy)-[e:$($action)]->(specifier)
+
+		RETURN DISTINCT policy.id as PolicyId
+	`
+
+	specifierMap := map[string]string{}
+	for _, specifier := range policy.Specifiers {
+		specifierMap[specifier.Key] = specifier.Value
+	}
+
+	params := map[string]any{
+		"subjectName":  policy.Subject.Name,
+		"resourceName": policy.Resource.Name,
+		"action":       policy.Action,
+		"effect":       EffectAllow,
+		"specifiers":   specifierMap,
+		"id":           nil,
+	}
+
+	if policy.Id != "" {
+		params["id"] = policy.Id
+	}
+
+	if policy.Effect != "" {
+		params["effect"] = policy.Effect
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Records) == 0 {
+		return "", nil
+	}
+
+	policyId, ok := result.Records[0].AsMap()["PolicyId"].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected result types from CreatePolicy query")
+	}
+	return policyId, nil
+}
+
+func (s *Neo4J) GetPolicies(ctx context.Context, filter PolicyFilter) ([]PolicyRecord, error) {
+	query := `
+	CALL () {
+		// --- BRANCH A: Specifiers is NULL ---
+		// Fetch ALL policies and their specifiers
+		WITH $specifiers AS inputMap
+		WHERE inputMap IS NULL
+
+		MATCH (p:Policy)-[r]->(s:Specifier)
+		WHERE CASE
+			WHEN $action IS NOT NULL
+			THEN type(r) = $action
+			ELSE TRUE
+		END
+		RETURN p, type(r) as action, collect(s) as specifiers
+
+		UNION
+
+		// --- BRANCH B: Specifiers is not NULL ---
+		WITH $specifiers AS inputMap
+		WHERE inputMap IS NOT NULL
+
+		// B1. Fetch all DB keys to handle implicit wildcards
+		CALL () {
+			MATCH (s:Specifier) WHERE s.key <> "*"
+			RETURN collect(DISTINCT s.key) AS allSpecifierKeys
+		}
+
+		// B2. Normalize the map (Fill missing keys with '*')
+		WITH inputMap,
+			reduce(acc = inputMap, k IN allSpecifierKeys |
+				CASE
+					WHEN k IN keys(inputMap) THEN acc
+					ELSE apoc.map.setKey(acc, k, "*")
+				END
+			) AS normalizedSpecifiers
+
+		UNWIND keys(normalizedSpecifiers) AS k
+		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
+
+		MATCH (s:Specifier)
+		WHERE s.key = k AND s.value = v
+		// WHERE s.key = k AND (s.value = v OR s.value = '*')
+
+		MATCH (p:Policy)-[r]->(s)
+		WHERE CASE
+			WHEN $action IS NOT NULL
+			THEN type(r) = $action
+			ELSE TRUE
+		END
+
+		// Ensure that ALL keys in the normalized map are matched
+		WITH p, type(r) AS action, collect(s) AS specifiers,
+			count(DISTINCT s.key) AS matches,
+			size(keys(normalizedSpecifiers)) AS requiredMatches
+
+		WHERE matches = requiredMatches
+		RETURN p, action, specifiers
+	}
+
+	MATCH (subject:Subject)-[:HAS_POLICY]->(p)
+		WHERE $subject IS NULL OR subject.name = $subject
+
+	MATCH (resource:Resource)-[:HAS_POLICY]->(p)
+		WHERE $resource IS NULL OR resource.name = $resource
+
+	WITH p, action, specifiers, subject, resource, coalesce(p.effect, "ALLOW") AS effect
+		WHERE $effect IS NULL OR effect = $effect
+
+	RETURN
+		p.id AS policyId,
+		action,
+		effect,
+		specifiers,
+		subject,
+		resource
+	`
+
+	params := map[string]any{
+		"subject":    nil,
+		"resource":   nil,
+		"action":     nil,
+		"effect":     nil,
+		"specifiers": nil,
+	}
+
+	if filter.Action != "" {
+		params["action"] = filter.Action
+	}
+
+	if filter.Effect != "" {
+		params["effect"] = filter.Effect
+	}
+
+	if filter.SubjectName != "" {
+		params["subject"] = filter.SubjectName
+	}
+
+	if filter.ResourceName != "" {
+		params["resource"] = filter.ResourceName
+	}
+
+	if filter.Specifiers != nil {
+		params["specifiers"] = filter.Specifiers
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]PolicyRecord, 0, len(result.Records))
+	for _, record := range result.Records {
+		policy, err := policyFromRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+func (s *Neo4J) GetPolicyById(ctx context.Context, id string) (PolicyRecord, bool, error) {
+	query := `
+		MATCH (policy:Policy {id: $policyId})
+		MATCH (subject:Subject)-[:HAS_POLICY]->(policy)
+		MATCH (resource:Resource)-[:HAS_POLICY]->(policy)
+		MATCH (specifier:Specifier)<-[rel]-(policy)
+
+		RETURN DISTINCT policy.id as policyId, subject, resource, type(rel) AS action, coalesce(policy.effect, "ALLOW") AS effect, collect(specifier) AS specifiers
+	`
+
+	params := map[string]any{
+		"policyId": id,
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return PolicyRecord{}, false, err
+	}
+
+	if len(result.Records) == 0 {
+		return PolicyRecord{}, false, nil
+	}
+
+	policy, err := policyFromRecord(result.Records[0])
+	if err != nil {
+		return PolicyRecord{}, false, err
+	}
+	return policy, true, nil
+}
+
+func (s *Neo4J) DeletePolicy(ctx context.Context, id string) error {
+	query := `
+		MATCH (p:Policy {id: $policyId})
+		DETACH DELETE p
+	`
+
+	params := map[string]any{
+		"policyId": id,
+	}
+
+	if _, err := s.executeQuery(ctx, query, params); err != nil {
+		return err
+	}
+	return nil
+}
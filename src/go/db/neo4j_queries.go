@@ -0,0 +1,466 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func (s *Neo4J) Can(ctx context.Context, q AccessQuery) (bool, error) {
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH DISTINCT specifier.key as AllKeys
+		UNWIND AllKeys AS k
+		WITH apoc.map.mergeList(collect(apoc.map.setKey({}, k, "*"))) AS AllSpecMap
+		WITH apoc.map.merge(AllSpecMap, $specifiers) AS NormalizedSpecifiers
+
+		UNWIND keys(NormalizedSpecifiers) AS k
+		WITH NormalizedSpecifiers, k, NormalizedSpecifiers[k] AS v
+
+		MATCH (s:Specifier)
+		WHERE s.key = k AND (s.value = v OR s.value IN coalesce($constraints[k], []))
+
+		MATCH (p:Policy)-[:$any($actions)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
+
+		MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(parents:Subject)-[:HAS_POLICY]->(p)
+		MATCH (resource:Resource {name: $resource})-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
+
+
+		// Aggregate by Policy and count how many *distinct* keys were matched
+		WITH p, count(DISTINCT s.key) AS matches, size(keys(NormalizedSpecifiers)) AS requiredMatches, NormalizedSpecifiers
+
+		// The Policy is valid only if it matched EVERY key in the input
+		WHERE matches = requiredMatches
+
+		// Any matching DENY policy overrides the ALLOW policies
+		WITH collect(DISTINCT coalesce(p.effect, "ALLOW")) AS effects
+		RETURN "ALLOW" IN effects AND NOT "DENY" IN effects AS CanDo
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return false, err
+	}
+	params := map[string]any{
+		"subject":     q.Subject.Name,
+		"resource":    q.Resource.Name,
+		"actions":     actions,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return false, err
+	}
+	if len(result.Records) == 0 {
+		return false, nil
+	}
+
+	canDo, ok := result.Records[0].AsMap()["CanDo"].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected result types from Can query")
+	}
+	return canDo, nil
+}
+
+func (s *Neo4J) WhoCan(ctx context.Context, q AccessQuery) ([]SubjectRecord, error) {
+	subjects := []SubjectRecord{}
+	err := s.StreamWhoCan(ctx, q, func(subject SubjectRecord) error {
+		subjects = append(subjects, subject)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subjects, nil
+}
+
+func (s *Neo4J) StreamWhoCan(ctx context.Context, q AccessQuery, yield func(SubjectRecord) error) error {
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH collect(DISTINCT specifier.key) AS allKeys
+		WITH reduce(specMap = $specifiers, k IN allKeys |
+			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
+		) AS normalizedSpecifiers
+
+		UNWIND keys(normalizedSpecifiers) AS k
+		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
+
+		MATCH (s:Specifier)
+		WHERE s.key = k AND (s.value = v OR s.value IN coalesce($constraints[k], []))
+
+		MATCH (p:Policy)-[:$any($actions)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
+		MATCH (resource:Resource {name: $resource})-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
+
+		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
+		WHERE matches = requiredMatches
+
+		MATCH (subject:Subject {type: $ofType})-[:CHILD_OF*0..]->(:Subject)-[:HAS_POLICY]->(p)
+
+		// Drop the subjects holding any matching DENY policy
+		WITH subject, collect(DISTINCT coalesce(p.effect, "ALLOW")) AS effects
+		WHERE NOT "DENY" IN effects
+
+		RETURN DISTINCT subject.name AS subject, subject.type AS subjectType
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return err
+	}
+	params := map[string]any{
+		"resource":    q.Resource.Name,
+		"actions":     actions,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+		"ofType":      q.SubjectType,
+	}
+
+	return s.streamQuery(ctx, query, params, func(record *neo4j.Record) error {
+		nameVal, nameOk := record.Get("subject")
+		typeVal, typeOk := record.Get("subjectType")
+		if nameOk && typeOk {
+			nameStr, nameIsStr := nameVal.(string)
+			typeStr, typeIsStr := typeVal.(string)
+			if nameIsStr && typeIsStr {
+				return yield(SubjectRecord{Name: nameStr, Type: typeStr})
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Neo4J) WhatCan(ctx context.Context, q AccessQuery) ([]ResourceRecord, error) {
+	resources := []ResourceRecord{}
+	err := s.StreamWhatCan(ctx, q, func(resource ResourceRecord) error {
+		resources = append(resources, resource)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (s *Neo4J) StreamWhatCan(ctx context.Context, q AccessQuery, yield func(ResourceRecord) error) error {
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH collect(DISTINCT specifier.key) AS allKeys
+		WITH reduce(specMap = $specifiers, k IN allKeys |
+			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
+		) AS normalizedSpecifiers
+
+		UNWIND keys(normalizedSpecifiers) AS k
+		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
+
+		MATCH (s:Specifier)
+		WHERE s.key = k AND (s.value = v OR s.value IN coalesce($constraints[k], []))
+
+		MATCH (p:Policy)-[:$any($actions)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
+
+		MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(parents:Subject)-[:HAS_POLICY]->(p)
+
+		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
+		WHERE matches = requiredMatches
+
+		MATCH (resource:Resource)-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
+		MATCH (resource)-[:CHILD_OF*0..]->(parent:Resource {name: $parent})
+
+		// Drop the resources inheriting any matching DENY policy
+		WITH resource, collect(DISTINCT coalesce(p.effect, "ALLOW")) AS effects
+		WHERE NOT "DENY" IN effects
+
+		RETURN DISTINCT resource.name AS resource
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return err
+	}
+	params := map[string]any{
+		"subject":     q.Subject.Name,
+		"actions":     actions,
+		"parent":      q.Resource.Name,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+	}
+
+	return s.streamQuery(ctx, query, params, func(record *neo4j.Record) error {
+		nameVal, nameOk := record.Get("resource")
+		if nameOk {
+			if nameStr, nameIsStr := nameVal.(string); nameIsStr {
+				return yield(ResourceRecord{Name: nameStr})
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Neo4J) WhatCanWithoutAllSpecifiers(ctx context.Context, q AccessQuery) (map[ResourceRecord]map[string][]string, error) {
+	query := `
+		WITH $specifiers AS normalizedSpecifiers
+			UNWIND keys(normalizedSpecifiers) AS k
+
+		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
+			MATCH (s:Specifier)
+				WHERE s.key = k AND (s.value = v OR s.value IN coalesce($constraints[k], []))
+
+			MATCH (p:Policy)-[:$any($actions)]->(:Specifier)<-[:CHILD_OF*0..]-(s)
+
+			MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(:Subject)-[:HAS_POLICY]->(p)
+
+		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
+			WHERE matches = requiredMatches
+
+			MATCH (resource:Resource)-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
+			MATCH (resource)-[:CHILD_OF*0..]->(parent:Resource {name: $parent})
+
+			// Expand the graph to get all the specifiers. DENY policies without any are kept, as they deny everything
+			OPTIONAL MATCH (p)-[:$any($actions)]->(parentSpecifier:Specifier)<-[:CHILD_OF*0..]-(otherSpecifier:Specifier)
+				WHERE NOT otherSpecifier.key IN keys(normalizedSpecifiers) AND parentSpecifier.key <> "*"
+
+		RETURN DISTINCT resource.name AS resource, p.id AS policyId, coalesce(p.effect, "ALLOW") AS effect,
+			coalesce(otherSpecifier.key, "") AS specifierKey, coalesce(otherSpecifier.value, "") AS specifierValue
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]any{
+		"subject":     q.Subject.Name,
+		"actions":     actions,
+		"parent":      q.Resource.Name,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, denied := resourceExpansions{}, resourceExpansions{}
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+		name, nameOk := recordMap["resource"].(string)
+		policyId, policyIdOk := recordMap["policyId"].(string)
+		effect, effectOk := recordMap["effect"].(string)
+		key, keyOk := recordMap["specifierKey"].(string)
+		value, valueOk := recordMap["specifierValue"].(string)
+		if !nameOk || !policyIdOk || !effectOk || !keyOk || !valueOk {
+			return nil, fmt.Errorf("unexpected result types from WhatCanWithoutAllSpecifiers query")
+		}
+
+		expansions := allowed
+		if effect == EffectDeny {
+			expansions = denied
+		}
+		expansions.add(ResourceRecord{Name: name}, policyId, key, value)
+	}
+
+	return withoutDenied(allowed, denied), nil
+}
+
+func (s *Neo4J) HowCan(ctx context.Context, q AccessQuery) (map[string]PolicyExpansion, error) {
+	query := `
+		MATCH (s:Subject {name: $subject, type: $subjectType})-[:CHILD_OF*0..]->(sParent)
+		MATCH (r:Resource {name: $resource})-[:CHILD_OF*0..]->(rParent)
+
+		MATCH (sParent)-[:HAS_POLICY]->(policy:Policy)<-[:HAS_POLICY]-(rParent)
+
+		// All the root specifiers for this policy, filtered by the required action
+		MATCH (policy)-[rel]->(rootSpec:Specifier)
+		WHERE type(rel) IN $actions
+
+		WITH policy, collect(rootSpec) AS policyRootSpecs
+
+		// If specifiers are provided, the policy is only valid if it covers ALL provided keys.
+		WHERE $specifiers IS NULL OR ALL(inputKey IN keys($specifiers) WHERE
+			ANY(pSpec IN policyRootSpecs WHERE
+				pSpec.key = inputKey AND
+				// Check: Is the Input Value a valid descendant of the Policy Specifier?
+				EXISTS {
+					MATCH (pSpec)<-[:CHILD_OF*0..]-(input:Specifier)
+					WHERE input.value = $specifiers[inputKey] OR input.value IN coalesce($constraints[inputKey], [])
+				}
+			)
+		)
+
+		// Get all specifiers from the policy
+		UNWIND policyRootSpecs AS rootSpec
+
+		// Exclude any specifiers that were provided in the input
+		WITH policy, rootSpec
+		WHERE $specifiers IS NULL OR NOT rootSpec.key IN keys($specifiers)
+
+		// Exapnd each root specifier to its valid children
+		CALL {
+			// Two WITH statements required to allow filtering on the imported rootSpec
+
+			WITH rootSpec
+			// Path A: It is a wildcard root, return only the root
+			WITH rootSpec
+			WHERE rootSpec.value = '*'
+			RETURN rootSpec AS finalSpec
+
+			UNION
+
+			WITH rootSpec
+			// Path B: It is specific, return the root and all children
+			WITH rootSpec
+			WHERE rootSpec.value <> '*'
+			MATCH (rootSpec)<-[:CHILD_OF*0..]-(childSpec)
+			RETURN childSpec AS finalSpec
+		}
+
+		RETURN
+			policy.id AS policyId,
+			coalesce(policy.effect, "ALLOW") AS effect,
+			finalSpec.key AS specifierKey,
+			collect(DISTINCT finalSpec.value) AS specifierVals
+	`
+
+	actions, err := s.actionsImplying(ctx, q.Action)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]any{
+		"subject":     q.Subject.Name,
+		"subjectType": q.Subject.Type,
+		"actions":     actions,
+		"resource":    q.Resource.Name,
+		"specifiers":  q.Specifiers,
+		"constraints": q.Constraints,
+	}
+
+	if len(q.Specifiers) == 0 {
+		params["specifiers"] = nil
+	}
+
+	result, err := s.executeQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	policyMap := map[string]PolicyExpansion{}
+	for _, record := range result.Records {
+		policyIdVal, policyIdOk := record.Get("policyId")
+		effectVal, effectOk := record.Get("effect")
+		specifierKeyVal, specifierKeyOk := record.Get("specifierKey")
+		specifierValsVal, specifierValsOk := record.Get("specifierVals")
+
+		if !policyIdOk || !effectOk || !specifierKeyOk || !specifierValsOk {
+			return nil, fmt.Errorf("unexpected result format from HowCan query")
+		}
+
+		policyStr, policyStrOk := policyIdVal.(string)
+		effect, effectOk := effectVal.(string)
+		specifierKey, specifierKeyOk := specifierKeyVal.(string)
+		specifierVals, specifierValsOk := specifierValsVal.([]any)
+
+		if !policyStrOk || !effectOk || !specifierKeyOk || !specifierValsOk {
+			return nil, fmt.Errorf("unexpected result types from HowCan query")
+		}
+
+		if _, exists := policyMap[policyStr]; !exists {
+			policyMap[policyStr] = PolicyExpansion{Effect: effect, Specifiers: map[string][]string{}}
+		}
+		policyMap[policyStr].Specifiers[specifierKey] = []string{}
+
+		for _, val := range specifierVals {
+			if valStr, valStrOk := val.(string); valStrOk {
+				policyMap[policyStr].Specifiers[specifierKey] = append(policyMap[policyStr].Specifiers[specifierKey], valStr)
+			} else {
+				return nil, fmt.Errorf("unexpected specifier value type from HowCan query")
+			}
+		}
+	}
+
+	return policyMap, nil
+}
+
+func (s *Neo4J) CanBatch(ctx context.Context, qs []AccessQuery) ([]bool, error) {
+	if len(qs) == 0 {
+		return []bool{}, nil
+	}
+
+	query := `
+		MATCH (specifier:Specifier)
+		WHERE specifier.key <> "*"
+		WITH collect(DISTINCT specifier.key) AS allKeys
+
+		UNWIND range(0, size($checks) - 1) AS idx
+		WITH allKeys, idx, $checks[idx] AS check
+
+		CALL (allKeys, check) {
+			WITH reduce(specMap = check.specifiers, k IN allKeys |
+				CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
+			) AS normalizedSpecifiers
+
+			// The action and every action implying it
+			OPTIONAL MATCH (implying:Action)-[:IMPLIES*1..]->(:Action {name: check.action})
+			WITH normalizedSpecifiers, collect(DISTINCT implying.name) + [check.action] AS actions
+
+			UNWIND keys(normalizedSpecifiers) AS k
+			WITH normalizedSpecifiers, actions, k, normalizedSpecifiers[k] AS v
+
+			MATCH (s:Specifier)
+			WHERE s.key = k AND (s.value = v OR s.value IN coalesce(check.constraints[k], []))
+
+			MATCH (p:Policy)-[e]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
+			WHERE type(e) IN actions
+
+			MATCH (subject:Subject {name: check.subject})-[:CHILD_OF*0..]->(:Subject)-[:HAS_POLICY]->(p)
+			MATCH (resource:Resource {name: check.resource})-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
+
+			WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches
+			WHERE matches = requiredMatches
+
+			// Any matching DENY policy overrides the ALLOW policies
+			WITH collect(DISTINCT coalesce(p.effect, "ALLOW")) AS effects
+			RETURN "ALLOW" IN effects AND NOT "DENY" IN effects AS canDo
+		}
+
+		RETURN idx, canDo
+		ORDER BY idx
+	`
+
+	checks := make([]map[string]any, 0, len(qs))
+	for _, q := range qs {
+		specifiers := q.Specifiers
+		if specifiers == nil {
+			specifiers = map[string]string{}
+		}
+		checks = append(checks, map[string]any{
+			"subject":     q.Subject.Name,
+			"resource":    q.Resource.Name,
+			"action":      q.Action,
+			"specifiers":  specifiers,
+			"constraints": q.Constraints,
+		})
+	}
+
+	result, err := s.executeQuery(ctx, query, map[string]any{"checks": checks})
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]bool, len(qs))
+	for _, record := range result.Records {
+		recordMap := record.AsMap()
+		idx, idxOk := recordMap["idx"].(int64)
+		canDo, canDoOk := recordMap["canDo"].(bool)
+		if !idxOk || !canDoOk || idx < 0 || int(idx) >= len(qs) {
+			return nil, fmt.Errorf("unexpected result types from CanBatch query")
+		}
+		answers[idx] = canDo
+	}
+
+	return answers, nil
+}
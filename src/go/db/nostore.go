@@ -0,0 +1,197 @@
+package db
+
+import "context"
+
+// noStore is returned by GetInstance when no instance has been set up, so that every call
+// fails with ErrNotInitialized instead of panicking.
+type noStore struct{}
+
+var _ Store = noStore{}
+
+func (noStore) CreateSubject(context.Context, SubjectRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateSubjectAsChildOf(context.Context, SubjectRecord, SubjectRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateResource(context.Context, ResourceRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateResourceAsChildOf(context.Context, ResourceRecord, ResourceRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateSpecifier(context.Context, SpecifierRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateSpecifierAsChildOf(context.Context, SpecifierRecord, SpecifierRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) GetSubjects(context.Context) ([]SubjectNode, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) GetResources(context.Context) ([]ResourceNode, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) GetSpecifiers(context.Context) ([]SpecifierNode, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) GetSubject(context.Context, string) (SubjectNode, bool, error) {
+	return SubjectNode{}, false, ErrNotInitialized
+}
+
+func (noStore) GetResource(context.Context, string) (ResourceNode, bool, error) {
+	return ResourceNode{}, false, ErrNotInitialized
+}
+
+func (noStore) GetSpecifier(context.Context, SpecifierRecord) (SpecifierNode, bool, error) {
+	return SpecifierNode{}, false, ErrNotInitialized
+}
+
+func (noStore) AddSubjectParent(context.Context, SubjectRecord, SubjectRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) RemoveSubjectParent(context.Context, SubjectRecord, SubjectRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) AddResourceParent(context.Context, ResourceRecord, ResourceRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) RemoveResourceParent(context.Context, ResourceRecord, ResourceRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) AddSpecifierParent(context.Context, SpecifierRecord, SpecifierRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) RemoveSpecifierParent(context.Context, SpecifierRecord, SpecifierRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) DeleteSubject(context.Context, SubjectRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) DeleteResource(context.Context, ResourceRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) DeleteSpecifier(context.Context, SpecifierRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateSpecifierKey(context.Context, SpecifierKeyRecord) error {
+	return ErrNotInitialized
+}
+
+func (noStore) GetSpecifierKeys(context.Context) ([]SpecifierKeyRecord, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) DeleteSpecifierKey(context.Context, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) CreateAction(context.Context, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) GetActions(context.Context) ([]string, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) DeleteAction(context.Context, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) AddActionImplication(context.Context, string, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) RemoveActionImplication(context.Context, string, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) GetActionImplications(context.Context) (map[string][]string, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) CreatePolicy(context.Context, PolicyRecord) (string, error) {
+	return "", ErrNotInitialized
+}
+
+func (noStore) GetPolicies(context.Context, PolicyFilter) ([]PolicyRecord, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) GetPolicyById(context.Context, string) (PolicyRecord, bool, error) {
+	return PolicyRecord{}, false, ErrNotInitialized
+}
+
+func (noStore) DeletePolicy(context.Context, string) error {
+	return ErrNotInitialized
+}
+
+func (noStore) Can(context.Context, AccessQuery) (bool, error) {
+	return false, ErrNotInitialized
+}
+
+func (noStore) CanBatch(context.Context, []AccessQuery) ([]bool, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) ExplainCan(context.Context, AccessQuery) ([]PolicyMatch, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) WhoCan(context.Context, AccessQuery) ([]SubjectRecord, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) WhatCan(context.Context, AccessQuery) ([]ResourceRecord, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) StreamWhoCan(context.Context, AccessQuery, func(SubjectRecord) error) error {
+	return ErrNotInitialized
+}
+
+func (noStore) StreamWhatCan(context.Context, AccessQuery, func(ResourceRecord) error) error {
+	return ErrNotInitialized
+}
+
+func (noStore) WhatCanWithoutAllSpecifiers(context.Context, AccessQuery) (map[ResourceRecord]map[string][]string, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) HowCan(context.Context, AccessQuery) (map[string]PolicyExpansion, error) {
+	return nil, ErrNotInitialized
+}
+
+func (noStore) InTx(context.Context, func(tx Store) error) error {
+	return ErrNotInitialized
+}
+
+func (noStore) SetupIndexes(context.Context) error {
+	return ErrNotInitialized
+}
+
+func (noStore) DeleteEverything(context.Context) error {
+	return ErrNotInitialized
+}
+
+func (noStore) Close() error {
+	return nil
+}
@@ -1,17 +1,90 @@
 package db
 
 import (
+	"context"
+
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-func ExecuteQuery(query string, params map[string]any) *neo4j.EagerResult {
-	instance := GetInstance()
-	result, err := neo4j.ExecuteQuery(instance.ctx, instance.driver, query, params,
-		neo4j.EagerResultTransformer,
-		neo4j.ExecuteQueryWithDatabase("neo4j"),
-	)
+// ExecuteQuery runs a raw Cypher query against the Neo4J instance.
+// It returns ErrNotNeo4J if the current instance is not backed by Neo4J.
+func ExecuteQuery(ctx context.Context, query string, params map[string]any) (*neo4j.EagerResult, error) {
+	instance, ok := GetInstance().(*Neo4J)
+	if !ok {
+		return nil, ErrNotNeo4J
+	}
+	return instance.executeQuery(ctx, query, params)
+}
+
+func (s *Neo4J) executeQuery(ctx context.Context, query string, params map[string]any) (*neo4j.EagerResult, error) {
+	var result *neo4j.EagerResult
+	var err error
+	if s.tx != nil {
+		result, err = runInTx(ctx, s.tx, query, params)
+	} else {
+		result, err = neo4j.ExecuteQuery(ctx, s.driver, query, params,
+			neo4j.EagerResultTransformer,
+			neo4j.ExecuteQueryWithDatabase(s.database),
+		)
+	}
+	if err != nil {
+		// The driver does not always wrap the error of the context, so report it as is.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, neo4jError(err)
+	}
+	return result, nil
+}
+
+// runInTx runs the query in the transaction, and collects its result like neo4j.ExecuteQuery does.
+func runInTx(ctx context.Context, tx neo4j.ManagedTransaction, query string, params map[string]any) (*neo4j.EagerResult, error) {
+	cursor, err := tx.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := cursor.Keys()
+	if err != nil {
+		return nil, err
+	}
+	records, err := cursor.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := cursor.Consume(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &neo4j.EagerResult{Keys: keys, Records: records, Summary: summary}, nil
+}
+
+// streamQuery runs the query, calling yield with each record as the driver reads it, and stops at
+// the first error yield returns, which it returns as is. Unlike executeQuery outside a transaction,
+// it is not retried, as the records already yielded cannot be taken back.
+func (s *Neo4J) streamQuery(ctx context.Context, query string, params map[string]any, yield func(*neo4j.Record) error) error {
+	var cursor neo4j.ResultWithContext
+	var err error
+	if s.tx != nil {
+		cursor, err = s.tx.Run(ctx, query, params)
+	} else {
+		session := s.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: s.database, AccessMode: neo4j.AccessModeRead})
+		defer session.Close(context.Background())
+		cursor, err = session.Run(ctx, query, params)
+	}
+
+	if err == nil {
+		for cursor.Next(ctx) {
+			if err := yield(cursor.Record()); err != nil {
+				return err
+			}
+		}
+		err = cursor.Err()
+	}
 	if err != nil {
-		panic(err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return neo4jError(err)
 	}
-	return result
+	return nil
 }
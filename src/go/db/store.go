@@ -0,0 +1,228 @@
+package db
+
+import "context"
+
+// SubjectRecord is the storage representation of a Subject node.
+type SubjectRecord struct {
+	Name string
+	Type string
+}
+
+// ResourceRecord is the storage representation of a Resource node.
+type ResourceRecord struct {
+	Name string
+}
+
+// SpecifierRecord is the storage representation of a Specifier node.
+type SpecifierRecord struct {
+	Key   string
+	Value string
+}
+
+// SpecifierKeyRecord is the storage representation of a SpecifierKey node. Values lists the
+// values the key accepts, any value being accepted when empty. Type is empty for keys matched
+// through the specifier hierarchy.
+type SpecifierKeyRecord struct {
+	Name        string
+	Type        string
+	Description string
+	Values      []string
+	Default     string
+	Required    bool
+}
+
+// SubjectNode is a subject along with its direct parents.
+type SubjectNode struct {
+	Subject SubjectRecord
+	Parents []SubjectRecord
+}
+
+// ResourceNode is a resource along with its direct parents.
+type ResourceNode struct {
+	Resource ResourceRecord
+	Parents  []ResourceRecord
+}
+
+// SpecifierNode is a specifier along with its direct parents.
+type SpecifierNode struct {
+	Specifier SpecifierRecord
+	Parents   []SpecifierRecord
+}
+
+// PolicyRecord is the storage representation of a Policy node and its edges.
+type PolicyRecord struct {
+	Id         string
+	Subject    SubjectRecord
+	Resource   ResourceRecord
+	Action     string
+	Effect     string
+	Specifiers []SpecifierRecord
+}
+
+// Policy effects. Policies stored without an effect are ALLOW policies.
+const (
+	EffectAllow = "ALLOW"
+	EffectDeny  = "DENY"
+)
+
+// PolicyFilter narrows down the policies returned by Store.GetPolicies.
+// Zero values match everything. A nil Specifiers map disables specifier filtering.
+type PolicyFilter struct {
+	SubjectName  string
+	ResourceName string
+	Action       string
+	Effect       string
+	Specifiers   map[string]string
+}
+
+// AccessQuery carries the inputs of the Can, WhoCan, WhatCan and HowCan evaluations.
+// For WhatCan, Resource is the parent resource under which to look.
+// Policies granting any action that implies Action also match, and a matching DENY policy
+// overrides any matching ALLOW policy.
+//
+// Constraints holds, for the keys of typed specifiers, the constraint specifiers the input value
+// satisfies. Policies pointing at one of them match as if it was the input, whose value in Specifiers
+// is then the `*` wildcard.
+type AccessQuery struct {
+	Subject     SubjectRecord
+	SubjectType string
+	Action      string
+	Resource    ResourceRecord
+	Specifiers  map[string]string
+	Constraints map[string][]string
+}
+
+// PolicyExpansion is the result of HowCan for a single policy.
+type PolicyExpansion struct {
+	Effect     string
+	Specifiers map[string][]string
+}
+
+// PolicyMatch describes how a candidate policy relates to a Can query. A candidate policy
+// is held by the subject or one of its ancestors, on the resource or one of its ancestors,
+// for the action or an action implying it.
+type PolicyMatch struct {
+	PolicyId string
+	Effect   string
+	Action   string
+	// SubjectChain goes from the queried subject up to the subject holding the policy.
+	SubjectChain []SubjectRecord
+	// ResourceChain goes from the queried resource up to the resource holding the policy.
+	ResourceChain []ResourceRecord
+	// Specifiers maps every normalized input key to the policy specifier it matched through.
+	// Keys that did not match are absent.
+	Specifiers map[string]SpecifierRecord
+	// InputSpecifiers is the normalized input the policy was matched against.
+	InputSpecifiers map[string]string
+}
+
+// Store is the storage backend behind the entities and the authorization queries.
+type Store interface {
+	// The Create methods fail with an ErrConflict error when the node already exists, which Neo4J
+	// only detects once SetupIndexes has created the constraints. The AsChildOf methods fail with an
+	// ErrNotFound error, creating nothing, when the parent does not exist.
+	CreateSubject(ctx context.Context, subject SubjectRecord) error
+	CreateSubjectAsChildOf(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error
+	CreateResource(ctx context.Context, resource ResourceRecord) error
+	CreateResourceAsChildOf(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error
+	CreateSpecifier(ctx context.Context, specifier SpecifierRecord) error
+	CreateSpecifierAsChildOf(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error
+
+	// GetSubjects, GetResources and GetSpecifiers return every node with its direct parents, sorted.
+	GetSubjects(ctx context.Context) ([]SubjectNode, error)
+	GetResources(ctx context.Context) ([]ResourceNode, error)
+	GetSpecifiers(ctx context.Context) ([]SpecifierNode, error)
+
+	// GetSubject, GetResource and GetSpecifier return a single node with its direct parents,
+	// and whether it exists.
+	GetSubject(ctx context.Context, name string) (SubjectNode, bool, error)
+	GetResource(ctx context.Context, name string) (ResourceNode, bool, error)
+	GetSpecifier(ctx context.Context, specifier SpecifierRecord) (SpecifierNode, bool, error)
+
+	AddSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error
+	RemoveSubjectParent(ctx context.Context, subject SubjectRecord, parent SubjectRecord) error
+	AddResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error
+	RemoveResourceParent(ctx context.Context, resource ResourceRecord, parent ResourceRecord) error
+	AddSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error
+	RemoveSpecifierParent(ctx context.Context, specifier SpecifierRecord, parent SpecifierRecord) error
+
+	// DeleteSubject, DeleteResource and DeleteSpecifier remove the node along with all its edges
+	// and the policies pointing at it.
+	DeleteSubject(ctx context.Context, subject SubjectRecord) error
+	DeleteResource(ctx context.Context, resource ResourceRecord) error
+	DeleteSpecifier(ctx context.Context, specifier SpecifierRecord) error
+
+	// CreateSpecifierKey fails with an ErrConflict error when the key is already registered.
+	CreateSpecifierKey(ctx context.Context, key SpecifierKeyRecord) error
+	// GetSpecifierKeys returns the registered specifier keys, sorted by name.
+	GetSpecifierKeys(ctx context.Context) ([]SpecifierKeyRecord, error)
+	// DeleteSpecifierKey unregisters the key, leaving its specifiers in place.
+	DeleteSpecifierKey(ctx context.Context, name string) error
+
+	CreateAction(ctx context.Context, name string) error
+	GetActions(ctx context.Context) ([]string, error)
+	DeleteAction(ctx context.Context, name string) error
+	// AddActionImplication records that holding the action also grants the implied action.
+	AddActionImplication(ctx context.Context, action string, implied string) error
+	RemoveActionImplication(ctx context.Context, action string, implied string) error
+	// GetActionImplications returns the actions directly implied by each action.
+	GetActionImplications(ctx context.Context) (map[string][]string, error)
+
+	// CreatePolicy stores the policy and returns its ID, generated unless the record has one.
+	// An empty ID is returned, and nothing created, if the subject, the resource or a specifier does
+	// not exist, and an ErrConflict error
+	// if a policy with the ID already exists.
+	CreatePolicy(ctx context.Context, policy PolicyRecord) (string, error)
+	GetPolicies(ctx context.Context, filter PolicyFilter) ([]PolicyRecord, error)
+	// GetPolicyById returns false if no policy exists with the given ID.
+	GetPolicyById(ctx context.Context, id string) (PolicyRecord, bool, error)
+	DeletePolicy(ctx context.Context, id string) error
+
+	Can(ctx context.Context, q AccessQuery) (bool, error)
+	// CanBatch evaluates every query in a single round trip, returning the answers in input order.
+	CanBatch(ctx context.Context, qs []AccessQuery) ([]bool, error)
+	// ExplainCan returns every candidate policy for the Can query, sorted by policy ID and action.
+	ExplainCan(ctx context.Context, q AccessQuery) ([]PolicyMatch, error)
+	WhoCan(ctx context.Context, q AccessQuery) ([]SubjectRecord, error)
+	WhatCan(ctx context.Context, q AccessQuery) ([]ResourceRecord, error)
+	// StreamWhoCan and StreamWhatCan call yield with every answer of WhoCan and WhatCan as it is
+	// read, stopping at the first error yield returns.
+	StreamWhoCan(ctx context.Context, q AccessQuery, yield func(SubjectRecord) error) error
+	StreamWhatCan(ctx context.Context, q AccessQuery, yield func(ResourceRecord) error) error
+	// WhatCanWithoutAllSpecifiers returns, for every matching resource, the values of
+	// each specifier key that was not part of the query. Matching DENY policies remove the
+	// resources and values they deny.
+	WhatCanWithoutAllSpecifiers(ctx context.Context, q AccessQuery) (map[ResourceRecord]map[string][]string, error)
+	// HowCan returns, for every matching ALLOW and DENY policy ID, the expanded values of
+	// each specifier key that was not part of the query. Matching DENY policies remove the
+	// resources and values they deny.
+	HowCan(ctx context.Context, q AccessQuery) (map[string]PolicyExpansion, error)
+
+	// InTx runs fn in a transaction: the writes made through tx are committed together when fn
+	// returns nil, and rolled back otherwise. fn may run more than once if the transaction is retried.
+	// Calling InTx on a transaction joins it.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+
+	// SetupIndexes creates the uniqueness constraints on subject names, resource names, specifier
+	// keys and values, specifier key names, policy IDs and action names, and the indexes used by the queries.
+	SetupIndexes(ctx context.Context) error
+	DeleteEverything(ctx context.Context) error
+	Close() error
+}
+
+var instance Store
+
+// SetInstance replaces the store used by the entities and queries.
+func SetInstance(store Store) {
+	instance = store
+}
+
+// GetInstance returns the store used by the entities and queries. Until one is set up,
+// every method of the returned store fails with ErrNotInitialized.
+func GetInstance() Store {
+	if instance == nil {
+		return noStore{}
+	}
+
+	return instance
+}
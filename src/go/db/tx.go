@@ -0,0 +1,24 @@
+package db
+
+import "context"
+
+// txKey is the context key of the store of the running transaction.
+type txKey struct{}
+
+// InTx runs fn in a transaction of the store. The entities and queries called with the context
+// given to fn take part in the transaction, so their writes are committed together when fn
+// returns nil, and rolled back otherwise. Calling InTx again with that context joins the transaction.
+func InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return FromContext(ctx).InTx(ctx, func(tx Store) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// FromContext returns the store of the transaction running in the context, or the instance outside of InTx.
+// The entities and queries use it to reach the store.
+func FromContext(ctx context.Context) Store {
+	if tx, ok := ctx.Value(txKey{}).(Store); ok {
+		return tx
+	}
+	return GetInstance()
+}
@@ -12,5 +12,9 @@ type Policy struct {
 	Subject    subject.Subject
 	Resource   resource.Resource
 	Action     action.Action
+	Effect     Effect
 	Specifiers specifier.SpecifierGroup
+	// EffectiveActions is the declared Action plus every action it implies.
+	// It is filled in when the policy is created or read from the store.
+	EffectiveActions []action.Action
 }
@@ -1,47 +1,105 @@
 package policy
 
 import (
+	"context"
+	"errors"
+
+	"github.com/namsnath/otter/action"
 	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/specifier"
 )
 
-func (policy Policy) Create() (Policy, error) {
-	query := `
-		MATCH (specifier:Specifier)
-		WHERE specifier.key <> "*"
-		WITH collect(DISTINCT specifier.key) AS allKeys
-		WITH reduce(specMap = $specifiers, k IN allKeys |
-			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
-		) AS normalizedSpecifiers
-
-		MATCH (subject:Subject {name: $subjectName})
-		MATCH (resource:Resource {name: $resourceName})
-		CREATE (policy:Policy {id: randomUUID()})
-		CREATE (subject)-[:HAS_POLICY]->(policy)<-[:HAS_POLICY]-(resource)
+// Create stores the policy. The store generates its ID, unless the policy already has one, in which
+// case it fails with ErrPolicyExists if a policy has the same ID. It fails with ErrPolicyNotCreated
+// if the subject, the resource or a specifier does not exist.
+func (policy Policy) Create(ctx context.Context) (Policy, error) {
+	policy, missingConstraints, err := policy.Check(ctx)
+	if err != nil {
+		return Policy{}, err
+	}
 
-		WITH policy, normalizedSpecifiers
-		UNWIND keys(normalizedSpecifiers) AS k
-		MATCH (specifier:Specifier {key: k, value: normalizedSpecifiers[k]})
-		CREATE (policy)-[e:$($action)]->(specifier)
+	effectiveActions, err := policy.Action.Implied(ctx)
+	if err != nil {
+		return Policy{}, err
+	}
 
-		RETURN DISTINCT policy.id as PolicyId
-	`
+	// Constraints of typed keys are created along with the first policy using them
+	var policyId string
+	err = db.InTx(ctx, func(ctx context.Context) error {
+		for _, constraint := range missingConstraints {
+			if _, err := constraint.GetOrCreateAsChildOf(ctx, specifier.NewSpecifier(constraint.Key, "*")); err != nil {
+				return err
+			}
+		}
 
-	params := map[string]any{
-		"subjectName":  policy.Subject.Name,
-		"resourceName": policy.Resource.Name,
-		"action":       string(policy.Action),
-		"specifiers":   policy.Specifiers.AsMap(),
+		policyId, err = db.FromContext(ctx).CreatePolicy(ctx, policy.Record())
+		if err == nil && policyId == "" {
+			err = ErrPolicyNotCreated
+		}
+		return err
+	})
+	if errors.Is(err, db.ErrConflict) {
+		return Policy{}, ErrPolicyExists
 	}
-
-	result := db.ExecuteQuery(query, params)
-	if len(result.Records) == 0 {
-		return Policy{}, nil
+	if err != nil {
+		return Policy{}, err
 	}
 
-	policyId := result.Records[0].AsMap()["PolicyId"].(string)
-
 	newPolicy := policy
 	newPolicy.Id = policyId
+	newPolicy.EffectiveActions = effectiveActions
 
 	return newPolicy, nil
 }
+
+// Check runs the checks of Create without writing anything: the action must exist, the effect must be
+// valid and the registered specifier keys must accept the specifiers. It returns the policy with its
+// effect defaulted, and the constraints of typed keys the store does not have yet.
+func (policy Policy) Check(ctx context.Context) (Policy, []specifier.Specifier, error) {
+	if _, err := action.FromString(ctx, string(policy.Action)); err != nil {
+		return Policy{}, nil, err
+	}
+
+	effect, err := EffectFromString(string(policy.Effect))
+	if err != nil {
+		return Policy{}, nil, err
+	}
+	policy.Effect = effect
+
+	registry, err := specifier.LoadRegistry(ctx)
+	if err != nil {
+		return Policy{}, nil, err
+	}
+	if err := registry.Check(policy.Specifiers.AsMap()); err != nil {
+		return Policy{}, nil, err
+	}
+
+	return policy, registry.MissingConstraints(policy.Specifiers.AsMap()), nil
+}
+
+// Upsert creates the policy, or replaces the one with the same ID, keeping the ID. Policies without
+// an ID are always created.
+func (policy Policy) Upsert(ctx context.Context) (Policy, error) {
+	if policy.Id == "" {
+		return policy.Create(ctx)
+	}
+
+	var result Policy
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		existing, err := policy.GetById(ctx)
+		if err != nil {
+			return err
+		}
+		if existing.Id == "" {
+			result, err = policy.Create(ctx)
+		} else {
+			result, err = existing.Update(ctx, policy)
+		}
+		return err
+	})
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return result, nil
+}
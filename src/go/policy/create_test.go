@@ -0,0 +1,67 @@
+package policy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestPolicyCreate(t *testing.T) {
+	db.TestContainer(t)
+
+	testPolicyCreate(t)
+}
+
+func TestPolicyCreateInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testPolicyCreate(t)
+}
+
+func testPolicyCreate(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	r1 := resource.Resource{Name: "Resource1"}
+
+	before, err := db.FromContext(ctx).GetPolicies(ctx, db.PolicyFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Missing subject or resource", func(t *testing.T) {
+		for _, p := range []policy.Policy{
+			{Subject: subject.Subject{Name: "Missing", Type: subject.SubjectTypePrincipal}, Resource: r1, Action: action.ActionRead},
+			{Subject: p1, Resource: resource.Resource{Name: "Missing"}, Action: action.ActionRead},
+		} {
+			if _, err := p.Create(ctx); !errors.Is(err, policy.ErrPolicyNotCreated) {
+				t.Errorf("Expected %v, got %v", policy.ErrPolicyNotCreated, err)
+			}
+		}
+	})
+
+	t.Run("Missing specifier", func(t *testing.T) {
+		// The store creates nothing, rather than a policy without the missing edge
+		id, err := db.FromContext(ctx).CreatePolicy(ctx, db.PolicyRecord{
+			Subject:    p1.Record(),
+			Resource:   r1.Record(),
+			Action:     string(action.ActionRead),
+			Specifiers: []db.SpecifierRecord{{Key: "Env", Value: "prod"}, {Key: "Role", Value: "missing"}},
+		})
+		if err != nil || id != "" {
+			t.Errorf("Expected no policy, got %q, %v", id, err)
+		}
+	})
+
+	after, err := db.FromContext(ctx).GetPolicies(ctx, db.PolicyFilter{})
+	if err != nil || len(after) != len(before) {
+		t.Errorf("Expected %d policies, got %d, %v", len(before), len(after), err)
+	}
+}
@@ -1,23 +1,15 @@
 package policy
 
 import (
+	"context"
+
 	"github.com/namsnath/otter/db"
 )
 
-func (policy Policy) Delete() error {
+func (policy Policy) Delete(ctx context.Context) error {
 	if policy.Id == "" {
 		return ErrPolicyIDRequired
 	}
 
-	query := `
-		MATCH (p:Policy {id: $policyId})
-		DETACH DELETE p
-	`
-
-	params := map[string]any{
-		"policyId": policy.Id,
-	}
-
-	db.ExecuteQuery(query, params)
-	return nil
+	return db.FromContext(ctx).DeletePolicy(ctx, policy.Id)
 }
@@ -0,0 +1,26 @@
+package policy
+
+import "github.com/namsnath/otter/db"
+
+// Effect decides whether a matching policy grants or denies access.
+// A matching DENY policy anywhere in the hierarchy overrides any ALLOW policy.
+type Effect string
+
+const (
+	EffectAllow Effect = db.EffectAllow
+	EffectDeny  Effect = db.EffectDeny
+)
+
+var ErrInvalidEffect = db.NewError(db.ErrInvalidInput, "invalid Effect")
+
+// EffectFromString parses the effect, defaulting to EffectAllow when empty.
+func EffectFromString(s string) (Effect, error) {
+	switch s {
+	case "", db.EffectAllow:
+		return EffectAllow, nil
+	case db.EffectDeny:
+		return EffectDeny, nil
+	default:
+		return "", ErrInvalidEffect
+	}
+}
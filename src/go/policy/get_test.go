@@ -16,30 +16,36 @@ import (
 )
 
 func TestPolicyGetQueries(t *testing.T) {
-	ctx, container := db.TestContainer()
-	// Ensure the container is terminated after the test finishes
-	defer func() {
-		container.Terminate(ctx)
-	}()
+	db.TestContainer(t)
 
-	query.DeleteEverything()
-	query.SetupIndexes()
+	testPolicyGetQueries(t)
+}
+
+func TestPolicyGetQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testPolicyGetQueries(t)
+}
+
+func testPolicyGetQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupIndexes(ctx)
 
-	g1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.Create()
-	g2 := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}.Create()
-	rRoot := resource.Resource{Name: "_"}.Create()
-	r1 := resource.Resource{Name: "Resource1"}.CreateAsChildOf(rRoot)
-	rootSpecifier := specifier.NewSpecifier("*", "*").Create()
-	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(rootSpecifier)
-	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(envRoot)
-	specifier.NewSpecifier("Env", "dev").CreateAsChildOf(envRoot)
+	g1, _ := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.Create(ctx)
+	g2, _ := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}.Create(ctx)
+	rRoot, _ := resource.Resource{Name: "_"}.Create(ctx)
+	r1, _ := resource.Resource{Name: "Resource1"}.CreateAsChildOf(ctx, rRoot)
+	rootSpecifier, _ := specifier.NewSpecifier("*", "*").Create(ctx)
+	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(ctx, rootSpecifier)
+	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(ctx, envRoot)
+	specifier.NewSpecifier("Env", "dev").CreateAsChildOf(ctx, envRoot)
 
 	g1R1ReadPolicy, _ := policy.Policy{
 		Subject:    g1,
 		Resource:   r1,
 		Action:     action.ActionRead,
 		Specifiers: specifier.SpecifierGroup{},
-	}.Create()
+	}.Create(ctx)
 	expectedG1R1ReadPolicy := g1R1ReadPolicy
 	expectedG1R1ReadPolicy.Specifiers = specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envRoot}}
 
@@ -48,7 +54,7 @@ func TestPolicyGetQueries(t *testing.T) {
 		Resource:   r1,
 		Action:     action.ActionRead,
 		Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envProd}},
-	}.Create()
+	}.Create(ctx)
 	expectedG2R1ProdReadPolicy := g2R1ProdReadPolicy
 
 	g1R1WritePolicy, _ := policy.Policy{
@@ -56,7 +62,7 @@ func TestPolicyGetQueries(t *testing.T) {
 		Resource:   r1,
 		Action:     action.ActionWrite,
 		Specifiers: specifier.SpecifierGroup{},
-	}.Create()
+	}.Create(ctx)
 	expectedG1R1WritePolicy := g1R1WritePolicy
 	expectedG1R1WritePolicy.Specifiers = specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envRoot}}
 
@@ -83,7 +89,7 @@ func TestPolicyGetQueries(t *testing.T) {
 				Resource:   tc.resource,
 				Action:     tc.action,
 				Specifiers: specifier.SpecifierGroup{Specifiers: tc.specifiers},
-			}.Get()
+			}.Get(ctx)
 
 			if err != nil {
 				t.Errorf("Unexpected error for %s: %v", tc.name, err)
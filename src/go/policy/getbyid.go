@@ -1,36 +1,26 @@
 package policy
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/namsnath/otter/db"
 )
 
-func (policy Policy) GetById() (Policy, error) {
+func (policy Policy) GetById(ctx context.Context) (Policy, error) {
 	if policy.Id == "" {
-		return Policy{}, fmt.Errorf("policy Id should be specified")
+		return Policy{}, db.NewError(db.ErrInvalidInput, "policy Id should be specified")
 	}
 
-	query := `
-		MATCH (policy:Policy {id: $policyId})
-		MATCH (subject:Subject)-[:HAS_POLICY]->(policy)
-		MATCH (resource:Resource)-[:HAS_POLICY]->(policy)
-		MATCH (specifier:Specifier)<-[rel]-(policy)
-
-		RETURN DISTINCT policy.id as policyId, subject, resource, type(rel) AS action, collect(specifier) AS specifiers
-	`
-
-	params := map[string]any{
-		"policyId": policy.Id,
+	record, found, err := db.FromContext(ctx).GetPolicyById(ctx, policy.Id)
+	if err != nil {
+		return Policy{}, err
 	}
 
-	result := db.ExecuteQuery(query, params)
-
-	if len(result.Records) == 0 {
+	if !found {
 		return Policy{}, nil
 	}
 
-	resultPolicy, err := ProcessPolicyRecord(result.Records[0])
+	resultPolicy, err := ProcessPolicyRecord(ctx, record)
 	if err != nil {
 		return Policy{}, err
 	}
@@ -1,21 +1,38 @@
 package policy
 
-func (policy Policy) Update(newPolicy Policy) (Policy, error) {
+import (
+	"context"
+
+	"github.com/namsnath/otter/db"
+)
+
+// Update replaces the policy with newPolicy, keeping its ID. The old policy is deleted and the
+// new one created in a single transaction, so a failed update leaves the old policy in place.
+func (policy Policy) Update(ctx context.Context, newPolicy Policy) (Policy, error) {
 	if policy.Id == "" {
 		return Policy{}, ErrPolicyIDRequired
 	}
 
-	newPolicyObj, err := newPolicy.Create()
-	if err != nil {
-		return Policy{}, err
-	}
+	var updated Policy
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		if existing, err := policy.GetById(ctx); err != nil {
+			return err
+		} else if existing.Id == "" {
+			return ErrPolicyNotFound
+		}
+
+		if err := policy.Delete(ctx); err != nil {
+			return err
+		}
 
-	err = policy.Delete()
+		newPolicy.Id = policy.Id
+		created, err := newPolicy.Create(ctx)
+		updated = created
+		return err
+	})
 	if err != nil {
-		// TODO: WHat if this delete also errors out?
-		newPolicyObj.Delete()
 		return Policy{}, err
 	}
 
-	return newPolicyObj, nil
+	return updated, nil
 }
@@ -0,0 +1,137 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestPolicyUpdate(t *testing.T) {
+	db.TestContainer(t)
+
+	testPolicyUpdate(t)
+}
+
+func TestPolicyUpdateInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testPolicyUpdate(t)
+}
+
+func testPolicyUpdate(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	r1 := resource.Resource{Name: "Resource1"}
+	envProd := specifier.NewSpecifier("Env", "prod")
+
+	original, err := policy.Policy{
+		Subject:  p1,
+		Resource: r1,
+		Action:   action.ActionWrite,
+	}.Create(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error creating: %v", err)
+	}
+
+	t.Run("Keeps the ID", func(t *testing.T) {
+		replacement := original
+		replacement.Specifiers = specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envProd}}
+
+		updated, err := original.Update(ctx, replacement)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if updated.Id != original.Id {
+			t.Errorf("Expected the ID %s to be kept, got %s", original.Id, updated.Id)
+		}
+
+		stored, err := original.GetById(ctx)
+		if err != nil || !slices.Contains(stored.Specifiers.Specifiers, envProd) {
+			t.Errorf("Expected the stored policy to be replaced, got %v, %v", stored, err)
+		}
+	})
+
+	t.Run("Rolls back", func(t *testing.T) {
+		replacement := original
+		replacement.Resource = resource.Resource{Name: "Missing"}
+
+		if _, err := original.Update(ctx, replacement); !errors.Is(err, policy.ErrPolicyNotCreated) {
+			t.Errorf("Expected %v, got %v", policy.ErrPolicyNotCreated, err)
+		}
+		if stored, err := original.GetById(ctx); err != nil || stored.Resource != r1 {
+			t.Errorf("Expected the failed update to keep the old policy, got %v, %v", stored, err)
+		}
+	})
+
+	t.Run("Missing policy", func(t *testing.T) {
+		if _, err := (policy.Policy{Id: "missing"}).Update(ctx, original); !errors.Is(err, policy.ErrPolicyNotFound) {
+			t.Errorf("Expected %v, got %v", policy.ErrPolicyNotFound, err)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		if _, err := original.Create(ctx); !errors.Is(err, policy.ErrPolicyExists) {
+			t.Errorf("Expected %v, got %v", policy.ErrPolicyExists, err)
+		}
+
+		replacement := original
+		replacement.Action = action.ActionRead
+		if upserted, err := replacement.Upsert(ctx); err != nil || upserted.Id != original.Id || upserted.Action != action.ActionRead {
+			t.Errorf("Expected %v to be replaced, got %v, %v", original.Id, upserted, err)
+		}
+
+		created := replacement
+		created.Id = "upserted"
+		for range 2 {
+			if upserted, err := created.Upsert(ctx); err != nil || upserted.Id != created.Id {
+				t.Errorf("Expected %v to be created, got %v, %v", created.Id, upserted, err)
+			}
+		}
+		if policies, _ := (policy.Policy{Subject: p1, Resource: r1, Action: action.ActionRead}).Get(ctx); len(policies) != 2 {
+			t.Errorf("Expected 2 policies, got %v", policies)
+		}
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		p9 := subject.Subject{Name: "Principal9", Type: subject.SubjectTypePrincipal}
+		errAbort := errors.New("abort")
+
+		err := db.InTx(ctx, func(ctx context.Context) error {
+			if _, err := p9.Create(ctx); err != nil {
+				return err
+			}
+			if _, err := subject.Get(ctx, p9.Name); err != nil {
+				t.Errorf("Expected the transaction to see its own writes, got %v", err)
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("Expected %v, got %v", errAbort, err)
+		}
+		if _, err := subject.Get(ctx, p9.Name); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected the aborted transaction to be rolled back, got %v", err)
+		}
+
+		err = db.InTx(ctx, func(ctx context.Context) error {
+			_, err := p9.Create(ctx)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := subject.Get(ctx, p9.Name); err != nil {
+			t.Errorf("Expected the transaction to be committed, got %v", err)
+		}
+	})
+}
@@ -1,57 +1,71 @@
 package policy
 
 import (
-	"errors"
+	"context"
 
 	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
 	"github.com/namsnath/otter/resource"
 	"github.com/namsnath/otter/specifier"
 	"github.com/namsnath/otter/subject"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-var ErrPolicyIDRequired = errors.New("policy ID is required")
+var ErrPolicyIDRequired = db.NewError(db.ErrInvalidInput, "policy ID is required")
+var ErrPolicyNotCreated = db.NewError(db.ErrNotFound, "policy not created: subject, resource or specifiers not found")
+var ErrPolicyNotFound = db.NewError(db.ErrNotFound, "policy not found")
+var ErrPolicyExists = db.NewError(db.ErrConflict, "policy already exists")
 
-func ProcessPolicyRecord(record *neo4j.Record) (Policy, error) {
+func ProcessPolicyRecord(ctx context.Context, record db.PolicyRecord) (Policy, error) {
 	policy := Policy{}
 
-	policyIdVal, _ := record.Get("policyId")
-	policy.Id = policyIdVal.(string)
+	policy.Id = record.Id
 
-	actionVal, _ := record.Get("action")
-	actionStr := actionVal.(string)
-	actionEnum, err := action.FromString(actionStr)
+	actionEnum, err := action.FromString(ctx, record.Action)
 	if err != nil {
 		return Policy{}, err
 	}
 	policy.Action = actionEnum
 
-	subjectVal, _ := record.Get("subject")
-	subjectNode := subjectVal.(neo4j.Node)
-	subjectType, err := subject.SubjectTypeFromString(subjectNode.Props["type"].(string))
+	policy.EffectiveActions, err = actionEnum.Implied(ctx)
 	if err != nil {
 		return Policy{}, err
 	}
-	subjectObj := subject.Subject{Name: subjectNode.Props["name"].(string), Type: subjectType}
-	policy.Subject = subjectObj
 
-	resourceVal, _ := record.Get("resource")
-	resourceNode := resourceVal.(neo4j.Node)
-	resourceObj := resource.Resource{Name: resourceNode.Props["name"].(string)}
-	policy.Resource = resourceObj
+	policy.Effect, err = EffectFromString(record.Effect)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	subjectType, err := subject.SubjectTypeFromString(record.Subject.Type)
+	if err != nil {
+		return Policy{}, err
+	}
+	policy.Subject = subject.Subject{Name: record.Subject.Name, Type: subjectType}
+
+	policy.Resource = resource.Resource{Name: record.Resource.Name}
 
-	specifiersVal, _ := record.Get("specifiers")
 	specifiers := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{}}
-	if specifiersVal != nil {
-		specifierNodes := specifiersVal.([]interface{})
-		for _, specifierNode := range specifierNodes {
-			specifierNode := specifierNode.(neo4j.Node)
-			key := specifierNode.Props["key"].(string)
-			value := specifierNode.Props["value"].(string)
-			specifiers.Specifiers = append(specifiers.Specifiers, specifier.Specifier{Key: key, Value: value})
-		}
+	for _, specifierRecord := range record.Specifiers {
+		specifiers.Specifiers = append(specifiers.Specifiers, specifier.Specifier{Key: specifierRecord.Key, Value: specifierRecord.Value})
 	}
 	policy.Specifiers = specifiers
 
 	return policy, nil
 }
+
+// Record returns the storage representation of the policy.
+func (policy Policy) Record() db.PolicyRecord {
+	specifiers := []db.SpecifierRecord{}
+	for k, v := range policy.Specifiers.AsMap() {
+		specifiers = append(specifiers, db.SpecifierRecord{Key: k, Value: v})
+	}
+
+	return db.PolicyRecord{
+		Id:         policy.Id,
+		Subject:    policy.Subject.Record(),
+		Resource:   policy.Resource.Record(),
+		Action:     string(policy.Action),
+		Effect:     string(policy.Effect),
+		Specifiers: specifiers,
+	}
+}
@@ -1,8 +1,9 @@
 package query
 
 import (
-	"fmt"
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/namsnath/otter/action"
@@ -27,7 +28,7 @@ type CanResult struct {
 }
 
 func (result CanResult) Ok() bool {
-	return result.Err != nil
+	return result.Err == nil
 }
 
 // `Can` initializes a new QueryBuilder and sets the Subject.
@@ -57,12 +58,12 @@ func (qb CanQueryBuilder) With(sg specifier.SpecifierGroup) CanQueryBuilder {
 
 func (qb CanQueryBuilder) Validate() (CanQueryBuilder, error) {
 	if qb.subject == (subject.Subject{}) || qb.action == "" || qb.resource == (resource.Resource{}) {
-		return qb, fmt.Errorf("incomplete Can query: subject, action, and resource must be set")
+		return qb, db.NewError(db.ErrInvalidInput, "incomplete Can query: subject, action, and resource must be set")
 	}
 	return qb, nil
 }
 
-func (qb CanQueryBuilder) Query() CanResult {
+func (qb CanQueryBuilder) Query(ctx context.Context) CanResult {
 	qb, validationError := qb.Validate()
 	if validationError != nil {
 		return CanResult{
@@ -71,63 +72,31 @@ func (qb CanQueryBuilder) Query() CanResult {
 		}
 	}
 
-	query := `
-		MATCH (specifier:Specifier)
-		WHERE specifier.key <> "*"
-		WITH DISTINCT specifier.key as AllKeys
-		UNWIND AllKeys AS k
-		WITH apoc.map.mergeList(collect(apoc.map.setKey({}, k, "*"))) AS AllSpecMap
-		WITH apoc.map.merge(AllSpecMap, $specifiers) AS NormalizedSpecifiers
-
-		UNWIND keys(NormalizedSpecifiers) AS k
-		WITH NormalizedSpecifiers, k, NormalizedSpecifiers[k] AS v
-
-		MATCH (s:Specifier)
-		WHERE s.key = k AND s.value = v
-
-		MATCH (p:Policy)-[:$($action)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
-
-		MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(parents:Subject)-[:HAS_POLICY]->(p)
-		MATCH (resource:Resource {name: $resource})-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
-
-
-		// Aggregate by Policy and count how many *distinct* keys were matched
-		WITH p, count(DISTINCT s.key) AS matches, size(keys(NormalizedSpecifiers)) AS requiredMatches, NormalizedSpecifiers
-
-		// The Policy is valid only if it matched EVERY key in the input
-		WHERE matches = requiredMatches
-
-		RETURN p IS NOT NULL AS CanDo
-	`
-
-	params := map[string]any{
-		"subject":    qb.subject.Name,
-		"resource":   qb.resource.Name,
-		"action":     string(qb.action),
-		"specifiers": qb.specifiers,
+	specifiers, constraints, err := resolveSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return CanResult{Err: err, Can: false}
 	}
-
-	queryResult := db.ExecuteQuery(query, params)
+	qb.specifiers = specifiers
+
+	start := time.Now()
+	canDo, err := db.FromContext(ctx).Can(ctx, db.AccessQuery{
+		Subject:     qb.subject.Record(),
+		Action:      string(qb.action),
+		Resource:    qb.resource.Record(),
+		Specifiers:  qb.specifiers,
+		Constraints: constraints,
+	})
 	slog.Info("Can",
 		"subject", qb.subject,
 		"action", qb.action,
 		"resource", qb.resource,
 		"specifiers", qb.specifiers,
-		"duration", queryResult.Summary.ResultAvailableAfter(),
-		"rows", len(queryResult.Records),
+		"duration", time.Since(start),
+		"can", canDo,
 	)
 
-	if len(queryResult.Records) == 0 {
-		return CanResult{
-			Err: nil,
-			Can: false,
-		}
-	}
-
-	canDo := queryResult.Records[0].AsMap()["CanDo"].(bool)
-
 	return CanResult{
-		Err: nil,
+		Err: err,
 		Can: canDo,
 	}
 }
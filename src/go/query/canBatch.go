@@ -0,0 +1,71 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/specifier"
+)
+
+// CanBatch evaluates the Can queries in a single round trip to the store.
+// Results are returned in input order. An invalid query only fails its own result,
+// while a store error fails every valid query.
+func CanBatch(ctx context.Context, queries []CanQueryBuilder) []CanResult {
+	results := make([]CanResult, len(queries))
+
+	registry, err := specifier.LoadRegistry(ctx)
+	if err != nil {
+		for i := range results {
+			results[i] = CanResult{Err: err, Can: false}
+		}
+		return results
+	}
+
+	params := []db.AccessQuery{}
+	positions := []int{}
+	for i, qb := range queries {
+		qb, validationError := qb.Validate()
+		if validationError != nil {
+			results[i] = CanResult{Err: validationError, Can: false}
+			continue
+		}
+		specifiers, constraints, err := registry.ForQuery(qb.specifiers)
+		if err != nil {
+			results[i] = CanResult{Err: err, Can: false}
+			continue
+		}
+
+		params = append(params, db.AccessQuery{
+			Subject:     qb.subject.Record(),
+			Action:      string(qb.action),
+			Resource:    qb.resource.Record(),
+			Specifiers:  specifiers,
+			Constraints: constraints,
+		})
+		positions = append(positions, i)
+	}
+
+	if len(params) == 0 {
+		return results
+	}
+
+	start := time.Now()
+	answers, err := db.FromContext(ctx).CanBatch(ctx, params)
+	slog.Info("CanBatch",
+		"queries", len(queries),
+		"evaluated", len(params),
+		"duration", time.Since(start),
+	)
+
+	for j, i := range positions {
+		if err != nil {
+			results[i] = CanResult{Err: err, Can: false}
+			continue
+		}
+		results[i] = CanResult{Err: nil, Can: answers[j]}
+	}
+
+	return results
+}
@@ -0,0 +1,232 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+// SpecifierMatch is an input specifier and the policy specifier it matched through.
+type SpecifierMatch struct {
+	Input          specifier.Specifier `json:"input" yaml:"input"`
+	MatchedThrough specifier.Specifier `json:"matchedThrough" yaml:"matchedThrough"`
+}
+
+// PolicyExplanation describes how a candidate policy relates to a Can query.
+type PolicyExplanation struct {
+	PolicyId string        `json:"policyId" yaml:"policyId"`
+	Effect   policy.Effect `json:"effect" yaml:"effect"`
+	Action   action.Action `json:"action" yaml:"action"`
+	// SubjectChain follows CHILD_OF from the queried subject to the subject holding the policy.
+	SubjectChain []subject.Subject `json:"subjectChain" yaml:"subjectChain"`
+	// ResourceChain follows CHILD_OF from the queried resource to the resource holding the policy.
+	ResourceChain []resource.Resource `json:"resourceChain" yaml:"resourceChain"`
+	// Matches holds the input specifiers that matched, sorted by key.
+	Matches []SpecifierMatch `json:"matches" yaml:"matches"`
+	// FailedKeys holds the input specifiers that no specifier of the policy matched, sorted by key.
+	FailedKeys []specifier.Specifier `json:"failedKeys" yaml:"failedKeys"`
+}
+
+// Matched reports whether the policy applies to the query.
+func (e PolicyExplanation) Matched() bool {
+	return len(e.FailedKeys) == 0 && len(e.Matches) > 0
+}
+
+// CanExplanation is the result of CanQueryBuilder.Explain.
+type CanExplanation struct {
+	Can bool `json:"can" yaml:"can"`
+	// Granting holds the matching ALLOW policies.
+	Granting []PolicyExplanation `json:"granting" yaml:"granting"`
+	// Denying holds the matching DENY policies, which override any grant.
+	Denying []PolicyExplanation `json:"denying" yaml:"denying"`
+	// Unmatched holds the candidate policies that failed on at least one specifier key.
+	Unmatched []PolicyExplanation `json:"unmatched" yaml:"unmatched"`
+}
+
+// Explain runs the Can query and returns the policies behind the answer.
+func (qb CanQueryBuilder) Explain(ctx context.Context) (CanExplanation, error) {
+	qb, validationError := qb.Validate()
+	if validationError != nil {
+		return CanExplanation{}, validationError
+	}
+
+	specifiers, constraints, err := resolveSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return CanExplanation{}, err
+	}
+	// Typed keys are queried as wildcards, but explained with the values they were given
+	values := qb.specifiers
+	qb.specifiers = specifiers
+
+	start := time.Now()
+	matches, err := db.FromContext(ctx).ExplainCan(ctx, db.AccessQuery{
+		Subject:     qb.subject.Record(),
+		Action:      string(qb.action),
+		Resource:    qb.resource.Record(),
+		Specifiers:  qb.specifiers,
+		Constraints: constraints,
+	})
+	if err != nil {
+		return CanExplanation{}, err
+	}
+
+	explanation := CanExplanation{
+		Granting:  []PolicyExplanation{},
+		Denying:   []PolicyExplanation{},
+		Unmatched: []PolicyExplanation{},
+	}
+	for _, match := range matches {
+		for k := range constraints {
+			if v, exists := values[k]; exists {
+				match.InputSpecifiers[k] = v
+			}
+		}
+		policyExplanation, err := explainPolicy(ctx, match)
+		if err != nil {
+			return CanExplanation{}, err
+		}
+
+		switch {
+		case !policyExplanation.Matched():
+			explanation.Unmatched = append(explanation.Unmatched, policyExplanation)
+		case policyExplanation.Effect == policy.EffectDeny:
+			explanation.Denying = append(explanation.Denying, policyExplanation)
+		default:
+			explanation.Granting = append(explanation.Granting, policyExplanation)
+		}
+	}
+	explanation.Can = len(explanation.Granting) > 0 && len(explanation.Denying) == 0
+
+	slog.Info("Can.Explain",
+		"subject", qb.subject,
+		"action", qb.action,
+		"resource", qb.resource,
+		"specifiers", qb.specifiers,
+		"duration", time.Since(start),
+		"rows", len(matches),
+		"can", explanation.Can,
+	)
+
+	return explanation, nil
+}
+
+func explainPolicy(ctx context.Context, match db.PolicyMatch) (PolicyExplanation, error) {
+	effect, err := policy.EffectFromString(match.Effect)
+	if err != nil {
+		return PolicyExplanation{}, err
+	}
+
+	explanation := PolicyExplanation{
+		PolicyId:      match.PolicyId,
+		Effect:        effect,
+		Action:        action.Action(match.Action),
+		SubjectChain:  []subject.Subject{},
+		ResourceChain: []resource.Resource{},
+		Matches:       []SpecifierMatch{},
+		FailedKeys:    []specifier.Specifier{},
+	}
+
+	for _, s := range match.SubjectChain {
+		subjectType, err := subject.SubjectTypeFromString(s.Type)
+		if err != nil {
+			return PolicyExplanation{}, err
+		}
+		explanation.SubjectChain = append(explanation.SubjectChain, subject.Subject{Name: s.Name, Type: subjectType})
+	}
+
+	for _, r := range match.ResourceChain {
+		explanation.ResourceChain = append(explanation.ResourceChain, resource.Resource{Name: r.Name})
+	}
+
+	keys := make([]string, 0, len(match.InputSpecifiers))
+	for k := range match.InputSpecifiers {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		input := specifier.NewSpecifier(k, match.InputSpecifiers[k])
+		if matched, exists := match.Specifiers[k]; exists {
+			explanation.Matches = append(explanation.Matches, SpecifierMatch{Input: input, MatchedThrough: specifier.NewSpecifier(matched.Key, matched.Value)})
+		} else {
+			explanation.FailedKeys = append(explanation.FailedKeys, input)
+		}
+	}
+
+	return explanation, nil
+}
+
+func (e PolicyExplanation) Pretty() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Policy %s (%s %s)\n", e.PolicyId, e.Effect, e.Action)
+
+	subjectNames := []string{}
+	for _, s := range e.SubjectChain {
+		subjectNames = append(subjectNames, fmt.Sprintf("%s(%s)", s.Name, s.Type))
+	}
+	fmt.Fprintf(&sb, "  subject:  %s\n", strings.Join(subjectNames, " -> "))
+
+	resourceNames := []string{}
+	for _, r := range e.ResourceChain {
+		resourceNames = append(resourceNames, r.Name)
+	}
+	fmt.Fprintf(&sb, "  resource: %s\n", strings.Join(resourceNames, " -> "))
+
+	for _, m := range e.Matches {
+		fmt.Fprintf(&sb, "  %s=%s matched through %s=%s\n", m.Input.Key, m.Input.Value, m.MatchedThrough.Key, m.MatchedThrough.Value)
+	}
+	for _, f := range e.FailedKeys {
+		fmt.Fprintf(&sb, "  %s=%s did not match\n", f.Key, f.Value)
+	}
+
+	return sb.String()
+}
+
+func (e CanExplanation) Pretty() string {
+	red := color.New(color.FgRed, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+
+	var sb strings.Builder
+	if e.Can {
+		sb.WriteString(green.Sprint(e.Can))
+	} else {
+		sb.WriteString(red.Sprint(e.Can))
+	}
+	sb.WriteString("\n")
+
+	sections := []struct {
+		title    string
+		policies []PolicyExplanation
+	}{
+		{"Granted by", e.Granting},
+		{"Denied by", e.Denying},
+		{"Not matching", e.Unmatched},
+	}
+	for _, section := range sections {
+		if len(section.policies) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", section.title)
+		for _, p := range section.policies {
+			sb.WriteString(p.Pretty())
+		}
+	}
+
+	if len(e.Granting)+len(e.Denying)+len(e.Unmatched) == 0 {
+		sb.WriteString("\nNo policy is held by the subject or its groups on the resource or its parents for this action\n")
+	}
+
+	return sb.String()
+}
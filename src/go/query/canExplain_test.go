@@ -0,0 +1,136 @@
+package query_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestCanExplain(t *testing.T) {
+	db.TestContainer(t)
+
+	testCanExplain(t)
+}
+
+func TestCanExplainInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testCanExplain(t)
+}
+
+type explainedPolicy struct {
+	subjectChain  []string
+	resourceChain []string
+	matches       map[string]string
+	failedKeys    []string
+}
+
+func summarizeExplanations(explanations []query.PolicyExplanation) []explainedPolicy {
+	summaries := []explainedPolicy{}
+	for _, e := range explanations {
+		summary := explainedPolicy{subjectChain: []string{}, resourceChain: []string{}, matches: map[string]string{}, failedKeys: []string{}}
+		for _, s := range e.SubjectChain {
+			summary.subjectChain = append(summary.subjectChain, s.Name)
+		}
+		for _, r := range e.ResourceChain {
+			summary.resourceChain = append(summary.resourceChain, r.Name)
+		}
+		for _, m := range e.Matches {
+			summary.matches[m.Input.Key+"="+m.Input.Value] = m.MatchedThrough.Key + "=" + m.MatchedThrough.Value
+		}
+		for _, f := range e.FailedKeys {
+			summary.failedKeys = append(summary.failedKeys, f.Key+"="+f.Value)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func testCanExplain(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	p3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}
+
+	r1 := resource.Resource{Name: "Resource1"}
+	r2 := resource.Resource{Name: "Resource2"}
+	r4 := resource.Resource{Name: "Resource4"}
+
+	envProd := specifier.NewSpecifier("Env", "prod")
+	envDev := specifier.NewSpecifier("Env", "dev")
+
+	testCases := []struct {
+		name       string
+		subject    subject.Subject
+		action     action.Action
+		resource   resource.Resource
+		specifiers []specifier.Specifier
+		can        bool
+		granting   []explainedPolicy
+		unmatched  []explainedPolicy
+	}{
+		{
+			"p1 READ r1 through Group1", p1, action.ActionRead, r1, nil, true,
+			[]explainedPolicy{{[]string{"Principal1", "Group1"}, []string{"Resource1"}, map[string]string{"Env=*": "Env=*", "Role=*": "Role=*"}, []string{}}},
+			[]explainedPolicy{},
+		},
+		{
+			"p1 READ r2 through Group2", p1, action.ActionRead, r2, nil, true,
+			[]explainedPolicy{{[]string{"Principal1", "Group1", "Group2"}, []string{"Resource2"}, map[string]string{"Env=*": "Env=*", "Role=*": "Role=*"}, []string{}}},
+			[]explainedPolicy{},
+		},
+		{
+			"p1 READ r4 in prod through Resource3", p1, action.ActionRead, r4, []specifier.Specifier{envProd}, true,
+			[]explainedPolicy{{[]string{"Principal1"}, []string{"Resource4", "Resource3"}, map[string]string{"Env=prod": "Env=prod", "Role=*": "Role=*"}, []string{}}},
+			[]explainedPolicy{},
+		},
+		{
+			"p1 READ r4 in dev fails on Env", p1, action.ActionRead, r4, []specifier.Specifier{envDev}, false,
+			[]explainedPolicy{},
+			[]explainedPolicy{{[]string{"Principal1"}, []string{"Resource4", "Resource3"}, map[string]string{"Role=*": "Role=*"}, []string{"Env=dev"}}},
+		},
+		{
+			"p3 READ r1 without role fails on Role", p3, action.ActionRead, r1, nil, false,
+			[]explainedPolicy{},
+			[]explainedPolicy{{[]string{"Principal3"}, []string{"Resource1", "_"}, map[string]string{"Env=*": "Env=*"}, []string{"Role=*"}}},
+		},
+		{
+			"p1 WRITE r1 has no candidates", p1, action.ActionWrite, r1, nil, false,
+			[]explainedPolicy{},
+			[]explainedPolicy{},
+		},
+	}
+
+	for _, tc := range testCases {
+		explanation, err := query.Can(tc.subject).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Explain(ctx)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", tc.name, err)
+			continue
+		}
+
+		if explanation.Can != tc.can {
+			t.Errorf("For %s, expected Can %v, but got %v", tc.name, tc.can, explanation.Can)
+		}
+		if granting := summarizeExplanations(explanation.Granting); !reflect.DeepEqual(granting, tc.granting) {
+			t.Errorf("For %s, expected granting %v, but got %v", tc.name, tc.granting, granting)
+		}
+		if unmatched := summarizeExplanations(explanation.Unmatched); !reflect.DeepEqual(unmatched, tc.unmatched) {
+			t.Errorf("For %s, expected unmatched %v, but got %v", tc.name, tc.unmatched, unmatched)
+		}
+		if len(explanation.Denying) != 0 {
+			t.Errorf("For %s, expected no denying policies, but got %v", tc.name, explanation.Denying)
+		}
+
+		// Explain must agree with Query
+		if result := query.Can(tc.subject).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query(ctx); result.Can != explanation.Can {
+			t.Errorf("For %s, Query returned %v but Explain returned %v", tc.name, result.Can, explanation.Can)
+		}
+	}
+}
@@ -12,14 +12,20 @@ import (
 )
 
 func TestCanQueries(t *testing.T) {
-	ctx, container := db.TestContainer()
-	// Ensure the container is terminated after the test finishes
-	defer func() {
-		container.Terminate(ctx)
-	}()
+	db.TestContainer(t)
 
-	query.DeleteEverything()
-	query.SetupTestState()
+	testCanQueries(t)
+}
+
+func TestCanQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testCanQueries(t)
+}
+
+func testCanQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
 
 	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
 	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
@@ -68,7 +74,7 @@ func TestCanQueries(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := query.Can(tc.subject).Perform(tc.action).On(tc.resource).With(tc.specifiers).Query()
+			result := query.Can(tc.subject).Perform(tc.action).On(tc.resource).With(tc.specifiers).Query(ctx)
 			if result.Err != nil {
 				t.Errorf("Unexpected error for %s: %v", tc.name, result.Err)
 				return
@@ -78,4 +84,33 @@ func TestCanQueries(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("batch", func(t *testing.T) {
+		queries := []query.CanQueryBuilder{}
+		for _, tc := range testCases {
+			queries = append(queries, query.Can(tc.subject).Perform(tc.action).On(tc.resource).With(tc.specifiers))
+		}
+		// An incomplete query in the middle must only fail its own result
+		invalidIdx := len(queries) / 2
+		queries = append(queries[:invalidIdx], append([]query.CanQueryBuilder{query.Can(p1).On(r1)}, queries[invalidIdx:]...)...)
+
+		results := query.CanBatch(ctx, queries)
+		if len(results) != len(queries) {
+			t.Fatalf("Expected %d results, but got %d", len(queries), len(results))
+		}
+		if results[invalidIdx].Err == nil {
+			t.Errorf("Expected an error for the incomplete query, but got %v", results[invalidIdx])
+		}
+
+		results = append(results[:invalidIdx], results[invalidIdx+1:]...)
+		for i, tc := range testCases {
+			if results[i].Err != nil {
+				t.Errorf("Unexpected error for %s: %v", tc.name, results[i].Err)
+				continue
+			}
+			if results[i].Can != tc.expected {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, results[i].Can)
+			}
+		}
+	})
 }
@@ -0,0 +1,86 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestCancel(t *testing.T) {
+	db.TestContainer(t)
+
+	testCancel(t)
+
+	t.Run("Slow query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := db.ExecuteQuery(ctx, "CALL apoc.util.sleep(10000)", nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected the query to stop at the deadline, took %v", elapsed)
+		}
+	})
+}
+
+func TestCancelInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testCancel(t)
+}
+
+func testCancel(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	r1 := resource.Resource{Name: "Resource1"}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	t.Run("Queries", func(t *testing.T) {
+		if result := query.Can(p1).Perform(action.ActionRead).On(r1).Query(canceled); !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Can: expected %v, got %v", context.Canceled, result.Err)
+		}
+		if _, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r1).Query(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("WhoCan: expected %v, got %v", context.Canceled, err)
+		}
+		if _, err := query.WhatCan(p1).Perform(action.ActionRead).Under(resource.Resource{Name: "_"}).Query(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("WhatCan: expected %v, got %v", context.Canceled, err)
+		}
+		howCan := query.HowCan(p1).Perform(action.ActionRead).On(r1).With(specifier.SpecifierGroup{})
+		if _, err := howCan.Query(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("HowCan: expected %v, got %v", context.Canceled, err)
+		}
+	})
+
+	t.Run("Mutations", func(t *testing.T) {
+		if _, err := (subject.Subject{Name: "Principal9", Type: subject.SubjectTypePrincipal}).Create(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("Create: expected %v, got %v", context.Canceled, err)
+		}
+		if _, err := subject.Get(ctx, "Principal9"); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected the canceled Create not to run, got %v", err)
+		}
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+
+		if _, err := subject.Get(expired, "Principal1"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
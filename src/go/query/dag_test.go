@@ -0,0 +1,121 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestResourceDagQueries(t *testing.T) {
+	db.TestContainer(t)
+
+	testResourceDagQueries(t)
+}
+
+func TestResourceDagQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testResourceDagQueries(t)
+}
+
+// testResourceDagQueries covers a diamond of resources: Doc lives in Folder and is shared into
+// Project, which both sit under Drive.
+func testResourceDagQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	drive, _ := resource.Resource{Name: "Drive"}.Create(ctx)
+	folder, _ := resource.Resource{Name: "Folder"}.CreateAsChildOf(ctx, drive)
+	project, _ := resource.Resource{Name: "Project"}.CreateAsChildOf(ctx, drive)
+	doc, _ := resource.Resource{Name: "Doc"}.CreateAsChildOf(ctx, folder)
+	if err := doc.AttachTo(ctx, project); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alice, _ := subject.Subject{Name: "Alice", Type: subject.SubjectTypePrincipal}.Create(ctx)
+	bob, _ := subject.Subject{Name: "Bob", Type: subject.SubjectTypePrincipal}.Create(ctx)
+	carol, _ := subject.Subject{Name: "Carol", Type: subject.SubjectTypePrincipal}.Create(ctx)
+
+	for _, p := range []policy.Policy{
+		{Subject: alice, Resource: folder, Action: action.ActionRead},
+		{Subject: bob, Resource: project, Action: action.ActionRead},
+		{Subject: carol, Resource: drive, Action: action.ActionRead},
+	} {
+		if _, err := p.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error creating %v: %v", p, err)
+		}
+	}
+
+	t.Run("Parents", func(t *testing.T) {
+		parents, err := doc.Parents(ctx)
+		if expected := []resource.Resource{folder, project}; err != nil || !reflect.DeepEqual(parents, expected) {
+			t.Errorf("Expected %v, got %v, %v", expected, parents, err)
+		}
+	})
+
+	t.Run("Can", func(t *testing.T) {
+		for _, s := range []subject.Subject{alice, bob, carol} {
+			if result := query.Can(s).Perform(action.ActionRead).On(doc).Query(ctx); result.Err != nil || !result.Can {
+				t.Errorf("Expected %s to READ Doc, got %v, %v", s.Name, result.Can, result.Err)
+			}
+		}
+		if query.Can(alice).Perform(action.ActionRead).On(project).Query(ctx).Can {
+			t.Errorf("Expected Alice not to READ Project")
+		}
+	})
+
+	t.Run("WhoCan", func(t *testing.T) {
+		principals, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(doc).Query(ctx)
+		if expected := []subject.Subject{alice, bob, carol}; err != nil || !reflect.DeepEqual(principals, expected) {
+			t.Errorf("Expected %v once each, got %v, %v", expected, principals, err)
+		}
+	})
+
+	t.Run("WhatCan", func(t *testing.T) {
+		resources, err := query.WhatCan(carol).Perform(action.ActionRead).Under(drive).Query(ctx)
+		if expected := []resource.Resource{doc, drive, folder, project}; err != nil || !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v once each, got %v, %v", expected, resources, err)
+		}
+
+		// Bob reaches Doc through Project, which is not under Folder
+		resources, err = query.WhatCan(bob).Perform(action.ActionRead).Under(folder).Query(ctx)
+		if expected := []resource.Resource{doc}; err != nil || !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v, got %v, %v", expected, resources, err)
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		if err := drive.AttachTo(ctx, doc); !errors.Is(err, resource.ErrResourceCycle) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceCycle, err)
+		}
+		if err := doc.AttachTo(ctx, doc); !errors.Is(err, resource.ErrResourceCycle) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceCycle, err)
+		}
+		if err := doc.AttachTo(ctx, resource.NewResource("Nowhere")); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceNotFound, err)
+		}
+	})
+
+	t.Run("Detach", func(t *testing.T) {
+		if err := doc.DetachFrom(ctx, project); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := doc.DetachFrom(ctx, project); !errors.Is(err, resource.ErrNotAParent) {
+			t.Errorf("Expected %v, got %v", resource.ErrNotAParent, err)
+		}
+
+		if query.Can(bob).Perform(action.ActionRead).On(doc).Query(ctx).Can {
+			t.Errorf("Expected Bob to lose READ on Doc once detached from Project")
+		}
+		if !query.Can(alice).Perform(action.ActionRead).On(doc).Query(ctx).Can {
+			t.Errorf("Expected Alice to keep READ on Doc through Folder")
+		}
+	})
+}
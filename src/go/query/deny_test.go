@@ -0,0 +1,158 @@
+package query_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestDenyPolicies(t *testing.T) {
+	db.TestContainer(t)
+
+	testDenyPolicies(t)
+}
+
+func TestDenyPoliciesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testDenyPolicies(t)
+}
+
+func testDenyPolicies(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
+	p3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}
+	rRoot := resource.Resource{Name: "_"}
+	r1 := resource.Resource{Name: "Resource1"}
+	r3 := resource.Resource{Name: "Resource3"}
+	roleAdmin := specifier.NewSpecifier("Role", "admin")
+	roleUser := specifier.NewSpecifier("Role", "user")
+	envProd := specifier.NewSpecifier("Env", "prod")
+
+	denyPolicies := []policy.Policy{
+		// Carves Principal1 out of the Group1 grant on Resource1
+		{Subject: p1, Resource: r1, Action: action.ActionRead, Effect: policy.EffectDeny},
+		// Carves Resource3, and its child Resource4, out of the Principal3 grant on the root resource
+		{Subject: p3, Resource: r3, Action: action.ActionRead, Effect: policy.EffectDeny},
+		// Carves the user role out of the Principal2 admin grant on Resource3
+		{Subject: p2, Resource: r3, Action: action.ActionRead, Effect: policy.EffectDeny, Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{roleUser}}},
+	}
+	for _, p := range denyPolicies {
+		created, err := p.Create(ctx)
+		if err != nil || created.Id == "" || created.Effect != policy.EffectDeny {
+			t.Fatalf("Failed to create deny policy %v: %v", p, err)
+		}
+	}
+
+	t.Run("Can", func(t *testing.T) {
+		testCases := []struct {
+			name       string
+			subject    subject.Subject
+			resource   resource.Resource
+			specifiers []specifier.Specifier
+			expected   bool
+		}{
+			{"p1 READ r1 denied over group grant", p1, r1, nil, false},
+			{"p2 READ r1 unaffected", p2, r1, nil, true},
+			{"p3 READ r1 as admin unaffected", p3, r1, []specifier.Specifier{roleAdmin}, true},
+			{"p3 READ r3 as admin denied", p3, r3, []specifier.Specifier{roleAdmin}, false},
+			{"p2 READ r3 as admin in prod", p2, r3, []specifier.Specifier{roleAdmin, envProd}, true},
+			{"p2 READ r3 as user in prod denied", p2, r3, []specifier.Specifier{roleUser, envProd}, false},
+		}
+
+		for _, tc := range testCases {
+			result := query.Can(tc.subject).Perform(action.ActionRead).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query(ctx)
+			if result.Err != nil || result.Can != tc.expected {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, result)
+			}
+		}
+	})
+
+	t.Run("WhoCan", func(t *testing.T) {
+		subjects, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r1).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []subject.Subject{p2}
+		if !reflect.DeepEqual(subjects, expected) {
+			t.Errorf("Expected %v, but got %v", expected, subjects)
+		}
+	})
+
+	t.Run("WhatCan", func(t *testing.T) {
+		resources, err := query.WhatCan(p3).Perform(action.ActionRead).Under(rRoot).With(specifier.SpecifierGroup{Specifiers: []specifier.Specifier{roleAdmin}}).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names := []string{}
+		for _, r := range resources {
+			names = append(names, r.Name)
+		}
+		slices.Sort(names)
+		expected := []string{"Resource1", "Resource2", "_"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected %v, but got %v", expected, names)
+		}
+	})
+
+	t.Run("WhatCanWithoutAllSpecifiers", func(t *testing.T) {
+		values := func(s subject.Subject, under resource.Resource, with specifier.Specifier) map[string]map[string][]string {
+			t.Helper()
+			resources, err := query.WhatCan(s).Perform(action.ActionRead).Under(under).With(specifier.SpecifierGroup{Specifiers: []specifier.Specifier{with}}).QueryWithoutAllSpecifiers(ctx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result := map[string]map[string][]string{}
+			for r, specMap := range resources {
+				result[r.Name] = map[string][]string{}
+				for key, specifiers := range specMap {
+					for _, sp := range specifiers {
+						result[r.Name][key] = append(result[r.Name][key], sp.Value)
+					}
+					slices.Sort(result[r.Name][key])
+				}
+			}
+			return result
+		}
+
+		// The DENY on Resource3 drops it and its child
+		envValues := map[string][]string{"Env": {"*", "dev", "prod"}}
+		expected := map[string]map[string][]string{"_": envValues, "Resource1": envValues, "Resource2": envValues}
+		if actual := values(p3, rRoot, roleAdmin); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+
+		// The DENY on the user role drops the value
+		roleValues := map[string][]string{"Role": {"admin"}}
+		expected = map[string]map[string][]string{"Resource3": roleValues, "Resource4": roleValues}
+		if actual := values(p2, r3, envProd); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("HowCan", func(t *testing.T) {
+		specifierGroups, err := query.HowCan(p2).Perform(action.ActionRead).On(r3).With(specifier.SpecifierGroup{Specifiers: []specifier.Specifier{envProd}}).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		groups := []map[string]string{}
+		for _, sg := range specifierGroups {
+			groups = append(groups, sg.AsMap())
+		}
+		expected := []map[string]string{{"Role": "admin"}}
+		if !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, but got %v", expected, groups)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestGroupQueries(t *testing.T) {
+	db.TestContainer(t)
+
+	testGroupQueries(t)
+}
+
+func TestGroupQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testGroupQueries(t)
+}
+
+// testGroupQueries covers a diamond of groups: Alice belongs to TeamA and TeamB, which both belong to Org.
+func testGroupQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	org, _ := subject.Subject{Name: "Org", Type: subject.SubjectTypeGroup}.Create(ctx)
+	teamA, _ := subject.Subject{Name: "TeamA", Type: subject.SubjectTypeGroup}.CreateAsChildOf(ctx, org)
+	teamB, _ := subject.Subject{Name: "TeamB", Type: subject.SubjectTypeGroup}.CreateAsChildOf(ctx, org)
+	alice, _ := subject.Subject{Name: "Alice", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(ctx, teamA)
+	if err := alice.AddToGroup(ctx, teamB); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	repo, _ := resource.Resource{Name: "Repo"}.Create(ctx)
+	repoDocs, _ := resource.Resource{Name: "RepoDocs"}.CreateAsChildOf(ctx, repo)
+
+	for _, p := range []policy.Policy{
+		{Subject: org, Resource: repo, Action: action.ActionRead},
+		{Subject: teamA, Resource: repoDocs, Action: action.ActionWrite},
+	} {
+		if _, err := p.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error creating %v: %v", p, err)
+		}
+	}
+
+	t.Run("Can", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			subject  subject.Subject
+			action   action.Action
+			resource resource.Resource
+			expected bool
+		}{
+			{"alice READ repoDocs through both teams", alice, action.ActionRead, repoDocs, true},
+			{"alice WRITE repoDocs through teamA", alice, action.ActionWrite, repoDocs, true},
+			{"teamB WRITE repoDocs", teamB, action.ActionWrite, repoDocs, false},
+			{"alice WRITE repo", alice, action.ActionWrite, repo, false},
+		}
+
+		for _, tc := range testCases {
+			result := query.Can(tc.subject).Perform(tc.action).On(tc.resource).Query(ctx)
+			if result.Err != nil || result.Can != tc.expected {
+				t.Errorf("For %s, expected %v, got %v, %v", tc.name, tc.expected, result.Can, result.Err)
+			}
+		}
+	})
+
+	t.Run("WhoCan", func(t *testing.T) {
+		principals, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(repoDocs).Query(ctx)
+		if expected := []subject.Subject{alice}; err != nil || !reflect.DeepEqual(principals, expected) {
+			t.Errorf("Expected %v once, got %v, %v", expected, principals, err)
+		}
+
+		groups, err := query.WhoCan(subject.SubjectTypeGroup).Perform(action.ActionRead).On(repoDocs).Query(ctx)
+		if expected := []subject.Subject{org, teamA, teamB}; err != nil || !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, got %v, %v", expected, groups, err)
+		}
+	})
+
+	t.Run("WhatCan", func(t *testing.T) {
+		resources, err := query.WhatCan(alice).Perform(action.ActionRead).Under(repo).Query(ctx)
+		if expected := []resource.Resource{repo, repoDocs}; err != nil || !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v once each, got %v, %v", expected, resources, err)
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		if err := org.AddToGroup(ctx, teamA); !errors.Is(err, subject.ErrGroupCycle) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", subject.ErrGroupCycle, err)
+		}
+		if err := org.AddToGroup(ctx, org); !errors.Is(err, subject.ErrGroupCycle) {
+			t.Errorf("Expected %v, got %v", subject.ErrGroupCycle, err)
+		}
+		if groups, _ := org.Groups(ctx); len(groups) != 0 {
+			t.Errorf("Expected the refused memberships not to be added, got %v", groups)
+		}
+	})
+
+	t.Run("Remove one path", func(t *testing.T) {
+		if err := alice.RemoveFromGroup(ctx, teamA); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !query.Can(alice).Perform(action.ActionRead).On(repoDocs).Query(ctx).Can {
+			t.Errorf("Expected alice to still READ repoDocs through teamB")
+		}
+		if query.Can(alice).Perform(action.ActionWrite).On(repoDocs).Query(ctx).Can {
+			t.Errorf("Expected alice to lose WRITE on repoDocs with teamA")
+		}
+
+		writers, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionWrite).On(repoDocs).Query(ctx)
+		if err != nil || len(writers) != 0 {
+			t.Errorf("Expected no principal to WRITE repoDocs, got %v, %v", writers, err)
+		}
+	})
+}
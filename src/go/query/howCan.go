@@ -1,10 +1,13 @@
 package query
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/namsnath/otter/action"
 	"github.com/namsnath/otter/db"
@@ -43,118 +46,51 @@ func (qb HowCanQueryBuilder) With(specifiers specifier.SpecifierGroup) HowCanQue
 
 func (qb HowCanQueryBuilder) Validate() (HowCanQueryBuilder, error) {
 	if qb.subject == (subject.Subject{}) || qb.action == "" || qb.resource == (resource.Resource{}) {
-		return qb, fmt.Errorf("incomplete HowCan query: subject, action, and resource must be set")
+		return qb, db.NewError(db.ErrInvalidInput, "incomplete HowCan query: subject, action, and resource must be set")
 	}
 
 	return qb, nil
 }
 
-func (qb HowCanQueryBuilder) Query() ([]specifier.SpecifierGroup, error) {
+func (qb HowCanQueryBuilder) Query(ctx context.Context) ([]specifier.SpecifierGroup, error) {
 	qb, validationError := qb.Validate()
 	if validationError != nil {
 		return []specifier.SpecifierGroup{}, validationError
 	}
-
-	query := `
-		MATCH (s:Subject {name: $subject, type: $subjectType})-[:CHILD_OF*0..]->(sParent)
-		MATCH (r:Resource {name: $resource})-[:CHILD_OF*0..]->(rParent)
-
-		MATCH (sParent)-[:HAS_POLICY]->(policy:Policy)<-[:HAS_POLICY]-(rParent)
-
-		// All the root specifiers for this policy, filtered by the required action
-		MATCH (policy)-[rel]->(rootSpec:Specifier)
-		WHERE type(rel) = $action
-
-		WITH policy, collect(rootSpec) AS policyRootSpecs
-
-		// If specifiers are provided, the policy is only valid if it covers ALL provided keys.
-		WHERE $specifiers IS NULL OR ALL(inputKey IN keys($specifiers) WHERE
-			ANY(pSpec IN policyRootSpecs WHERE
-				pSpec.key = inputKey AND
-				// Check: Is the Input Value a valid descendant of the Policy Specifier?
-				EXISTS {
-					MATCH (pSpec)<-[:CHILD_OF*0..]-(:Specifier {value: $specifiers[inputKey]})
-				}
-			)
-		)
-
-		// Get all specifiers from the policy
-		UNWIND policyRootSpecs AS rootSpec
-
-		// Exclude any specifiers that were provided in the input
-		WITH policy, rootSpec
-		WHERE $specifiers IS NULL OR NOT rootSpec.key IN keys($specifiers)
-
-		// Exapnd each root specifier to its valid children
-		CALL {
-			// Two WITH statements required to allow filtering on the imported rootSpec
-
-			WITH rootSpec
-			// Path A: It is a wildcard root, return only the root
-			WITH rootSpec
-			WHERE rootSpec.value = '*'
-			RETURN rootSpec AS finalSpec
-
-			UNION
-
-			WITH rootSpec
-			// Path B: It is specific, return the root and all children
-			WITH rootSpec
-			WHERE rootSpec.value <> '*'
-			MATCH (rootSpec)<-[:CHILD_OF*0..]-(childSpec)
-			RETURN childSpec AS finalSpec
-		}
-
-		RETURN
-			policy.id AS policyId,
-			finalSpec.key AS specifierKey,
-			collect(DISTINCT finalSpec.value) AS specifierVals
-	`
-
-	params := map[string]any{
-		"subject":     qb.subject.Name,
-		"subjectType": qb.subject.Type,
-		"action":      qb.action,
-		"resource":    qb.resource.Name,
-		"specifiers":  qb.specifiers,
+	specifiers, constraints, err := checkSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return []specifier.SpecifierGroup{}, err
 	}
 
-	if len(qb.specifiers) == 0 {
-		params["specifiers"] = nil
+	params := db.AccessQuery{
+		Subject:     qb.subject.Record(),
+		Action:      string(qb.action),
+		Resource:    qb.resource.Record(),
+		Specifiers:  specifiers,
+		Constraints: constraints,
 	}
 
-	result := db.ExecuteQuery(query, params)
+	start := time.Now()
+	policyValues, err := db.FromContext(ctx).HowCan(ctx, params)
+	if err != nil {
+		return []specifier.SpecifierGroup{}, err
+	}
 
 	specifierGroups := []specifier.SpecifierGroup{}
 	policyMap := map[string]map[string][]string{}
+	denyPolicies := []map[string][]string{}
 
-	for _, record := range result.Records {
-		policyIdVal, policyIdOk := record.Get("policyId")
-		specifierKeyVal, specifierKeyOk := record.Get("specifierKey")
-		specifierValsVal, specifierValsOk := record.Get("specifierVals")
-
-		if !policyIdOk || !specifierKeyOk || !specifierValsOk {
-			return []specifier.SpecifierGroup{}, fmt.Errorf("unexpected result format from HowCan query")
+	for policyId, expansion := range policyValues {
+		if expansion.Effect == db.EffectDeny {
+			denyPolicies = append(denyPolicies, expansion.Specifiers)
+			continue
 		}
 
-		policyStr, policyStrOk := policyIdVal.(string)
-		specifierKey, specifierKeyOk := specifierKeyVal.(string)
-		specifierVals, specifierValsOk := specifierValsVal.([]any)
-
-		if !policyStrOk || !specifierKeyOk || !specifierValsOk {
-			return []specifier.SpecifierGroup{}, fmt.Errorf("unexpected result types from HowCan query")
-		}
-
-		if _, exists := policyMap[policyStr]; !exists {
-			policyMap[policyStr] = map[string][]string{}
-		}
-		policyMap[policyStr][specifierKey] = []string{}
-
-		for _, val := range specifierVals {
-			if valStr, valStrOk := val.(string); valStrOk {
-				policyMap[policyStr][specifierKey] = append(policyMap[policyStr][specifierKey], fmt.Sprintf("%s=%s", specifierKey, valStr))
-			} else {
-				return []specifier.SpecifierGroup{}, fmt.Errorf("unexpected specifier value type from HowCan query")
+		policyMap[policyId] = map[string][]string{}
+		for specifierKey, specifierVals := range expansion.Specifiers {
+			policyMap[policyId][specifierKey] = []string{}
+			for _, valStr := range specifierVals {
+				policyMap[policyId][specifierKey] = append(policyMap[policyId][specifierKey], fmt.Sprintf("%s=%s", specifierKey, valStr))
 			}
 		}
 	}
@@ -176,7 +112,9 @@ func (qb HowCanQueryBuilder) Query() ([]specifier.SpecifierGroup, error) {
 
 		combinations := utils.CartesianProduct(specifierLists)
 		for _, combination := range combinations {
-			uniqueGroups.Add(strings.Join(combination, ","))
+			if !isDenied(combination, denyPolicies) {
+				uniqueGroups.Add(strings.Join(combination, ","))
+			}
 		}
 	}
 
@@ -200,9 +138,40 @@ func (qb HowCanQueryBuilder) Query() ([]specifier.SpecifierGroup, error) {
 		"resource", qb.resource,
 		"specifiers", qb.specifiers,
 		"specifierGroups", specifierGroups,
-		"duration", result.Summary.ResultAvailableAfter(),
-		"rows", len(result.Records),
+		"duration", time.Since(start),
+		"rows", len(policyValues),
 	)
 
 	return specifierGroups, nil
 }
+
+// isDenied reports whether a DENY policy covers every key of the `key=value` combination.
+// A DENY policy covers a key if it has the wildcard, or the value itself, for the key.
+func isDenied(combination []string, denyPolicies []map[string][]string) bool {
+	values := map[string]string{}
+	for _, pair := range combination {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			values[kv[0]] = kv[1]
+		}
+	}
+
+	for _, denied := range denyPolicies {
+		covered := true
+		for key, deniedValues := range denied {
+			if slices.Contains(deniedValues, "*") {
+				continue
+			}
+			value, exists := values[key]
+			if !exists || !slices.Contains(deniedValues, value) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package query_test
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"reflect"
@@ -17,31 +18,40 @@ import (
 	"github.com/namsnath/otter/subject"
 )
 
-func deletePolicies() {
-	db.ExecuteQuery("MATCH (p:Policy) DETACH DELETE p", nil)
+func deletePolicies(ctx context.Context) {
+	policies, _ := policy.Policy{}.Get(ctx)
+	for _, p := range policies {
+		p.Delete(ctx)
+	}
 }
 
 func TestHowCanQuery(t *testing.T) {
-	ctx, container := db.TestContainer()
-	// Ensure the container is terminated after the test finishes
-	defer func() {
-		container.Terminate(ctx)
-	}()
-
-	query.DeleteEverything()
-	query.SetupIndexes()
-
-	g1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.Create()
-	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(g1)
-	rRoot := resource.Resource{Name: "_"}.Create()
-	r1 := resource.Resource{Name: "Resource1"}.CreateAsChildOf(rRoot)
-	rootSpecifier := specifier.NewSpecifier("*", "*").Create()
-	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(rootSpecifier)
-	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(envRoot)
-	envDev, _ := specifier.NewSpecifier("Env", "dev").CreateAsChildOf(envRoot)
-	roleRoot, _ := specifier.NewSpecifier("Role", "*").CreateAsChildOf(rootSpecifier)
-	roleAdmin, _ := specifier.NewSpecifier("Role", "admin").CreateAsChildOf(roleRoot)
-	roleUser, _ := specifier.NewSpecifier("Role", "user").CreateAsChildOf(roleAdmin)
+	db.TestContainer(t)
+
+	testHowCanQuery(t)
+}
+
+func TestHowCanQueryInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testHowCanQuery(t)
+}
+
+func testHowCanQuery(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupIndexes(ctx)
+
+	g1, _ := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.Create(ctx)
+	p1, _ := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(ctx, g1)
+	rRoot, _ := resource.Resource{Name: "_"}.Create(ctx)
+	r1, _ := resource.Resource{Name: "Resource1"}.CreateAsChildOf(ctx, rRoot)
+	rootSpecifier, _ := specifier.NewSpecifier("*", "*").Create(ctx)
+	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(ctx, rootSpecifier)
+	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(ctx, envRoot)
+	envDev, _ := specifier.NewSpecifier("Env", "dev").CreateAsChildOf(ctx, envRoot)
+	roleRoot, _ := specifier.NewSpecifier("Role", "*").CreateAsChildOf(ctx, rootSpecifier)
+	roleAdmin, _ := specifier.NewSpecifier("Role", "admin").CreateAsChildOf(ctx, roleRoot)
+	roleUser, _ := specifier.NewSpecifier("Role", "user").CreateAsChildOf(ctx, roleAdmin)
 
 	p1R1AdminDevPolicy := policy.Policy{
 		Subject:    p1,
@@ -190,15 +200,15 @@ func TestHowCanQuery(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			deletePolicies()
+			deletePolicies(ctx)
 			for _, p := range tc.policies {
-				_, err := p.Create()
+				_, err := p.Create(ctx)
 				if err != nil {
 					slog.Error("Error creating policy", "policy", p, "error", err)
 				}
 			}
 
-			result, err := query.HowCan(tc.subject).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query()
+			result, err := query.HowCan(tc.subject).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query(ctx)
 
 			if err != nil {
 				t.Errorf("Unexpected error for %s: %v", tc.name, err)
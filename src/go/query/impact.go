@@ -0,0 +1,295 @@
+package query
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+// Access is a subject allowed to perform an action on a resource under a set of specifiers,
+// as returned by HowCan. The specifiers are sorted by key.
+type Access struct {
+	Subject    subject.Subject
+	Action     action.Action
+	Resource   resource.Resource
+	Specifiers specifier.SpecifierGroup
+}
+
+func (a Access) String() string {
+	description := fmt.Sprintf("%s %s %s", a.Subject.Name, a.Action, a.Resource.Name)
+
+	specifiers := []string{}
+	for _, s := range a.Specifiers.Specifiers {
+		if s.Value != "*" {
+			specifiers = append(specifiers, s.Key+"="+s.Value)
+		}
+	}
+	if len(specifiers) > 0 {
+		description += " with " + strings.Join(specifiers, ",")
+	}
+	return description
+}
+
+func compareAccesses(a, b Access) int {
+	return cmp.Or(
+		cmp.Compare(a.Subject.Name, b.Subject.Name),
+		cmp.Compare(a.Action, b.Action),
+		cmp.Compare(a.Resource.Name, b.Resource.Name),
+		cmp.Compare(a.String(), b.String()),
+	)
+}
+
+// Impact lists the accesses a change would add and remove, sorted by subject, action and resource.
+type Impact struct {
+	Gained []Access
+	Lost   []Access
+}
+
+// Empty reports whether the change leaves every access as it is.
+func (i Impact) Empty() bool {
+	return len(i.Gained) == 0 && len(i.Lost) == 0
+}
+
+func (i Impact) String() string {
+	var b strings.Builder
+	for _, a := range i.Gained {
+		fmt.Fprintf(&b, "+ %s\n", a)
+	}
+	for _, a := range i.Lost {
+		fmt.Fprintf(&b, "- %s\n", a)
+	}
+	return b.String()
+}
+
+// errDryRun rolls back the transaction of a preview.
+var errDryRun = errors.New("dry run")
+
+// ResourceMoveImpact previews Resource.MoveTo: the accesses to the resource and everything below
+// it that moving it under the parent would add or remove. Nothing is changed.
+func ResourceMoveImpact(ctx context.Context, r resource.Resource, parent resource.Resource) (Impact, error) {
+	start := time.Now()
+	g, err := readHierarchy(ctx)
+	if err != nil {
+		return Impact{}, err
+	}
+
+	// Only the holders of policies on the old or new ancestors can gain or lose access
+	ancestors := walk(g.resourceParents, r.Name, parent.Name)
+	holders := []string{}
+	for _, p := range g.policies {
+		if slices.Contains(ancestors, p.Resource.Name) {
+			holders = append(holders, p.Subject.Name)
+		}
+	}
+
+	subjects := g.subjects(walk(invertEdges(g.subjectParents), holders...))
+	resources := g.resources(walk(invertEdges(g.resourceParents), r.Name))
+
+	impact, err := previewImpact(ctx, subjects, resources, func(ctx context.Context) error {
+		return r.MoveTo(ctx, parent)
+	})
+	slog.Info("ResourceMoveImpact", "resource", r, "parent", parent, "gained", len(impact.Gained), "lost", len(impact.Lost), "duration", time.Since(start))
+	return impact, err
+}
+
+// SubjectMoveImpact previews Subject.MoveTo: the accesses of the subject and its members that
+// moving it into the group would add or remove. Nothing is changed.
+func SubjectMoveImpact(ctx context.Context, s subject.Subject, group subject.Subject) (Impact, error) {
+	start := time.Now()
+	g, err := readHierarchy(ctx)
+	if err != nil {
+		return Impact{}, err
+	}
+
+	// Only the resources of policies held by the old or new groups can be gained or lost
+	ancestors := walk(g.subjectParents, s.Name, group.Name)
+	granted := []string{}
+	for _, p := range g.policies {
+		if slices.Contains(ancestors, p.Subject.Name) {
+			granted = append(granted, p.Resource.Name)
+		}
+	}
+
+	subjects := g.subjects(walk(invertEdges(g.subjectParents), s.Name))
+	resources := g.resources(walk(invertEdges(g.resourceParents), granted...))
+
+	impact, err := previewImpact(ctx, subjects, resources, func(ctx context.Context) error {
+		return s.MoveTo(ctx, group)
+	})
+	slog.Info("SubjectMoveImpact", "subject", s, "group", group, "gained", len(impact.Gained), "lost", len(impact.Lost), "duration", time.Since(start))
+	return impact, err
+}
+
+// previewImpact compares the accesses of the subjects to the resources before and after change,
+// which runs in a transaction that is rolled back.
+func previewImpact(ctx context.Context, subjects []subject.Subject, resources []resource.Resource, change func(ctx context.Context) error) (Impact, error) {
+	actions, err := action.List(ctx)
+	if err != nil {
+		return Impact{}, err
+	}
+
+	before, err := accesses(ctx, subjects, resources, actions)
+	if err != nil {
+		return Impact{}, err
+	}
+
+	var after []Access
+	err = db.InTx(ctx, func(ctx context.Context) error {
+		if err := change(ctx); err != nil {
+			return err
+		}
+		after, err = accesses(ctx, subjects, resources, actions)
+		if err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return Impact{}, err
+	}
+
+	return Impact{Gained: missingAccesses(after, before), Lost: missingAccesses(before, after)}, nil
+}
+
+// missingAccesses returns the accesses of a that are not in b.
+func missingAccesses(a []Access, b []Access) []Access {
+	existing := map[string]bool{}
+	for _, access := range b {
+		existing[access.String()] = true
+	}
+
+	result := []Access{}
+	for _, access := range a {
+		if !existing[access.String()] {
+			result = append(result, access)
+		}
+	}
+	return result
+}
+
+// accesses returns the sorted accesses of the subjects to the resources.
+func accesses(ctx context.Context, subjects []subject.Subject, resources []resource.Resource, actions []action.Action) ([]Access, error) {
+	result := []Access{}
+	for _, s := range subjects {
+		for _, a := range actions {
+			for _, r := range resources {
+				groups, err := HowCan(s).Perform(a).On(r).Query(ctx)
+				if err != nil {
+					return nil, err
+				}
+				for _, group := range groups {
+					group.Specifiers = slices.Clone(group.Specifiers)
+					slices.SortFunc(group.Specifiers, func(a, b specifier.Specifier) int { return cmp.Compare(a.Key, b.Key) })
+					result = append(result, Access{Subject: s, Action: a, Resource: r, Specifiers: group})
+				}
+			}
+		}
+	}
+	slices.SortFunc(result, compareAccesses)
+	return result, nil
+}
+
+// hierarchy holds the subject and resource graphs, by name, with every policy.
+type hierarchy struct {
+	subjectRecords  map[string]db.SubjectRecord
+	subjectParents  map[string][]string
+	resourceParents map[string][]string
+	policies        []db.PolicyRecord
+}
+
+func readHierarchy(ctx context.Context) (hierarchy, error) {
+	store := db.FromContext(ctx)
+	g := hierarchy{
+		subjectRecords:  map[string]db.SubjectRecord{},
+		subjectParents:  map[string][]string{},
+		resourceParents: map[string][]string{},
+	}
+
+	subjectNodes, err := store.GetSubjects(ctx)
+	if err != nil {
+		return hierarchy{}, err
+	}
+	for _, node := range subjectNodes {
+		g.subjectRecords[node.Subject.Name] = node.Subject
+		g.subjectParents[node.Subject.Name] = []string{}
+		for _, parent := range node.Parents {
+			g.subjectParents[node.Subject.Name] = append(g.subjectParents[node.Subject.Name], parent.Name)
+		}
+	}
+
+	resourceNodes, err := store.GetResources(ctx)
+	if err != nil {
+		return hierarchy{}, err
+	}
+	for _, node := range resourceNodes {
+		g.resourceParents[node.Resource.Name] = []string{}
+		for _, parent := range node.Parents {
+			g.resourceParents[node.Resource.Name] = append(g.resourceParents[node.Resource.Name], parent.Name)
+		}
+	}
+
+	g.policies, err = store.GetPolicies(ctx, db.PolicyFilter{})
+	if err != nil {
+		return hierarchy{}, err
+	}
+	return g, nil
+}
+
+func (g hierarchy) subjects(names []string) []subject.Subject {
+	subjects := []subject.Subject{}
+	for _, name := range names {
+		if record, exists := g.subjectRecords[name]; exists {
+			subjects = append(subjects, subject.Subject{Name: record.Name, Type: subject.SubjectType(record.Type)})
+		}
+	}
+	return subjects
+}
+
+func (g hierarchy) resources(names []string) []resource.Resource {
+	resources := []resource.Resource{}
+	for _, name := range names {
+		if _, exists := g.resourceParents[name]; exists {
+			resources = append(resources, resource.Resource{Name: name})
+		}
+	}
+	return resources
+}
+
+// walk returns the sorted names reachable from the start ones through the edges, including them.
+func walk(edges map[string][]string, start ...string) []string {
+	visited := map[string]bool{}
+	queue := slices.Clone(start)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, edges[current]...)
+	}
+	return slices.Sorted(maps.Keys(visited))
+}
+
+// invertEdges turns child to parents edges into parent to children ones.
+func invertEdges(edges map[string][]string) map[string][]string {
+	inverted := map[string][]string{}
+	for child, parents := range edges {
+		for _, parent := range parents {
+			inverted[parent] = append(inverted[parent], child)
+		}
+	}
+	return inverted
+}
@@ -0,0 +1,118 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestMoveImpact(t *testing.T) {
+	db.TestContainer(t)
+
+	testMoveImpact(t)
+}
+
+func TestMoveImpactInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testMoveImpact(t)
+}
+
+func accessStrings(accesses []query.Access) []string {
+	result := []string{}
+	for _, a := range accesses {
+		result = append(result, a.String())
+	}
+	return result
+}
+
+func testMoveImpact(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}
+	g1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}
+	g2 := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}
+	r1 := resource.NewResource("Resource1")
+	r3 := resource.NewResource("Resource3")
+	r4 := resource.NewResource("Resource4")
+
+	t.Run("Resource", func(t *testing.T) {
+		impact, err := query.ResourceMoveImpact(ctx, r4, r1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		gained := []string{"Group1 READ Resource4", "Principal1 READ Resource4", "Principal2 READ Resource4"}
+		if !reflect.DeepEqual(accessStrings(impact.Gained), gained) {
+			t.Errorf("Expected gained %v, got %v", gained, accessStrings(impact.Gained))
+		}
+		lost := []string{
+			"Principal1 READ Resource4 with Env=prod",
+			"Principal2 READ Resource4 with Env=prod,Role=admin",
+			"Principal2 READ Resource4 with Env=prod,Role=user",
+		}
+		if !reflect.DeepEqual(accessStrings(impact.Lost), lost) {
+			t.Errorf("Expected lost %v, got %v", lost, accessStrings(impact.Lost))
+		}
+
+		if parents, _ := r4.Parents(ctx); !reflect.DeepEqual(parents, []resource.Resource{r3}) {
+			t.Errorf("Expected the preview not to move the resource, got parents %v", parents)
+		}
+		if query.Can(g1).Perform(action.ActionRead).On(r4).Query(ctx).Can {
+			t.Errorf("Expected the preview not to grant Group1 READ on Resource4")
+		}
+
+		if err := r4.MoveTo(ctx, r1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !query.Can(g1).Perform(action.ActionRead).On(r4).Query(ctx).Can {
+			t.Errorf("Expected Group1 to READ Resource4 once moved")
+		}
+
+		if _, err := query.ResourceMoveImpact(ctx, r1, r4); !errors.Is(err, resource.ErrResourceCycle) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceCycle, err)
+		}
+	})
+
+	t.Run("Subject", func(t *testing.T) {
+		impact, err := query.SubjectMoveImpact(ctx, p3, g1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		gained := []string{"Principal3 READ Resource1", "Principal3 READ Resource2", "Principal3 READ Resource4"}
+		if !reflect.DeepEqual(accessStrings(impact.Gained), gained) || len(impact.Lost) != 0 {
+			t.Errorf("Expected only gained %v, got %v", gained, impact)
+		}
+		if groups, _ := p3.Groups(ctx); len(groups) != 0 {
+			t.Errorf("Expected the preview not to move the subject, got groups %v", groups)
+		}
+
+		if err := p3.MoveTo(ctx, g1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if groups, _ := p3.Groups(ctx); !reflect.DeepEqual(groups, []subject.Subject{g1}) {
+			t.Errorf("Expected %v, got %v", []subject.Subject{g1}, groups)
+		}
+
+		impact, err = query.SubjectMoveImpact(ctx, p3, g2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lost := []string{"Principal3 READ Resource1", "Principal3 READ Resource4"}
+		if !reflect.DeepEqual(accessStrings(impact.Lost), lost) || len(impact.Gained) != 0 {
+			t.Errorf("Expected only lost %v, got %v", lost, impact)
+		}
+
+		if _, err := query.SubjectMoveImpact(ctx, g2, g1); !errors.Is(err, subject.ErrGroupCycle) {
+			t.Errorf("Expected %v, got %v", subject.ErrGroupCycle, err)
+		}
+	})
+}
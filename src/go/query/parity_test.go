@@ -0,0 +1,175 @@
+package query_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/state"
+	"github.com/namsnath/otter/subject"
+)
+
+// TestStoreParity runs the same fixtures and queries against Neo4j and the memory store, and
+// expects the same answers from both.
+func TestStoreParity(t *testing.T) {
+	db.TestContainer(t)
+	neo4jResults := parityResults(t)
+
+	db.SetupMemoryInstance()
+	memoryResults := parityResults(t)
+
+	if len(neo4jResults) != len(memoryResults) {
+		t.Fatalf("Expected as many answers from both stores, got %d and %d", len(neo4jResults), len(memoryResults))
+	}
+	for _, name := range slices.Sorted(maps.Keys(neo4jResults)) {
+		if neo4jResults[name] != memoryResults[name] {
+			t.Errorf("For %s, Neo4j answered %s, but the memory store %s", name, neo4jResults[name], memoryResults[name])
+		}
+	}
+}
+
+// parityResults sets up the fixtures in the store of the instance, and returns the answer of every
+// query over them by description. Errors are only recorded as such, their messages being the store's.
+func parityResults(t *testing.T) map[string]string {
+	t.Helper()
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
+	p3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}
+	g1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}
+	g2 := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}
+	rRoot := resource.Resource{Name: "_"}
+	r1 := resource.Resource{Name: "Resource1"}
+	r2 := resource.Resource{Name: "Resource2"}
+	r3 := resource.Resource{Name: "Resource3"}
+	r4 := resource.Resource{Name: "Resource4"}
+	r5 := resource.Resource{Name: "Resource5"}
+	deploy := action.Action("DEPLOY")
+
+	// On top of the test state: a resource with two parents, an implied action and DENY policies
+	fixtures := []func() error{
+		func() error { _, err := deploy.Create(ctx); return err },
+		func() error { return deploy.Implies(ctx, action.ActionRead) },
+		func() error { _, err := r5.CreateAsChildOf(ctx, r2); return err },
+		func() error { return r5.AttachTo(ctx, r3) },
+	}
+	policies := []policy.Policy{
+		{Subject: g2, Resource: r2, Action: deploy, Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Env", "prod")}}},
+		{Subject: p1, Resource: r1, Action: action.ActionRead, Effect: policy.EffectDeny},
+		{Subject: p3, Resource: r3, Action: action.ActionRead, Effect: policy.EffectDeny},
+		{Subject: p2, Resource: r3, Action: action.ActionRead, Effect: policy.EffectDeny, Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Role", "user")}}},
+		{Subject: p2, Resource: r5, Action: deploy, Effect: policy.EffectDeny, Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Role", "user")}}},
+	}
+	for _, p := range policies {
+		fixtures = append(fixtures, func() error { _, err := p.Create(ctx); return err })
+	}
+	for i, fixture := range fixtures {
+		if err := fixture(); err != nil {
+			t.Fatalf("Unexpected error setting up fixture %d: %v", i, err)
+		}
+	}
+
+	subjects := []subject.Subject{p1, p2, p3, g1, g2}
+	resources := []resource.Resource{rRoot, r1, r2, r3, r4, r5}
+	actions := []action.Action{action.ActionRead, action.ActionWrite, deploy}
+	specifierSets := [][]specifier.Specifier{
+		{},
+		{specifier.NewSpecifier("Role", "admin")},
+		{specifier.NewSpecifier("Role", "user")},
+		{specifier.NewSpecifier("Role", "admin"), specifier.NewSpecifier("Env", "prod")},
+		{specifier.NewSpecifier("Role", "user"), specifier.NewSpecifier("Env", "prod")},
+		{specifier.NewSpecifier("Env", "dev")},
+		{specifier.NewSpecifier("Env", "qa")},
+	}
+
+	results := map[string]string{}
+	failed := 0
+	record := func(name string, result any, err error) {
+		if err != nil {
+			result = "error"
+			failed++
+		}
+		results[name] = fmt.Sprint(result)
+	}
+
+	for _, a := range actions {
+		for _, set := range specifierSets {
+			specifiers := specifier.SpecifierGroup{Specifiers: set}
+			with := fmt.Sprint(specifiers.AsMap())
+
+			for _, s := range subjects {
+				for _, r := range resources {
+					can := query.Can(s).Perform(a).On(r).With(specifiers).Query(ctx)
+					record(fmt.Sprintf("Can %s %s %s with %s", s.Name, a, r.Name, with), can.Can, can.Err)
+
+					howCan, err := query.HowCan(s).Perform(a).On(r).With(specifiers).Query(ctx)
+					groups := []string{}
+					for _, group := range howCan {
+						groups = append(groups, fmt.Sprint(group.AsMap()))
+					}
+					slices.Sort(groups)
+					record(fmt.Sprintf("HowCan %s %s %s with %s", s.Name, a, r.Name, with), groups, err)
+				}
+
+				whatCan, err := query.WhatCan(s).Perform(a).Under(rRoot).With(specifiers).Query(ctx)
+				names := []string{}
+				for _, r := range whatCan {
+					names = append(names, r.Name)
+				}
+				slices.Sort(names)
+				record(fmt.Sprintf("WhatCan %s %s with %s", s.Name, a, with), names, err)
+
+				expansions, err := query.WhatCan(s).Perform(a).Under(rRoot).With(specifiers).QueryWithoutAllSpecifiers(ctx)
+				values := map[string]map[string][]string{}
+				for r, specMap := range expansions {
+					values[r.Name] = map[string][]string{}
+					for key, specs := range specMap {
+						for _, sp := range specs {
+							values[r.Name][key] = append(values[r.Name][key], sp.Value)
+						}
+						slices.Sort(values[r.Name][key])
+					}
+				}
+				record(fmt.Sprintf("WhatCanWithoutAllSpecifiers %s %s with %s", s.Name, a, with), values, err)
+			}
+
+			for _, st := range []subject.SubjectType{subject.SubjectTypePrincipal, subject.SubjectTypeGroup} {
+				for _, r := range resources {
+					whoCan, err := query.WhoCan(st).Perform(a).On(r).With(specifiers).Query(ctx)
+					names := []string{}
+					for _, s := range whoCan {
+						names = append(names, s.Name)
+					}
+					slices.Sort(names)
+					record(fmt.Sprintf("WhoCan %s %s %s with %s", st, a, r.Name, with), names, err)
+				}
+			}
+		}
+	}
+
+	// Policy IDs are generated, so only the policies are compared
+	exported, err := state.Export(ctx)
+	exportedPolicies := []string{}
+	for _, p := range exported.Policies {
+		p.Id = ""
+		exportedPolicies = append(exportedPolicies, fmt.Sprintf("%+v", p))
+	}
+	slices.Sort(exportedPolicies)
+	exported.Policies = nil
+	record("Export", fmt.Sprintf("%+v %v", exported, exportedPolicies), err)
+
+	if failed == len(results) {
+		t.Fatalf("Expected some queries to succeed, got only errors")
+	}
+	return results
+}
@@ -1,7 +1,9 @@
 package query
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/namsnath/otter/action"
 	"github.com/namsnath/otter/db"
@@ -11,44 +13,98 @@ import (
 	"github.com/namsnath/otter/subject"
 )
 
-func DeleteEverything() {
-	result := db.ExecuteQuery(`MATCH (n) DETACH DELETE n`, nil)
+func DeleteEverything(ctx context.Context) error {
+	start := time.Now()
+	err := db.FromContext(ctx).DeleteEverything(ctx)
+	if err != nil {
+		return err
+	}
 	slog.Info(
 		"All nodes and relationships deleted",
-		slog.Any("duration", result.Summary.ResultAvailableAfter()),
+		slog.Any("duration", time.Since(start)),
 	)
+	return nil
 }
 
-func SetupIndexes() {
-	db.ExecuteQuery(`CREATE INDEX subject_name_index IF NOT EXISTS FOR (s:Subject) ON (s.name)`, nil)
-	db.ExecuteQuery(`CREATE INDEX subject_name_type_index IF NOT EXISTS FOR (s:Subject) ON (s.name, s.type)`, nil)
-	db.ExecuteQuery(`CREATE INDEX resource_name_index IF NOT EXISTS FOR (r:Resource) ON (r.name)`, nil)
-	db.ExecuteQuery(`CREATE INDEX specifier_key_value_index IF NOT EXISTS FOR (s:Specifier) ON (s.key, s.value)`, nil)
-	db.ExecuteQuery(`CREATE INDEX policy_id_index IF NOT EXISTS FOR (p:Policy) ON (p.id)`, nil)
+// SetupIndexes creates the uniqueness constraints and indexes of the store. Creating a subject,
+// resource, specifier or policy that already exists fails with an ErrConflict error.
+func SetupIndexes(ctx context.Context) error {
+	return db.FromContext(ctx).SetupIndexes(ctx)
 }
 
-func SetupTestState() {
-	SetupIndexes()
+// SetupTestState creates the subjects, resources, specifiers and policies used by the examples and tests.
+func SetupTestState(ctx context.Context) error {
+	if err := SetupIndexes(ctx); err != nil {
+		return err
+	}
 
-	g2 := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}.Create()
-	g1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.CreateAsChildOf(g2)
-	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(g1)
-	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(g2)
-	p3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}.Create()
+	g2, err := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}.Create(ctx)
+	if err != nil {
+		return err
+	}
+	g1, err := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}.CreateAsChildOf(ctx, g2)
+	if err != nil {
+		return err
+	}
+	p1, err := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(ctx, g1)
+	if err != nil {
+		return err
+	}
+	p2, err := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}.CreateAsChildOf(ctx, g2)
+	if err != nil {
+		return err
+	}
+	p3, err := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}.Create(ctx)
+	if err != nil {
+		return err
+	}
 
-	rRoot := resource.Resource{Name: "_"}.Create()
-	r1 := resource.Resource{Name: "Resource1"}.CreateAsChildOf(rRoot)
-	r2 := resource.Resource{Name: "Resource2"}.CreateAsChildOf(rRoot)
-	r3 := resource.Resource{Name: "Resource3"}.CreateAsChildOf(rRoot)
-	resource.Resource{Name: "Resource4"}.CreateAsChildOf(r3)
+	rRoot, err := resource.Resource{Name: "_"}.Create(ctx)
+	if err != nil {
+		return err
+	}
+	r1, err := resource.Resource{Name: "Resource1"}.CreateAsChildOf(ctx, rRoot)
+	if err != nil {
+		return err
+	}
+	r2, err := resource.Resource{Name: "Resource2"}.CreateAsChildOf(ctx, rRoot)
+	if err != nil {
+		return err
+	}
+	r3, err := resource.Resource{Name: "Resource3"}.CreateAsChildOf(ctx, rRoot)
+	if err != nil {
+		return err
+	}
+	if _, err := (resource.Resource{Name: "Resource4"}).CreateAsChildOf(ctx, r3); err != nil {
+		return err
+	}
 
-	rootSpecifier := specifier.NewSpecifier("*", "*").Create()
-	roleRoot, _ := specifier.NewSpecifier("Role", "*").CreateAsChildOf(rootSpecifier)
-	roleAdmin, _ := specifier.NewSpecifier("Role", "admin").CreateAsChildOf(roleRoot)
-	specifier.NewSpecifier("Role", "user").CreateAsChildOf(roleAdmin)
-	envRoot, _ := specifier.NewSpecifier("Env", "*").CreateAsChildOf(rootSpecifier)
-	envProd, _ := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(envRoot)
-	specifier.NewSpecifier("Env", "dev").CreateAsChildOf(envRoot)
+	rootSpecifier, err := specifier.NewSpecifier("*", "*").Create(ctx)
+	if err != nil {
+		return err
+	}
+	roleRoot, err := specifier.NewSpecifier("Role", "*").CreateAsChildOf(ctx, rootSpecifier)
+	if err != nil {
+		return err
+	}
+	roleAdmin, err := specifier.NewSpecifier("Role", "admin").CreateAsChildOf(ctx, roleRoot)
+	if err != nil {
+		return err
+	}
+	if _, err := specifier.NewSpecifier("Role", "user").CreateAsChildOf(ctx, roleAdmin); err != nil {
+		return err
+	}
+	envRoot, err := specifier.NewSpecifier("Env", "*").CreateAsChildOf(ctx, rootSpecifier)
+	if err != nil {
+		return err
+	}
+	envProd, err := specifier.NewSpecifier("Env", "prod").CreateAsChildOf(ctx, envRoot)
+	if err != nil {
+		return err
+	}
+	if _, err := specifier.NewSpecifier("Env", "dev").CreateAsChildOf(ctx, envRoot); err != nil {
+		return err
+	}
 
 	policies := []policy.Policy{
 		{
@@ -90,9 +146,9 @@ func SetupTestState() {
 	}
 
 	for _, p := range policies {
-		_, err := p.Create()
-		if err != nil {
-			slog.Error("Error creating policy", "error", err)
+		if _, err := p.Create(ctx); err != nil {
+			return err
 		}
 	}
+	return nil
 }
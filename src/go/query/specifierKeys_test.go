@@ -0,0 +1,145 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestSpecifierKeys(t *testing.T) {
+	db.TestContainer(t)
+
+	testSpecifierKeys(t)
+}
+
+func TestSpecifierKeysInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testSpecifierKeys(t)
+}
+
+func testSpecifierKeys(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	r4 := resource.Resource{Name: "Resource4"}
+	with := func(specifiers ...specifier.Specifier) specifier.SpecifierGroup {
+		return specifier.SpecifierGroup{Specifiers: specifiers}
+	}
+	prod := specifier.NewSpecifier("Env", "prod")
+
+	t.Run("Unknown keys and values", func(t *testing.T) {
+		for _, tc := range []struct {
+			name       string
+			specifiers specifier.SpecifierGroup
+			expected   error
+		}{
+			{"Typo in key", with(specifier.NewSpecifier("Evn", "prod")), specifier.ErrUnknownSpecifierKey},
+			{"Unknown value", with(specifier.NewSpecifier("Env", "staging")), specifier.ErrUnknownSpecifierValue},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				errs := map[string]error{
+					"Can": query.Can(p1).Perform(action.ActionRead).On(r4).With(tc.specifiers).Query(ctx).Err,
+					"CanBatch": query.CanBatch(ctx, []query.CanQueryBuilder{
+						query.Can(p1).Perform(action.ActionRead).On(r4).With(tc.specifiers),
+					})[0].Err,
+				}
+				_, errs["Explain"] = query.Can(p1).Perform(action.ActionRead).On(r4).With(tc.specifiers).Explain(ctx)
+				_, errs["WhoCan"] = query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r4).With(tc.specifiers).Query(ctx)
+				_, errs["WhatCan"] = query.WhatCan(p1).Perform(action.ActionRead).Under(r4).With(tc.specifiers).Query(ctx)
+				_, errs["HowCan"] = query.HowCan(p1).Perform(action.ActionRead).On(r4).With(tc.specifiers).Query(ctx)
+				_, errs["Policy.Create"] = policy.Policy{Subject: p1, Resource: r4, Action: action.ActionRead, Specifiers: tc.specifiers}.Create(ctx)
+
+				for name, err := range errs {
+					if !errors.Is(err, tc.expected) || !errors.Is(err, db.ErrInvalidInput) {
+						t.Errorf("%s: expected %v, got %v", name, tc.expected, err)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		region := specifier.SpecifierKey{Name: "Region", Description: "Where the data lives", Values: []string{"eu", "us"}, Required: true}
+		if _, err := region.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := region.Create(ctx); !errors.Is(err, specifier.ErrSpecifierKeyExists) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierKeyExists, err)
+		}
+		if _, err := (specifier.SpecifierKey{Name: "Tier", Values: []string{"gold"}, Default: "silver"}).Create(ctx); !errors.Is(err, specifier.ErrInvalidSpecifierKey) {
+			t.Errorf("Expected %v, got %v", specifier.ErrInvalidSpecifierKey, err)
+		}
+
+		// The key root and values are created along with the key, and other values are refused
+		values, _ := specifier.List(ctx, "Region")
+		expected := []specifier.Specifier{specifier.NewSpecifier("Region", "*"), specifier.NewSpecifier("Region", "eu"), specifier.NewSpecifier("Region", "us")}
+		if !reflect.DeepEqual(values, expected) {
+			t.Errorf("Expected %v, got %v", expected, values)
+		}
+		if _, err := specifier.NewSpecifier("Region", "apac").CreateAsChildOf(ctx, specifier.NewSpecifier("Region", "*")); !errors.Is(err, specifier.ErrUnknownSpecifierValue) {
+			t.Errorf("Expected %v, got %v", specifier.ErrUnknownSpecifierValue, err)
+		}
+
+		keys, err := specifier.ListKeys(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(keys, []specifier.SpecifierKey{region}) {
+			t.Errorf("Expected %v, got %v", []specifier.SpecifierKey{region}, keys)
+		}
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		if result := query.Can(p1).Perform(action.ActionRead).On(r4).With(with(prod)).Query(ctx); !errors.Is(result.Err, specifier.ErrMissingSpecifier) {
+			t.Errorf("Expected %v, got %v", specifier.ErrMissingSpecifier, result.Err)
+		}
+		if _, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r4).Query(ctx); !errors.Is(err, specifier.ErrMissingSpecifier) {
+			t.Errorf("Expected %v, got %v", specifier.ErrMissingSpecifier, err)
+		}
+
+		// HowCan reports the values of the keys it is not given, required or not
+		if _, err := query.HowCan(p1).Perform(action.ActionRead).On(r4).Query(ctx); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		// Policies leaving the key out apply to every value
+		if _, err := (policy.Policy{Subject: p1, Resource: r4, Action: action.ActionWrite, Specifiers: with(prod)}).Create(ctx); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		if err := (specifier.SpecifierKey{Name: "Region"}).Delete(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := (specifier.SpecifierKey{Name: "Region"}).Delete(ctx); !errors.Is(err, specifier.ErrSpecifierKeyNotFound) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierKeyNotFound, err)
+		}
+		if err := specifier.NewSpecifier("Region", "*").Delete(ctx, db.DeleteCascade); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Without a default, a query leaving Env out asks for every environment, which Principal1 lacks
+		if result := query.Can(p1).Perform(action.ActionRead).On(r4).Query(ctx); !result.Ok() || result.Can {
+			t.Errorf("Expected Principal1 not to READ Resource4 in every environment, got %v", result)
+		}
+		if _, err := (specifier.SpecifierKey{Name: "Env", Default: "prod"}).Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result := query.Can(p1).Perform(action.ActionRead).On(r4).Query(ctx); !result.Ok() || !result.Can {
+			t.Errorf("Expected Principal1 to READ Resource4 in prod, got %v", result)
+		}
+		if result := query.Can(p1).Perform(action.ActionRead).On(r4).With(with(specifier.NewSpecifier("Env", "dev"))).Query(ctx); !result.Ok() || result.Can {
+			t.Errorf("Expected Principal1 not to READ Resource4 in dev, got %v", result)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package query
+
+import (
+	"context"
+
+	"github.com/namsnath/otter/specifier"
+)
+
+// resolveSpecifiers checks the specifiers of a query against the specifier keys, and fills in
+// the defaults of the keys it does not pass. The values of typed keys are replaced by `*`, and
+// returned with the constraints they satisfy.
+func resolveSpecifiers(ctx context.Context, specifiers map[string]string) (map[string]string, map[string][]string, error) {
+	registry, err := specifier.LoadRegistry(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry.ForQuery(specifiers)
+}
+
+// checkSpecifiers checks the specifiers of a query that reports the values of the keys it does
+// not pass, which therefore stay wildcards whatever their defaults. The values of typed keys are
+// resolved as in resolveSpecifiers.
+func checkSpecifiers(ctx context.Context, specifiers map[string]string) (map[string]string, map[string][]string, error) {
+	registry, err := specifier.LoadRegistry(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry.Constrain(specifiers)
+}
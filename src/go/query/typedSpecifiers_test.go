@@ -0,0 +1,178 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestTypedSpecifiers(t *testing.T) {
+	db.TestContainer(t)
+
+	testTypedSpecifiers(t)
+}
+
+func TestTypedSpecifiersInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testTypedSpecifiers(t)
+}
+
+func testTypedSpecifiers(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	for _, key := range []specifier.SpecifierKey{
+		{Name: "Amount", Type: specifier.SpecifierTypeInt},
+		{Name: "Day", Type: specifier.SpecifierTypeDate},
+		{Name: "Hours", Type: specifier.SpecifierTypeTime},
+		{Name: "Network", Type: specifier.SpecifierTypeCIDR},
+		{Name: "Version", Type: specifier.SpecifierTypeSemver},
+	} {
+		if _, err := key.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := (specifier.SpecifierKey{Name: "Port", Type: specifier.SpecifierTypeInt, Values: []string{"80"}}).Create(ctx); !errors.Is(err, specifier.ErrInvalidSpecifierKey) {
+		t.Errorf("Expected %v, got %v", specifier.ErrInvalidSpecifierKey, err)
+	}
+
+	contractor, err := subject.Subject{Name: "Contractor", Type: subject.SubjectTypePrincipal}.Create(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r4 := resource.Resource{Name: "Resource4"}
+	rRoot := resource.Resource{Name: "_"}
+
+	// Specifiers sorted by key, as HowCan returns them
+	amount := specifier.NewSpecifier("Amount", "..1000")
+	day := specifier.NewSpecifier("Day", "2026-01-01..2026-12-31")
+	hours := specifier.NewSpecifier("Hours", "09:00-18:00")
+	network := specifier.NewSpecifier("Network", "10.0.0.0/8")
+	with := func(specifiers ...specifier.Specifier) specifier.SpecifierGroup {
+		return specifier.SpecifierGroup{Specifiers: specifiers}
+	}
+
+	policies := []policy.Policy{
+		{Subject: contractor, Resource: r4, Action: action.ActionRead, Specifiers: with(amount, day, hours, network)},
+		{Subject: contractor, Resource: r4, Action: action.ActionRead, Effect: policy.EffectDeny, Specifiers: with(specifier.NewSpecifier("Version", "<1.2.0"))},
+	}
+	for _, p := range policies {
+		if _, err := p.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Values of a request inside every constraint, each case overriding one of them
+	request := func(key string, value string) specifier.SpecifierGroup {
+		values := map[string]string{"Amount": "500", "Day": "2026-10-18", "Hours": "10:30", "Network": "10.1.2.3", "Version": "1.5.0"}
+		values[key] = value
+		group := specifier.SpecifierGroup{}
+		for _, k := range []string{"Amount", "Day", "Hours", "Network", "Version"} {
+			group.Specifiers = append(group.Specifiers, specifier.NewSpecifier(k, values[k]))
+		}
+		return group
+	}
+
+	t.Run("Constraints", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			key      string
+			value    string
+			expected bool
+		}{
+			{"Inside every constraint", "", "", true},
+			{"Amount above the range", "Amount", "5000", false},
+			{"Amount at the bound", "Amount", "1000", true},
+			{"Day after the range", "Day", "2027-01-01", false},
+			{"Hours outside the window", "Hours", "18:00", false},
+			{"Network outside the block", "Network", "192.168.0.1", false},
+			{"Denied version", "Version", "1.1.9", false},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				qb := query.Can(contractor).Perform(action.ActionRead).On(r4).With(request(tc.key, tc.value))
+				if result := qb.Query(ctx); !result.Ok() || result.Can != tc.expected {
+					t.Errorf("Expected Can %v, got %v", tc.expected, result)
+				}
+				if result := query.CanBatch(ctx, []query.CanQueryBuilder{qb})[0]; !result.Ok() || result.Can != tc.expected {
+					t.Errorf("Expected CanBatch %v, got %v", tc.expected, result)
+				}
+
+				subjects, err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r4).With(request(tc.key, tc.value)).Query(ctx)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if found := slices.Contains(subjects, contractor); found != tc.expected {
+					t.Errorf("Expected WhoCan to include Contractor %v, got %v", tc.expected, subjects)
+				}
+
+				resources, err := query.WhatCan(contractor).Perform(action.ActionRead).Under(rRoot).With(request(tc.key, tc.value)).Query(ctx)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if found := slices.Contains(resources, r4); found != tc.expected {
+					t.Errorf("Expected WhatCan to include Resource4 %v, got %v", tc.expected, resources)
+				}
+			})
+		}
+	})
+
+	t.Run("Explain", func(t *testing.T) {
+		explanation, err := query.Can(contractor).Perform(action.ActionRead).On(r4).With(request("", "")).Explain(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := query.SpecifierMatch{Input: specifier.NewSpecifier("Network", "10.1.2.3"), MatchedThrough: network}
+		if len(explanation.Granting) != 1 || !slices.Contains(explanation.Granting[0].Matches, expected) {
+			t.Errorf("Expected the policy to grant through %v, got %v", expected, explanation.Granting)
+		}
+	})
+
+	t.Run("HowCan", func(t *testing.T) {
+		groups, err := query.HowCan(contractor).Perform(action.ActionRead).On(r4).Query(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []specifier.SpecifierGroup{with(
+			amount, day, specifier.NewSpecifier("Env", "*"), hours, network, specifier.NewSpecifier("Role", "*"), specifier.NewSpecifier("Version", "*"),
+		)}
+		if !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, got %v", expected, groups)
+		}
+
+		// Passing a value checks it against the constraints, and the denied versions deny everything else
+		groups, err = query.HowCan(contractor).Perform(action.ActionRead).On(r4).With(with(specifier.NewSpecifier("Network", "10.9.9.9"))).Query(ctx)
+		if err != nil || len(groups) != 1 {
+			t.Errorf("Expected a group, got %v, %v", groups, err)
+		}
+		groups, err = query.HowCan(contractor).Perform(action.ActionRead).On(r4).With(with(specifier.NewSpecifier("Network", "192.168.0.1"))).Query(ctx)
+		if err != nil || len(groups) != 0 {
+			t.Errorf("Expected no group, got %v, %v", groups, err)
+		}
+		groups, err = query.HowCan(contractor).Perform(action.ActionRead).On(r4).With(with(specifier.NewSpecifier("Version", "1.0.0"))).Query(ctx)
+		if err != nil || len(groups) != 0 {
+			t.Errorf("Expected no group, got %v, %v", groups, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if result := query.Can(contractor).Perform(action.ActionRead).On(r4).With(request("Amount", "lots")).Query(ctx); !errors.Is(result.Err, specifier.ErrUnknownSpecifierValue) {
+			t.Errorf("Expected %v, got %v", specifier.ErrUnknownSpecifierValue, result.Err)
+		}
+		if _, err := (policy.Policy{Subject: contractor, Resource: r4, Action: action.ActionWrite, Specifiers: with(specifier.NewSpecifier("Amount", "1000..10"))}).Create(ctx); !errors.Is(err, specifier.ErrInvalidConstraint) {
+			t.Errorf("Expected %v, got %v", specifier.ErrInvalidConstraint, err)
+		}
+		if _, err := specifier.NewSpecifier("Amount", "..10").CreateAsChildOf(ctx, amount); !errors.Is(err, db.ErrInvalidInput) {
+			t.Errorf("Expected %v, got %v", db.ErrInvalidInput, err)
+		}
+	})
+}
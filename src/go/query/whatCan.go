@@ -1,16 +1,15 @@
 package query
 
 import (
-	"errors"
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/namsnath/otter/action"
 	"github.com/namsnath/otter/db"
 	"github.com/namsnath/otter/resource"
 	"github.com/namsnath/otter/specifier"
 	"github.com/namsnath/otter/subject"
-	"github.com/namsnath/otter/utils/hashset"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 type WhatCanQueryBuilder struct {
@@ -20,9 +19,9 @@ type WhatCanQueryBuilder struct {
 	specifiers     map[string]string
 }
 
-var ErrSubjectNotSet = errors.New("subject not set in query builder")
-var ErrActionNotSet = errors.New("action not set in query builder")
-var ErrParentResourceNotSet = errors.New("parentResource not set in query builder")
+var ErrSubjectNotSet = db.NewError(db.ErrInvalidInput, "subject not set in query builder")
+var ErrActionNotSet = db.NewError(db.ErrInvalidInput, "action not set in query builder")
+var ErrParentResourceNotSet = db.NewError(db.ErrInvalidInput, "parentResource not set in query builder")
 
 func WhatCan(subject subject.Subject) WhatCanQueryBuilder {
 	return WhatCanQueryBuilder{subject: subject}
@@ -63,57 +62,42 @@ func (qb WhatCanQueryBuilder) Validate() (WhatCanQueryBuilder, error) {
 	return qb, nil
 }
 
-func (qb WhatCanQueryBuilder) Query() ([]resource.Resource, error) {
+// accessQuery validates the query and resolves its specifiers for the store.
+func (qb WhatCanQueryBuilder) accessQuery(ctx context.Context) (WhatCanQueryBuilder, db.AccessQuery, error) {
 	qb, err := qb.Validate()
 	if err != nil {
-		return nil, err
+		return qb, db.AccessQuery{}, err
 	}
+	specifiers, constraints, err := resolveSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return qb, db.AccessQuery{}, err
+	}
+	qb.specifiers = specifiers
+
+	return qb, db.AccessQuery{
+		Subject:     qb.subject.Record(),
+		Action:      string(qb.action),
+		Resource:    qb.parentResource.Record(),
+		Specifiers:  qb.specifiers,
+		Constraints: constraints,
+	}, nil
+}
 
-	query := `
-		MATCH (specifier:Specifier)
-		WHERE specifier.key <> "*"
-		WITH collect(DISTINCT specifier.key) AS allKeys
-		WITH reduce(specMap = $specifiers, k IN allKeys |
-			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
-		) AS normalizedSpecifiers
-
-		UNWIND keys(normalizedSpecifiers) AS k
-		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
-
-		MATCH (s:Specifier)
-		WHERE s.key = k AND s.value = v
-
-		MATCH (p:Policy)-[:$($action)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
-
-		MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(parents:Subject)-[:HAS_POLICY]->(p)
-
-		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
-		WHERE matches = requiredMatches
-
-		MATCH (resource:Resource)-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
-		MATCH (resource)-[:CHILD_OF*0..]->(parent:Resource {name: $parent})
-
-		RETURN DISTINCT resource.name AS resource
-	`
-
-	params := map[string]any{
-		"subject":    qb.subject.Name,
-		"action":     string(qb.action),
-		"parent":     qb.parentResource.Name,
-		"specifiers": qb.specifiers,
+func (qb WhatCanQueryBuilder) Query(ctx context.Context) ([]resource.Resource, error) {
+	qb, q, err := qb.accessQuery(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	result := db.ExecuteQuery(query, params)
+	start := time.Now()
+	records, err := db.FromContext(ctx).WhatCan(ctx, q)
+	if err != nil {
+		return nil, err
+	}
 
-	resources := make([]resource.Resource, 0, len(result.Records))
-	for _, record := range result.Records {
-		nameVal, nameOk := record.Get("resource")
-		if nameOk {
-			if nameStr, nameIsStr := nameVal.(string); nameIsStr {
-				resource := resource.Resource{Name: nameStr}
-				resources = append(resources, resource)
-			}
-		}
+	resources := make([]resource.Resource, 0, len(records))
+	for _, record := range records {
+		resources = append(resources, resource.Resource{Name: record.Name})
 	}
 
 	slog.Info(
@@ -123,83 +107,77 @@ func (qb WhatCanQueryBuilder) Query() ([]resource.Resource, error) {
 		"underResource", qb.parentResource,
 		"specifiers", qb.specifiers,
 		"resources", resources,
-		"duration", result.Summary.ResultAvailableAfter(),
-		"rows", len(result.Records),
+		"duration", time.Since(start),
+		"rows", len(records),
 	)
 
 	return resources, nil
 }
 
+// Stream calls yield with every resource as the store reads it, rather than collecting them first,
+// and stops at the first error yield returns.
+func (qb WhatCanQueryBuilder) Stream(ctx context.Context, yield func(resource.Resource) error) error {
+	qb, q, err := qb.accessQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	rows := 0
+	err = db.FromContext(ctx).StreamWhatCan(ctx, q, func(record db.ResourceRecord) error {
+		rows++
+		return yield(resource.Resource{Name: record.Name})
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"WhatCan Stream",
+		"subject", qb.subject,
+		"action", qb.action,
+		"underResource", qb.parentResource,
+		"specifiers", qb.specifiers,
+		"duration", time.Since(start),
+		"rows", rows,
+	)
+	return nil
+}
+
 // Retrieve resources for a given subject, action, specifiers, and a parent resource, expanding to fetch all additional specifiers
 //
 // Returns:
 //   - A mapping of resources to their specifiers
 //   - error
-func (qb WhatCanQueryBuilder) QueryWithoutAllSpecifiers() (map[resource.Resource]map[string][]specifier.Specifier, error) {
+func (qb WhatCanQueryBuilder) QueryWithoutAllSpecifiers(ctx context.Context) (map[resource.Resource]map[string][]specifier.Specifier, error) {
 	qb, err := qb.Validate()
 	if err != nil {
 		return nil, err
 	}
-
-	query := `
-		WITH $specifiers AS normalizedSpecifiers
-			UNWIND keys(normalizedSpecifiers) AS k
-
-		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
-			MATCH (s:Specifier)
-				WHERE s.key = k AND s.value = v
-
-			MATCH (p:Policy)-[:$($action)]->(:Specifier)<-[:CHILD_OF*0..]-(s)
-
-			MATCH (subject:Subject {name: $subject})-[:CHILD_OF*0..]->(:Subject)-[:HAS_POLICY]->(p)
-
-		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
-			WHERE matches = requiredMatches
-
-			MATCH (resource:Resource)-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
-			MATCH (resource)-[:CHILD_OF*0..]->(parent:Resource {name: $parent})
-
-			// Expand the graph to get all the specifiers
-			MATCH (p)-[:$($action)]->(parentSpecifier:Specifier)<-[:CHILD_OF*0..]-(otherSpecifier:Specifier)
-				WHERE NOT otherSpecifier.key IN keys(normalizedSpecifiers) AND parentSpecifier.key <> "*"
-
-		RETURN resource, otherSpecifier
-	`
-
-	params := map[string]any{
-		"subject":    qb.subject.Name,
-		"action":     string(qb.action),
-		"parent":     qb.parentResource.Name,
-		"specifiers": qb.specifiers,
+	specifiers, constraints, err := checkSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return nil, err
 	}
 
-	result := db.ExecuteQuery(query, params)
-
-	resourcesWithSpecifiersMap := map[resource.Resource]map[string]*hashset.HashSet[string]{}
-	for _, record := range result.Records {
-		recordMap := record.AsMap()
-		resourceVal := recordMap["resource"].(neo4j.Node).Props
-		specifierVal := recordMap["otherSpecifier"].(neo4j.Node).Props
-
-		resourceObj := resource.Resource{Name: resourceVal["name"].(string)}
-		specifierObj := specifier.Specifier{Key: specifierVal["key"].(string), Value: specifierVal["value"].(string)}
-
-		if _, exists := resourcesWithSpecifiersMap[resourceObj]; !exists {
-			resourcesWithSpecifiersMap[resourceObj] = map[string]*hashset.HashSet[string]{}
-		}
-		if _, exists := resourcesWithSpecifiersMap[resourceObj][specifierObj.Key]; !exists {
-			resourcesWithSpecifiersMap[resourceObj][specifierObj.Key] = hashset.New[string]()
-		}
-
-		resourcesWithSpecifiersMap[resourceObj][specifierObj.Key].Add(specifierObj.Value)
+	start := time.Now()
+	records, err := db.FromContext(ctx).WhatCanWithoutAllSpecifiers(ctx, db.AccessQuery{
+		Subject:     qb.subject.Record(),
+		Action:      string(qb.action),
+		Resource:    qb.parentResource.Record(),
+		Specifiers:  specifiers,
+		Constraints: constraints,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	resourcesWithSpecifiers := map[resource.Resource]map[string][]specifier.Specifier{}
-	for res, specMap := range resourcesWithSpecifiersMap {
+	for resourceRecord, specMap := range records {
+		res := resource.Resource{Name: resourceRecord.Name}
 		resourcesWithSpecifiers[res] = map[string][]specifier.Specifier{}
 		for specKey, specValues := range specMap {
-			specList := make([]specifier.Specifier, 0, specValues.Len())
-			for specValue := range specValues.All() {
+			specList := make([]specifier.Specifier, 0, len(specValues))
+			for _, specValue := range specValues {
 				specList = append(specList, specifier.Specifier{Key: specKey, Value: specValue})
 			}
 			resourcesWithSpecifiers[res][specKey] = specList
@@ -213,8 +191,8 @@ func (qb WhatCanQueryBuilder) QueryWithoutAllSpecifiers() (map[resource.Resource
 		"underResource", qb.parentResource,
 		"specifiers", qb.specifiers,
 		"resourcesWithSpecifiers", resourcesWithSpecifiers,
-		"duration", result.Summary.ResultAvailableAfter(),
-		"rows", len(result.Records),
+		"duration", time.Since(start),
+		"rows", len(records),
 	)
 
 	return resourcesWithSpecifiers, nil
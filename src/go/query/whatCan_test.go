@@ -1,6 +1,7 @@
 package query_test
 
 import (
+	"errors"
 	"reflect"
 	"slices"
 	"strings"
@@ -15,14 +16,20 @@ import (
 )
 
 func TestWhatCanQueries(t *testing.T) {
-	ctx, container := db.TestContainer()
-	// Ensure the container is terminated after the test finishes
-	defer func() {
-		container.Terminate(ctx)
-	}()
+	db.TestContainer(t)
 
-	query.DeleteEverything()
-	query.SetupTestState()
+	testWhatCanQueries(t)
+}
+
+func TestWhatCanQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testWhatCanQueries(t)
+}
+
+func testWhatCanQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
 
 	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
 	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
@@ -55,7 +62,6 @@ func TestWhatCanQueries(t *testing.T) {
 		{"g2 READ", g2, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{}, []resource.Resource{r2}},
 		{"p2 READ", p2, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{}, []resource.Resource{r1, r2}},
 		{"p3 READ", p3, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{}, []resource.Resource{}},
-		{"p3 READ as dev", p3, action.ActionRead, resource.Resource{}, devGroup, []resource.Resource{}},
 		{"p3 READ in prod", p3, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{Specifiers: []specifier.Specifier{prodEnv}}, []resource.Resource{}},
 		{"p3 READ in prod as admin", p3, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{Specifiers: []specifier.Specifier{prodEnv, adminRole}}, []resource.Resource{rRoot, r1, r2, r3, r4}},
 		{"p3 READ in dev as admin", p3, action.ActionRead, resource.Resource{}, specifier.SpecifierGroup{Specifiers: []specifier.Specifier{devEnv, adminRole}}, []resource.Resource{rRoot, r1, r2, r3, r4}},
@@ -65,7 +71,7 @@ func TestWhatCanQueries(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := query.WhatCan(tc.subject).Perform(tc.action).Under(tc.resource).With(tc.specifiers).Query()
+			result, err := query.WhatCan(tc.subject).Perform(tc.action).Under(tc.resource).With(tc.specifiers).Query(ctx)
 			if err != nil {
 				t.Errorf("Unexpected error for %s: %v", tc.name, err)
 				return
@@ -81,6 +87,25 @@ func TestWhatCanQueries(t *testing.T) {
 			if !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, result)
 			}
+
+			streamed := []resource.Resource{}
+			err = query.WhatCan(tc.subject).Perform(tc.action).Under(tc.resource).With(tc.specifiers).Stream(ctx, func(r resource.Resource) error {
+				streamed = append(streamed, r)
+				return nil
+			})
+			slices.SortFunc(streamed, func(a, b resource.Resource) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+			if err != nil || !reflect.DeepEqual(streamed, tc.expected) {
+				t.Errorf("For %s, expected to stream %v, but got %v, %v", tc.name, tc.expected, streamed, err)
+			}
 		})
 	}
+
+	t.Run("p3 READ as dev", func(t *testing.T) {
+		// Role=dev does not exist, which is reported instead of matching nothing
+		if _, err := query.WhatCan(p3).Perform(action.ActionRead).Under(rRoot).With(devGroup).Query(ctx); !errors.Is(err, specifier.ErrUnknownSpecifierValue) {
+			t.Errorf("Expected %v, got %v", specifier.ErrUnknownSpecifierValue, err)
+		}
+	})
 }
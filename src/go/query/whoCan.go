@@ -1,8 +1,9 @@
 package query
 
 import (
-	"fmt"
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/namsnath/otter/action"
 	"github.com/namsnath/otter/db"
@@ -47,69 +48,56 @@ func (qb WhoCanQueryBuilder) With(sg specifier.SpecifierGroup) WhoCanQueryBuilde
 
 func (qb WhoCanQueryBuilder) Validate() (WhoCanQueryBuilder, error) {
 	if qb.action == "" || qb.resource == (resource.Resource{}) {
-		return WhoCanQueryBuilder{}, fmt.Errorf("incomplete WhoCan query: action and resource must be set")
+		return WhoCanQueryBuilder{}, db.NewError(db.ErrInvalidInput, "incomplete WhoCan query: action and resource must be set")
 	}
 	if qb.ofType == "" {
-		return WhoCanQueryBuilder{}, fmt.Errorf("incomplete WhoCan query: subject type must be set")
+		return WhoCanQueryBuilder{}, db.NewError(db.ErrInvalidInput, "incomplete WhoCan query: subject type must be set")
 	}
 
 	return qb, nil
 }
 
-func (qb WhoCanQueryBuilder) Query() ([]subject.Subject, error) {
-	qb, ok := qb.Validate()
-	if ok != nil {
-		return []subject.Subject{}, ok
+// accessQuery validates the query and resolves its specifiers for the store.
+func (qb WhoCanQueryBuilder) accessQuery(ctx context.Context) (WhoCanQueryBuilder, db.AccessQuery, error) {
+	qb, err := qb.Validate()
+	if err != nil {
+		return qb, db.AccessQuery{}, err
 	}
 
-	query := `
-		MATCH (specifier:Specifier)
-		WHERE specifier.key <> "*"
-		WITH collect(DISTINCT specifier.key) AS allKeys
-		WITH reduce(specMap = $specifiers, k IN allKeys |
-			CASE WHEN NOT k IN keys(specMap) THEN apoc.map.setKey(specMap, k, "*") ELSE specMap END
-		) AS normalizedSpecifiers
-
-		UNWIND keys(normalizedSpecifiers) AS k
-		WITH normalizedSpecifiers, k, normalizedSpecifiers[k] AS v
-
-		MATCH (s:Specifier)
-		WHERE s.key = k AND s.value = v
-
-		MATCH (p:Policy)-[:$($action)]->(ps:Specifier)<-[:CHILD_OF*0..]-(s)
-		MATCH (resource:Resource {name: $resource})-[:CHILD_OF*0..]->(:Resource)-[:HAS_POLICY]->(p)
-
-		WITH p, count(DISTINCT s.key) AS matches, size(keys(normalizedSpecifiers)) AS requiredMatches, normalizedSpecifiers
-		WHERE matches = requiredMatches
-
-		MATCH (subject:Subject {type: $ofType})-[:CHILD_OF*0..]->(:Subject)-[:HAS_POLICY]->(p)
+	specifiers, constraints, err := resolveSpecifiers(ctx, qb.specifiers)
+	if err != nil {
+		return qb, db.AccessQuery{}, err
+	}
+	qb.specifiers = specifiers
+
+	return qb, db.AccessQuery{
+		SubjectType: string(qb.ofType),
+		Action:      string(qb.action),
+		Resource:    qb.resource.Record(),
+		Specifiers:  qb.specifiers,
+		Constraints: constraints,
+	}, nil
+}
 
-		RETURN DISTINCT subject.name AS subject, subject.type AS subjectType
-	`
+func (qb WhoCanQueryBuilder) Query(ctx context.Context) ([]subject.Subject, error) {
+	qb, q, err := qb.accessQuery(ctx)
+	if err != nil {
+		return []subject.Subject{}, err
+	}
 
-	params := map[string]any{
-		"resource":   qb.resource.Name,
-		"action":     string(qb.action),
-		"specifiers": qb.specifiers,
-		"ofType":     string(qb.ofType),
+	start := time.Now()
+	records, err := db.FromContext(ctx).WhoCan(ctx, q)
+	if err != nil {
+		return nil, err
 	}
 
-	result := db.ExecuteQuery(query, params)
-
-	subjects := make([]subject.Subject, 0, len(result.Records))
-	for _, record := range result.Records {
-		nameVal, nameOk := record.Get("subject")
-		typeVal, typeOk := record.Get("subjectType")
-		if nameOk && typeOk {
-			if nameStr, nameIsStr := nameVal.(string); nameIsStr {
-				subjectType, err := subject.SubjectTypeFromString(typeVal.(string))
-				if err != nil {
-					return nil, err
-				}
-				subject := subject.Subject{Name: nameStr, Type: subjectType}
-				subjects = append(subjects, subject)
-			}
+	subjects := make([]subject.Subject, 0, len(records))
+	for _, record := range records {
+		subjectType, err := subject.SubjectTypeFromString(record.Type)
+		if err != nil {
+			return nil, err
 		}
+		subjects = append(subjects, subject.Subject{Name: record.Name, Type: subjectType})
 	}
 
 	slog.Info("WhoCan",
@@ -117,9 +105,41 @@ func (qb WhoCanQueryBuilder) Query() ([]subject.Subject, error) {
 		"resource", qb.resource,
 		"specifiers", qb.specifiers,
 		"subjects", subjects,
-		"duration", result.Summary.ResultAvailableAfter(),
-		"rows", len(result.Records),
+		"duration", time.Since(start),
+		"rows", len(records),
 	)
 
 	return subjects, nil
 }
+
+// Stream calls yield with every subject as the store reads it, rather than collecting them first,
+// and stops at the first error yield returns.
+func (qb WhoCanQueryBuilder) Stream(ctx context.Context, yield func(subject.Subject) error) error {
+	qb, q, err := qb.accessQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	rows := 0
+	err = db.FromContext(ctx).StreamWhoCan(ctx, q, func(record db.SubjectRecord) error {
+		subjectType, err := subject.SubjectTypeFromString(record.Type)
+		if err != nil {
+			return err
+		}
+		rows++
+		return yield(subject.Subject{Name: record.Name, Type: subjectType})
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("WhoCan Stream",
+		"action", qb.action,
+		"resource", qb.resource,
+		"specifiers", qb.specifiers,
+		"duration", time.Since(start),
+		"rows", rows,
+	)
+	return nil
+}
@@ -1,6 +1,7 @@
 package query_test
 
 import (
+	"errors"
 	"reflect"
 	"slices"
 	"strings"
@@ -15,14 +16,20 @@ import (
 )
 
 func TestWhoCanQueries(t *testing.T) {
-	ctx, container := db.TestContainer()
-	// Ensure the container is terminated after the test finishes
-	defer func() {
-		container.Terminate(ctx)
-	}()
+	db.TestContainer(t)
 
-	query.DeleteEverything()
-	query.SetupTestState()
+	testWhoCanQueries(t)
+}
+
+func TestWhoCanQueriesInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testWhoCanQueries(t)
+}
+
+func testWhoCanQueries(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
 
 	p1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
 	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
@@ -42,10 +49,10 @@ func TestWhoCanQueries(t *testing.T) {
 	prodEnv := specifier.NewSpecifier("Env", "prod")
 	// devEnv := specifier.NewSpecifier("Env", "dev")
 
-	// query.WhoCan(action.ActionRead).On(r1).Query()
-	// query.WhoCan(action.ActionRead).On(r2).Query()
-	// query.WhoCan(action.ActionRead).On(rRoot).Query()
-	// query.WhoCan(action.ActionRead).On(rRoot).With(specifierGroup).Query()
+	// query.WhoCan(action.ActionRead).On(r1).Query(ctx)
+	// query.WhoCan(action.ActionRead).On(r2).Query(ctx)
+	// query.WhoCan(action.ActionRead).On(rRoot).Query(ctx)
+	// query.WhoCan(action.ActionRead).On(rRoot).With(specifierGroup).Query(ctx)
 
 	testCases := []struct {
 		name        string
@@ -71,7 +78,7 @@ func TestWhoCanQueries(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := query.WhoCan(tc.subjectType).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query()
+			result, err := query.WhoCan(tc.subjectType).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Query(ctx)
 			if err != nil {
 				t.Errorf("Unexpected error for %s: %v", tc.name, err)
 				return
@@ -87,6 +94,30 @@ func TestWhoCanQueries(t *testing.T) {
 			if !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, result)
 			}
+
+			streamed := []subject.Subject{}
+			err = query.WhoCan(tc.subjectType).Perform(tc.action).On(tc.resource).With(specifier.SpecifierGroup{Specifiers: tc.specifiers}).Stream(ctx, func(s subject.Subject) error {
+				streamed = append(streamed, s)
+				return nil
+			})
+			slices.SortFunc(streamed, func(a, b subject.Subject) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+			if err != nil || !reflect.DeepEqual(streamed, tc.expected) {
+				t.Errorf("For %s, expected to stream %v, but got %v, %v", tc.name, tc.expected, streamed, err)
+			}
 		})
 	}
+
+	t.Run("Stream stops at the first error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := query.WhoCan(subject.SubjectTypePrincipal).Perform(action.ActionRead).On(r1).Stream(ctx, func(subject.Subject) error {
+			calls++
+			return errStop
+		})
+		if !errors.Is(err, errStop) || calls != 1 {
+			t.Errorf("Expected %v after a single call, got %v after %d", errStop, err, calls)
+		}
+	})
 }
@@ -1,7 +1,7 @@
 package resource
 
 type Resource struct {
-	Name string
+	Name string `json:"name" yaml:"name"`
 }
 
 func NewResource(name string) Resource {
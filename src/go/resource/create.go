@@ -1,31 +1,77 @@
 package resource
 
-import "github.com/namsnath/otter/db"
-
-func (resource Resource) Create() Resource {
-	db.ExecuteQuery(`
-		CREATE (r:Resource {name: $name})
-		`,
-		map[string]any{
-			"name": resource.Name,
-		},
-	)
-
-	return resource
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/namsnath/otter/db"
+)
+
+// Record returns the storage representation of the resource.
+func (resource Resource) Record() db.ResourceRecord {
+	return db.ResourceRecord{Name: resource.Name}
 }
 
-func (resource Resource) CreateAsChildOf(parent Resource) Resource {
-	db.ExecuteQuery(`
-		CREATE (r:Resource {name: $name})
-		WITH r
-		MATCH (p:Resource {name: $parentName})
-		CREATE (r)-[:CHILD_OF]->(p)
-		`,
-		map[string]any{
-			"name":       resource.Name,
-			"parentName": parent.Name,
-		},
-	)
-
-	return resource
+// Create stores the resource, failing with ErrResourceExists if a resource has the same name.
+func (resource Resource) Create(ctx context.Context) (Resource, error) {
+	err := db.FromContext(ctx).CreateResource(ctx, resource.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Resource{}, ErrResourceExists
+	}
+	if err != nil {
+		return Resource{}, err
+	}
+
+	return resource, nil
+}
+
+// CreateAsChildOf creates the resource under the parent, failing with ErrResourceNotFound, and creating
+// nothing, if the parent does not exist.
+func (resource Resource) CreateAsChildOf(ctx context.Context, parent Resource) (Resource, error) {
+	err := db.FromContext(ctx).CreateResourceAsChildOf(ctx, resource.Record(), parent.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Resource{}, ErrResourceExists
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return Resource{}, fmt.Errorf("parent %s: %w", parent.Name, ErrResourceNotFound)
+	}
+	if err != nil {
+		return Resource{}, err
+	}
+
+	return resource, nil
+}
+
+// GetOrCreate returns the resource if it exists, and creates it otherwise.
+func (resource Resource) GetOrCreate(ctx context.Context) (Resource, error) {
+	return resource.getOrCreate(ctx, func(ctx context.Context) (Resource, error) {
+		return resource.Create(ctx)
+	})
+}
+
+// GetOrCreateAsChildOf returns the resource if it exists, and creates it under the parent otherwise.
+// The parents of an existing resource are left unchanged.
+func (resource Resource) GetOrCreateAsChildOf(ctx context.Context, parent Resource) (Resource, error) {
+	return resource.getOrCreate(ctx, func(ctx context.Context) (Resource, error) {
+		return resource.CreateAsChildOf(ctx, parent)
+	})
+}
+
+func (resource Resource) getOrCreate(ctx context.Context, create func(ctx context.Context) (Resource, error)) (Resource, error) {
+	var result Resource
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		existing, err := Get(ctx, resource.Name)
+		if errors.Is(err, ErrResourceNotFound) {
+			result, err = create(ctx)
+			return err
+		}
+		result = existing
+		return err
+	})
+	if err != nil {
+		return Resource{}, err
+	}
+
+	return result, nil
 }
@@ -0,0 +1,213 @@
+package resource
+
+import (
+	"context"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
+
+var ErrResourceNotFound = db.NewError(db.ErrNotFound, "resource not found")
+var ErrResourceExists = db.NewError(db.ErrConflict, "resource already exists")
+var ErrResourceCycle = db.NewError(db.ErrConflict, "resource cannot be placed under itself or one of its descendants")
+var ErrNotAParent = db.NewError(db.ErrInvalidInput, "resource is not a direct parent")
+var ErrResourceInUse = db.NewError(db.ErrConflict, "resource holds policies or has children")
+
+// Get returns the resource with the name.
+func Get(ctx context.Context, name string) (Resource, error) {
+	_, found, err := db.FromContext(ctx).GetResource(ctx, name)
+	if err != nil {
+		return Resource{}, err
+	}
+	if !found {
+		return Resource{}, ErrResourceNotFound
+	}
+	return Resource{Name: name}, nil
+}
+
+// List returns all resources, sorted by name.
+func List(ctx context.Context) ([]Resource, error) {
+	nodes, err := db.FromContext(ctx).GetResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(nodes))
+	for _, node := range nodes {
+		resources = append(resources, Resource{Name: node.Resource.Name})
+	}
+	return resources, nil
+}
+
+// Parents returns the direct parents of the resource, sorted by name.
+func (resource Resource) Parents(ctx context.Context) ([]Resource, error) {
+	node, found, err := db.FromContext(ctx).GetResource(ctx, resource.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrResourceNotFound
+	}
+
+	parents := make([]Resource, 0, len(node.Parents))
+	for _, parent := range node.Parents {
+		parents = append(parents, Resource{Name: parent.Name})
+	}
+	return parents, nil
+}
+
+// isAncestorOf reports whether the resource is the other one, or one of its ancestors.
+func (resource Resource) isAncestorOf(ctx context.Context, other Resource) (bool, error) {
+	visited := []Resource{other}
+	queue := []Resource{other}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == resource {
+			return true, nil
+		}
+
+		parents, err := current.Parents(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, parent := range parents {
+			if !slices.Contains(visited, parent) {
+				visited = append(visited, parent)
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return false, nil
+}
+
+// AttachTo adds the parent to the parents of the resource, keeping the existing ones. The parent
+// must exist and not be below the resource.
+func (resource Resource) AttachTo(ctx context.Context, parent Resource) error {
+	// The checks and the edge share a transaction, so a concurrent move cannot close a cycle
+	return db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := resource.Parents(ctx); err != nil {
+			return err
+		}
+		if err := resource.checkParent(ctx, parent); err != nil {
+			return err
+		}
+		return db.FromContext(ctx).AddResourceParent(ctx, resource.Record(), parent.Record())
+	})
+}
+
+// DetachFrom removes a direct parent of the resource. Detaching the last parent makes the resource a root.
+func (resource Resource) DetachFrom(ctx context.Context, parent Resource) error {
+	parents, err := resource.Parents(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(parents, parent) {
+		return ErrNotAParent
+	}
+
+	return db.FromContext(ctx).RemoveResourceParent(ctx, resource.Record(), parent.Record())
+}
+
+// MoveTo replaces the parents of the resource with the given one, which must exist
+// and not be below the resource.
+func (resource Resource) MoveTo(ctx context.Context, parent Resource) error {
+	return db.InTx(ctx, func(ctx context.Context) error {
+		parents, err := resource.Parents(ctx)
+		if err != nil {
+			return err
+		}
+		if err := resource.checkParent(ctx, parent); err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		for _, p := range parents {
+			if p == parent {
+				continue
+			}
+			if err := store.RemoveResourceParent(ctx, resource.Record(), p.Record()); err != nil {
+				return err
+			}
+		}
+		return store.AddResourceParent(ctx, resource.Record(), parent.Record())
+	})
+}
+
+// checkParent checks that the parent exists and is not below the resource.
+func (resource Resource) checkParent(ctx context.Context, parent Resource) error {
+	if _, err := Get(ctx, parent.Name); err != nil {
+		return err
+	}
+
+	cycle, err := resource.isAncestorOf(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrResourceCycle
+	}
+	return nil
+}
+
+// Children returns the resources the resource is a direct parent of, sorted by name.
+func (resource Resource) Children(ctx context.Context) ([]Resource, error) {
+	if _, err := Get(ctx, resource.Name); err != nil {
+		return nil, err
+	}
+
+	nodes, err := db.FromContext(ctx).GetResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Resource{}
+	for _, node := range nodes {
+		if slices.Contains(node.Parents, resource.Record()) {
+			children = append(children, Resource{Name: node.Resource.Name})
+		}
+	}
+	return children, nil
+}
+
+// Delete removes the resource. The mode selects what happens to the policies it holds and to its children:
+// db.DeleteRefuse fails with ErrResourceInUse if it has any, db.DeleteCascade deletes the policies
+// and detaches the children, which become roots unless they have other parents, and db.DeleteReparent
+// deletes the policies and attaches the children to the parents of the resource instead.
+func (resource Resource) Delete(ctx context.Context, mode db.DeleteMode) error {
+	return db.InTx(ctx, func(ctx context.Context) error {
+		parents, err := resource.Parents(ctx)
+		if err != nil {
+			return err
+		}
+		children, err := resource.Children(ctx)
+		if err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		switch mode {
+		case db.DeleteRefuse:
+			policies, err := store.GetPolicies(ctx, db.PolicyFilter{ResourceName: resource.Name})
+			if err != nil {
+				return err
+			}
+			if len(policies) > 0 || len(children) > 0 {
+				return ErrResourceInUse
+			}
+		case db.DeleteCascade:
+		case db.DeleteReparent:
+			for _, child := range children {
+				for _, parent := range parents {
+					if err := store.AddResourceParent(ctx, child.Record(), parent.Record()); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			return db.ErrInvalidDeleteMode
+		}
+
+		return store.DeleteResource(ctx, resource.Record())
+	})
+}
@@ -0,0 +1,166 @@
+package resource_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestResourceCrud(t *testing.T) {
+	db.TestContainer(t)
+
+	testResourceCrud(t)
+}
+
+func TestResourceCrudInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testResourceCrud(t)
+}
+
+func testResourceCrud(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	root := resource.NewResource("_")
+	resource3 := resource.NewResource("Resource3")
+	resource4 := resource.NewResource("Resource4")
+	principal1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+
+	t.Run("Get and list", func(t *testing.T) {
+		if _, err := resource.Get(ctx, "Nowhere"); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceNotFound, err)
+		}
+
+		resources, err := resource.List(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(resources) != 5 {
+			t.Errorf("Expected 5 resources, got %v", resources)
+		}
+
+		parents, err := resource4.Parents(ctx)
+		if err != nil || !reflect.DeepEqual(parents, []resource.Resource{resource3}) {
+			t.Errorf("Expected %v, got %v, %v", []resource.Resource{resource3}, parents, err)
+		}
+	})
+
+	t.Run("Missing parent", func(t *testing.T) {
+		if _, err := resource.NewResource("Orphan").CreateAsChildOf(ctx, resource.NewResource("Nowhere")); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceNotFound, err)
+		}
+		if _, err := resource.Get(ctx, "Orphan"); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected nothing to be created, got %v", err)
+		}
+	})
+
+	t.Run("Create once", func(t *testing.T) {
+		if _, err := resource3.Create(ctx); !errors.Is(err, resource.ErrResourceExists) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceExists, err)
+		}
+		if _, err := resource3.CreateAsChildOf(ctx, root); !errors.Is(err, resource.ErrResourceExists) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceExists, err)
+		}
+
+		resource5 := resource.NewResource("Resource5")
+		for range 2 {
+			if r, err := resource5.GetOrCreateAsChildOf(ctx, resource4); err != nil || r != resource5 {
+				t.Errorf("Expected %v, got %v, %v", resource5, r, err)
+			}
+		}
+		if parents, _ := resource5.Parents(ctx); !reflect.DeepEqual(parents, []resource.Resource{resource4}) {
+			t.Errorf("Expected a single %v parent, got %v", resource4, parents)
+		}
+		if err := resource5.Delete(ctx, db.DeleteRefuse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := specifier.NewSpecifier("Env", "prod").Create(ctx); !errors.Is(err, specifier.ErrSpecifierExists) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierExists, err)
+		}
+		if s, err := specifier.NewSpecifier("Env", "prod").GetOrCreate(ctx); err != nil || s != specifier.NewSpecifier("Env", "prod") {
+			t.Errorf("Expected the existing specifier, got %v, %v", s, err)
+		}
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		if err := resource3.MoveTo(ctx, resource4); !errors.Is(err, resource.ErrResourceCycle) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceCycle, err)
+		}
+		if err := resource3.MoveTo(ctx, resource3); !errors.Is(err, resource.ErrResourceCycle) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceCycle, err)
+		}
+		if err := resource4.MoveTo(ctx, resource.NewResource("Nowhere")); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceNotFound, err)
+		}
+
+		// Principal1 can READ Resource4 in prod through Resource3, which it loses once moved
+		prod := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Env", "prod")}}
+		if result := query.Can(principal1).Perform(action.ActionRead).On(resource4).With(prod).Query(ctx); !result.Can {
+			t.Errorf("Expected Principal1 to READ Resource4 before the move")
+		}
+
+		if err := resource4.MoveTo(ctx, root); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		parents, _ := resource4.Parents(ctx)
+		if expected := []resource.Resource{root}; !reflect.DeepEqual(parents, expected) {
+			t.Errorf("Expected %v, got %v", expected, parents)
+		}
+		if result := query.Can(principal1).Perform(action.ActionRead).On(resource4).With(prod).Query(ctx); result.Can {
+			t.Errorf("Expected Principal1 not to READ Resource4 after the move")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := resource3.Delete(ctx, db.DeleteRefuse); !errors.Is(err, resource.ErrResourceInUse) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceInUse, err)
+		}
+
+		// Resource4 takes the place of Resource3 under the root, keeping the access granted there
+		if err := resource4.MoveTo(ctx, resource3); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := resource3.Delete(ctx, db.DeleteReparent); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if parents, _ := resource4.Parents(ctx); !reflect.DeepEqual(parents, []resource.Resource{root}) {
+			t.Errorf("Expected %v, got %v", []resource.Resource{root}, parents)
+		}
+		principal3 := subject.Subject{Name: "Principal3", Type: subject.SubjectTypePrincipal}
+		admin := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Role", "admin")}}
+		if result := query.Can(principal3).Perform(action.ActionRead).On(resource4).With(admin).Query(ctx); !result.Can {
+			t.Errorf("Expected Principal3 to READ Resource4 through the root")
+		}
+		if policies, _ := db.GetInstance().GetPolicies(ctx, db.PolicyFilter{ResourceName: "Resource3"}); len(policies) != 0 {
+			t.Errorf("Expected the policies on Resource3 to be deleted, got %v", policies)
+		}
+
+		if err := resource4.Delete(ctx, db.DeleteRefuse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := resource4.Delete(ctx, db.DeleteRefuse); !errors.Is(err, resource.ErrResourceNotFound) {
+			t.Errorf("Expected %v, got %v", resource.ErrResourceNotFound, err)
+		}
+
+		// Resource1 and Resource2 are detached, leaving them as roots
+		if err := root.Delete(ctx, db.DeleteCascade); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resources, _ := resource.List(ctx)
+		if expected := []resource.Resource{resource.NewResource("Resource1"), resource.NewResource("Resource2")}; !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v, got %v", expected, resources)
+		}
+		if parents, _ := resource.NewResource("Resource1").Parents(ctx); len(parents) != 0 {
+			t.Errorf("Expected Resource1 to be a root, got %v", parents)
+		}
+	})
+}
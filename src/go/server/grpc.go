@@ -0,0 +1,260 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/namsnath/otter/action"
+	otterv1 "github.com/namsnath/otter/api/otter/v1"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type authorizationService struct {
+	otterv1.UnimplementedAuthorizationServiceServer
+}
+
+// NewGRPCServer returns a gRPC server with the AuthorizationService registered.
+func NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	otterv1.RegisterAuthorizationServiceServer(s, &authorizationService{})
+	return s
+}
+
+func fromProtoSubject(s *otterv1.Subject) (subject.Subject, error) {
+	return subjectBody{Name: s.GetName(), Type: s.GetType()}.toSubject()
+}
+
+func toProtoSubject(s subject.Subject) *otterv1.Subject {
+	return &otterv1.Subject{Name: s.Name, Type: string(s.Type)}
+}
+
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// storeError returns the gRPC status of an error of the entities, queries or store, from its kind.
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, db.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrBackendUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func checkQuery(ctx context.Context, req *otterv1.CheckRequest) (query.CanQueryBuilder, error) {
+	s, err := fromProtoSubject(req.GetSubject())
+	if err != nil {
+		return query.CanQueryBuilder{}, invalidArgument(err)
+	}
+
+	a, err := action.FromString(ctx, req.GetAction())
+	if err != nil {
+		return query.CanQueryBuilder{}, storeError(err)
+	}
+
+	qb, err := query.Can(s).Perform(a).On(resource.NewResource(req.GetResource())).With(toSpecifierGroup(req.GetSpecifiers())).Validate()
+	if err != nil {
+		return query.CanQueryBuilder{}, invalidArgument(err)
+	}
+	return qb, nil
+}
+
+func (authorizationService) Check(ctx context.Context, req *otterv1.CheckRequest) (*otterv1.CheckResponse, error) {
+	qb, err := checkQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := qb.Query(ctx)
+	if result.Err != nil {
+		return nil, storeError(result.Err)
+	}
+
+	return &otterv1.CheckResponse{Can: result.Can}, nil
+}
+
+func (authorizationService) BatchCheck(ctx context.Context, req *otterv1.BatchCheckRequest) (*otterv1.BatchCheckResponse, error) {
+	queries := make([]query.CanQueryBuilder, len(req.GetChecks()))
+	checkErrors := make([]error, len(req.GetChecks()))
+	for i, check := range req.GetChecks() {
+		queries[i], checkErrors[i] = checkQuery(ctx, check)
+	}
+
+	results := query.CanBatch(ctx, queries)
+
+	response := &otterv1.BatchCheckResponse{Results: make([]*otterv1.BatchCheckResult, 0, len(results))}
+	for i, result := range results {
+		switch {
+		case checkErrors[i] != nil:
+			response.Results = append(response.Results, &otterv1.BatchCheckResult{Error: status.Convert(checkErrors[i]).Message()})
+		case result.Err != nil:
+			response.Results = append(response.Results, &otterv1.BatchCheckResult{Error: result.Err.Error()})
+		default:
+			response.Results = append(response.Results, &otterv1.BatchCheckResult{Can: result.Can})
+		}
+	}
+
+	return response, nil
+}
+
+func whoCanQuery(ctx context.Context, req *otterv1.LookupSubjectsRequest) (query.WhoCanQueryBuilder, error) {
+	subjectType, err := parseSubjectType(req.GetSubjectType())
+	if err != nil {
+		return query.WhoCanQueryBuilder{}, invalidArgument(err)
+	}
+
+	a, err := action.FromString(ctx, req.GetAction())
+	if err != nil {
+		return query.WhoCanQueryBuilder{}, storeError(err)
+	}
+
+	qb, err := query.WhoCan(subjectType).Perform(a).On(resource.NewResource(req.GetResource())).With(toSpecifierGroup(req.GetSpecifiers())).Validate()
+	if err != nil {
+		return query.WhoCanQueryBuilder{}, invalidArgument(err)
+	}
+	return qb, nil
+}
+
+func (authorizationService) LookupSubjects(ctx context.Context, req *otterv1.LookupSubjectsRequest) (*otterv1.LookupSubjectsResponse, error) {
+	qb, err := whoCanQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := qb.Query(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	response := &otterv1.LookupSubjectsResponse{Subjects: make([]*otterv1.Subject, 0, len(subjects))}
+	for _, s := range subjects {
+		response.Subjects = append(response.Subjects, toProtoSubject(s))
+	}
+	return response, nil
+}
+
+// StreamLookupSubjects sends every subject as the store reads it.
+func (authorizationService) StreamLookupSubjects(req *otterv1.LookupSubjectsRequest, stream grpc.ServerStreamingServer[otterv1.StreamLookupSubjectsResponse]) error {
+	qb, err := whoCanQuery(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	// Errors of Send are already statuses, and only those of the store need one
+	var sendErr error
+	err = qb.Stream(stream.Context(), func(s subject.Subject) error {
+		sendErr = stream.Send(&otterv1.StreamLookupSubjectsResponse{Subject: toProtoSubject(s)})
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return storeError(err)
+	}
+	return nil
+}
+
+func whatCanQuery(ctx context.Context, req *otterv1.LookupResourcesRequest) (query.WhatCanQueryBuilder, error) {
+	s, err := fromProtoSubject(req.GetSubject())
+	if err != nil {
+		return query.WhatCanQueryBuilder{}, invalidArgument(err)
+	}
+
+	a, err := action.FromString(ctx, req.GetAction())
+	if err != nil {
+		return query.WhatCanQueryBuilder{}, storeError(err)
+	}
+
+	under := resource.Resource{}
+	if req.GetUnder() != "" {
+		under = resource.NewResource(req.GetUnder())
+	}
+
+	qb, err := query.WhatCan(s).Perform(a).Under(under).With(toSpecifierGroup(req.GetSpecifiers())).Validate()
+	if err != nil {
+		return query.WhatCanQueryBuilder{}, invalidArgument(err)
+	}
+	return qb, nil
+}
+
+func (authorizationService) LookupResources(ctx context.Context, req *otterv1.LookupResourcesRequest) (*otterv1.LookupResourcesResponse, error) {
+	qb, err := whatCanQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := qb.Query(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	response := &otterv1.LookupResourcesResponse{Resources: make([]string, 0, len(resources))}
+	for _, res := range resources {
+		response.Resources = append(response.Resources, res.Name)
+	}
+	return response, nil
+}
+
+// StreamLookupResources sends every resource as the store reads it.
+func (authorizationService) StreamLookupResources(req *otterv1.LookupResourcesRequest, stream grpc.ServerStreamingServer[otterv1.StreamLookupResourcesResponse]) error {
+	qb, err := whatCanQuery(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	// Errors of Send are already statuses, and only those of the store need one
+	var sendErr error
+	err = qb.Stream(stream.Context(), func(res resource.Resource) error {
+		sendErr = stream.Send(&otterv1.StreamLookupResourcesResponse{Resource: res.Name})
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return storeError(err)
+	}
+	return nil
+}
+
+func (authorizationService) ExpandSpecifiers(ctx context.Context, req *otterv1.ExpandSpecifiersRequest) (*otterv1.ExpandSpecifiersResponse, error) {
+	s, err := fromProtoSubject(req.GetSubject())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	a, err := action.FromString(ctx, req.GetAction())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	qb, err := query.HowCan(s).Perform(a).On(resource.NewResource(req.GetResource())).With(toSpecifierGroup(req.GetSpecifiers())).Validate()
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	specifierGroups, err := qb.Query(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	response := &otterv1.ExpandSpecifiersResponse{SpecifierGroups: make([]*otterv1.SpecifierGroup, 0, len(specifierGroups))}
+	for _, sg := range specifierGroups {
+		response.SpecifierGroups = append(response.SpecifierGroups, &otterv1.SpecifierGroup{Specifiers: sg.AsMap()})
+	}
+	return response, nil
+}
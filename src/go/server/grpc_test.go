@@ -0,0 +1,239 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"slices"
+	"testing"
+
+	otterv1 "github.com/namsnath/otter/api/otter/v1"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupGRPCClient(t *testing.T) otterv1.AuthorizationServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return otterv1.NewAuthorizationServiceClient(conn)
+}
+
+func TestGRPCAuthorizationService(t *testing.T) {
+	ctx := t.Context()
+	db.SetupMemoryInstance()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	client := setupGRPCClient(t)
+
+	p1 := &otterv1.Subject{Name: "Principal1"}
+	p3 := &otterv1.Subject{Name: "Principal3", Type: "Principal"}
+
+	t.Run("Check", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			req      *otterv1.CheckRequest
+			expected bool
+		}{
+			{"p1 READ r1", &otterv1.CheckRequest{Subject: p1, Action: "READ", Resource: "Resource1"}, true},
+			{"p1 READ r3", &otterv1.CheckRequest{Subject: p1, Action: "READ", Resource: "Resource3"}, false},
+			{"p3 READ r1 as admin", &otterv1.CheckRequest{Subject: p3, Action: "READ", Resource: "Resource1", Specifiers: map[string]string{"Role": "admin"}}, true},
+		}
+
+		for _, tc := range testCases {
+			resp, err := client.Check(ctx, tc.req)
+			if err != nil {
+				t.Errorf("Unexpected error for %s: %v", tc.name, err)
+				continue
+			}
+			if resp.GetCan() != tc.expected {
+				t.Errorf("For %s, expected %v, but got %v", tc.name, tc.expected, resp.GetCan())
+			}
+		}
+	})
+
+	t.Run("Check with invalid action", func(t *testing.T) {
+		_, err := client.Check(ctx, &otterv1.CheckRequest{Subject: p1, Action: "FLY", Resource: "Resource1"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, but got %v", err)
+		}
+	})
+
+	t.Run("BatchCheck", func(t *testing.T) {
+		resp, err := client.BatchCheck(ctx, &otterv1.BatchCheckRequest{Checks: []*otterv1.CheckRequest{
+			{Subject: p1, Action: "READ", Resource: "Resource1"},
+			{Subject: p1, Action: "FLY", Resource: "Resource1"},
+			{Subject: p1, Action: "READ", Resource: "Resource3"},
+		}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		results := resp.GetResults()
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, but got %d", len(results))
+		}
+		if !results[0].GetCan() || results[0].GetError() != "" {
+			t.Errorf("Expected first check to be allowed, got %v", results[0])
+		}
+		if results[1].GetError() == "" {
+			t.Errorf("Expected second check to fail, got %v", results[1])
+		}
+		if results[2].GetCan() || results[2].GetError() != "" {
+			t.Errorf("Expected third check to be denied, got %v", results[2])
+		}
+	})
+
+	t.Run("LookupSubjects", func(t *testing.T) {
+		req := &otterv1.LookupSubjectsRequest{SubjectType: "Group", Action: "READ", Resource: "Resource2"}
+		expected := []string{"Group1", "Group2"}
+
+		resp, err := client.LookupSubjects(ctx, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names := []string{}
+		for _, s := range resp.GetSubjects() {
+			names = append(names, s.GetName())
+		}
+		slices.Sort(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected %v, but got %v", expected, names)
+		}
+
+		stream, err := client.StreamLookupSubjects(ctx, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		streamed := []string{}
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected stream error: %v", err)
+			}
+			streamed = append(streamed, msg.GetSubject().GetName())
+		}
+		slices.Sort(streamed)
+		if !reflect.DeepEqual(streamed, expected) {
+			t.Errorf("Expected streamed %v, but got %v", expected, streamed)
+		}
+	})
+
+	t.Run("LookupResources", func(t *testing.T) {
+		req := &otterv1.LookupResourcesRequest{Subject: p3, Action: "READ", Under: "Resource3", Specifiers: map[string]string{"Role": "admin"}}
+		expected := []string{"Resource3", "Resource4"}
+
+		resp, err := client.LookupResources(ctx, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resources := resp.GetResources()
+		slices.Sort(resources)
+		if !reflect.DeepEqual(resources, expected) {
+			t.Errorf("Expected %v, but got %v", expected, resources)
+		}
+
+		stream, err := client.StreamLookupResources(ctx, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		streamed := []string{}
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected stream error: %v", err)
+			}
+			streamed = append(streamed, msg.GetResource())
+		}
+		slices.Sort(streamed)
+		if !reflect.DeepEqual(streamed, expected) {
+			t.Errorf("Expected streamed %v, but got %v", expected, streamed)
+		}
+	})
+
+	t.Run("ExpandSpecifiers", func(t *testing.T) {
+		resp, err := client.ExpandSpecifiers(ctx, &otterv1.ExpandSpecifiersRequest{
+			Subject:    &otterv1.Subject{Name: "Principal2"},
+			Action:     "READ",
+			Resource:   "Resource3",
+			Specifiers: map[string]string{"Env": "prod"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		roles := []string{}
+		for _, sg := range resp.GetSpecifierGroups() {
+			roles = append(roles, sg.GetSpecifiers()["Role"])
+		}
+		slices.Sort(roles)
+		expected := []string{"admin", "user"}
+		if !reflect.DeepEqual(roles, expected) {
+			t.Errorf("Expected %v, but got %v", expected, roles)
+		}
+	})
+}
+
+func TestGRPCWithoutStore(t *testing.T) {
+	ctx := t.Context()
+	db.SetInstance(nil)
+
+	client := setupGRPCClient(t)
+	p1 := &otterv1.Subject{Name: "Principal1"}
+
+	// Custom actions are looked up in the store, whose failure is not the fault of the client
+	calls := map[string]func() error{
+		"Check": func() error {
+			_, err := client.Check(ctx, &otterv1.CheckRequest{Subject: p1, Action: "DEPLOY", Resource: "Resource1"})
+			return err
+		},
+		"LookupSubjects": func() error {
+			_, err := client.LookupSubjects(ctx, &otterv1.LookupSubjectsRequest{Action: "DEPLOY", Resource: "Resource1"})
+			return err
+		},
+		"LookupResources": func() error {
+			_, err := client.LookupResources(ctx, &otterv1.LookupResourcesRequest{Subject: p1, Action: "DEPLOY"})
+			return err
+		},
+		"ExpandSpecifiers": func() error {
+			_, err := client.ExpandSpecifiers(ctx, &otterv1.ExpandSpecifiersRequest{Subject: p1, Action: "DEPLOY", Resource: "Resource1"})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); status.Code(err) != codes.Unavailable {
+			t.Errorf("For %s, expected Unavailable, but got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/subject"
+)
+
+var ErrPolicyNotFound = db.NewError(db.ErrNotFound, "policy not found")
+
+func handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body policyBody
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := body.toPolicy(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	p.Id = ""
+
+	created, err := p.Create(ctx)
+	if errors.Is(err, policy.ErrPolicyNotCreated) {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, fromPolicy(created))
+}
+
+// handleGetPolicies lists policies, filtered by the `subject`, `resource`, `action`, `effect`
+// and repeated `with=key=value` query parameters.
+func handleGetPolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params := r.URL.Query()
+	filter := policy.Policy{}
+
+	if name := params.Get("subject"); name != "" {
+		filter.Subject = subject.Subject{Name: name}
+	}
+
+	if name := params.Get("resource"); name != "" {
+		filter.Resource = resource.NewResource(name)
+	}
+
+	if actionStr := params.Get("action"); actionStr != "" {
+		a, err := action.FromString(ctx, actionStr)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		filter.Action = a
+	}
+
+	if effectStr := params.Get("effect"); effectStr != "" {
+		effect, err := policy.EffectFromString(effectStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.Effect = effect
+	}
+
+	specifiers := map[string]string{}
+	for _, pair := range params["with"] {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid specifier %q, expected key=value", pair))
+			return
+		}
+		specifiers[k] = v
+	}
+	filter.Specifiers = toSpecifierGroup(specifiers)
+
+	policies, err := filter.Get(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	response := policiesResponse{Policies: make([]policyBody, 0, len(policies))}
+	for _, p := range policies {
+		response.Policies = append(response.Policies, fromPolicy(p))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getPolicy fetches the policy from the `id` path value, writing the error response if it fails.
+func getPolicy(w http.ResponseWriter, r *http.Request) (policy.Policy, bool) {
+	ctx := r.Context()
+
+	p, err := policy.Policy{Id: r.PathValue("id")}.GetById(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return policy.Policy{}, false
+	}
+	if p.Id == "" {
+		writeError(w, http.StatusNotFound, ErrPolicyNotFound)
+		return policy.Policy{}, false
+	}
+	return p, true
+}
+
+func handleGetPolicyById(w http.ResponseWriter, r *http.Request) {
+	p, ok := getPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fromPolicy(p))
+}
+
+func handleUpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body policyBody
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	newPolicy, err := body.toPolicy(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	newPolicy.Id = ""
+
+	existing, ok := getPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := existing.Update(ctx, newPolicy)
+	if errors.Is(err, policy.ErrPolicyNotCreated) {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fromPolicy(updated))
+}
+
+func handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	existing, ok := getPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	if err := existing.Delete(ctx); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
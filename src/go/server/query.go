@@ -0,0 +1,191 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+)
+
+func handleCan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req canRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	qb, err := req.toQuery(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	result := qb.Query(ctx)
+	if result.Err != nil {
+		writeError(w, errorStatus(result.Err), result.Err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, canResponse{Can: result.Can})
+}
+
+// handleCanBatch evaluates every check in a single store round trip.
+// Invalid checks get their own error, and do not fail the request.
+func handleCanBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req canBatchRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	queries := make([]query.CanQueryBuilder, len(req.Checks))
+	checkErrors := make([]error, len(req.Checks))
+	for i, check := range req.Checks {
+		queries[i], checkErrors[i] = check.toQuery(ctx)
+	}
+
+	results := query.CanBatch(ctx, queries)
+
+	response := canBatchResponse{Results: make([]canBatchResult, 0, len(results))}
+	for i, result := range results {
+		switch {
+		case checkErrors[i] != nil:
+			response.Results = append(response.Results, canBatchResult{Error: checkErrors[i].Error()})
+		case result.Err != nil:
+			response.Results = append(response.Results, canBatchResult{Error: result.Err.Error()})
+		default:
+			response.Results = append(response.Results, canBatchResult{Can: result.Can})
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func handleWhoCan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req whoCanRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subjectType, err := parseSubjectType(req.SubjectType)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a, err := action.FromString(ctx, req.Action)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	qb, err := query.WhoCan(subjectType).Perform(a).On(resource.NewResource(req.Resource)).With(toSpecifierGroup(req.Specifiers)).Validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subjects, err := qb.Query(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	response := whoCanResponse{Subjects: make([]subjectBody, 0, len(subjects))}
+	for _, s := range subjects {
+		response.Subjects = append(response.Subjects, fromSubject(s))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func handleWhatCan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req whatCanRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s, err := req.Subject.toSubject()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a, err := action.FromString(ctx, req.Action)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	under := resource.Resource{}
+	if req.Under != "" {
+		under = resource.NewResource(req.Under)
+	}
+
+	qb, err := query.WhatCan(s).Perform(a).Under(under).With(toSpecifierGroup(req.Specifiers)).Validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resources, err := qb.Query(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	response := whatCanResponse{Resources: make([]string, 0, len(resources))}
+	for _, res := range resources {
+		response.Resources = append(response.Resources, res.Name)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func handleHowCan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req howCanRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s, err := req.Subject.toSubject()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a, err := action.FromString(ctx, req.Action)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	qb, err := query.HowCan(s).Perform(a).On(resource.NewResource(req.Resource)).With(toSpecifierGroup(req.Specifiers)).Validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	specifierGroups, err := qb.Query(ctx)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	response := howCanResponse{SpecifierGroups: make([]map[string]string, 0, len(specifierGroups))}
+	for _, sg := range specifierGroups {
+		response.SpecifierGroups = append(response.SpecifierGroups, sg.AsMap())
+	}
+	writeJSON(w, http.StatusOK, response)
+}
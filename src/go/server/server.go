@@ -0,0 +1,73 @@
+// Package server exposes the authorization queries and policy management over a JSON HTTP API.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/namsnath/otter/db"
+)
+
+var ErrInvalidBody = db.NewError(db.ErrInvalidInput, "invalid request body")
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the handler serving the versioned REST API.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/can", handleCan)
+	mux.HandleFunc("POST /v1/can/batch", handleCanBatch)
+	mux.HandleFunc("POST /v1/who-can", handleWhoCan)
+	mux.HandleFunc("POST /v1/what-can", handleWhatCan)
+	mux.HandleFunc("POST /v1/how-can", handleHowCan)
+
+	mux.HandleFunc("POST /v1/policies", handleCreatePolicy)
+	mux.HandleFunc("GET /v1/policies", handleGetPolicies)
+	mux.HandleFunc("GET /v1/policies/{id}", handleGetPolicyById)
+	mux.HandleFunc("PUT /v1/policies/{id}", handleUpdatePolicy)
+	mux.HandleFunc("DELETE /v1/policies/{id}", handleDeletePolicy)
+
+	return mux
+}
+
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return errors.Join(ErrInvalidBody, err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Error writing response", "error", err)
+	}
+}
+
+// errorStatus returns the HTTP status of an error of the entities, queries or store, from its kind.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrBackendUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
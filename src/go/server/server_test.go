@@ -0,0 +1,181 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/server"
+)
+
+func TestQueryEndpoints(t *testing.T) {
+	ctx := t.Context()
+	db.SetupMemoryInstance()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	handler := server.NewHandler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expected       string
+	}{
+		{"can: p1 READ r1", "POST", "/v1/can", `{"subject": {"name": "Principal1"}, "action": "READ", "resource": "Resource1"}`, 200, `{"can": true}`},
+		{"can: p1 READ r3", "POST", "/v1/can", `{"subject": {"name": "Principal1", "type": "Principal"}, "action": "READ", "resource": "Resource3"}`, 200, `{"can": false}`},
+		{"can: p2 READ r3 in prod as admin", "POST", "/v1/can", `{"subject": {"name": "Principal2"}, "action": "READ", "resource": "Resource3", "specifiers": {"Env": "prod", "Role": "admin"}}`, 200, `{"can": true}`},
+		{"can batch: results in input order", "POST", "/v1/can/batch", `{"checks": [{"subject": {"name": "Principal1"}, "action": "READ", "resource": "Resource1"}, {"subject": {"name": "Principal1"}, "action": "FLY", "resource": "Resource1"}, {"subject": {"name": "Principal1"}, "action": "READ", "resource": "Resource3"}]}`, 200, `{"results": [{"can": true}, {"can": false, "error": "invalid Action"}, {"can": false}]}`},
+		{"can: invalid action", "POST", "/v1/can", `{"subject": {"name": "Principal1"}, "action": "FLY", "resource": "Resource1"}`, 400, `{"error": "invalid Action"}`},
+		{"can: missing resource", "POST", "/v1/can", `{"subject": {"name": "Principal1"}, "action": "READ"}`, 400, `{"error": "incomplete Can query: subject, action, and resource must be set"}`},
+		{"can: invalid subject type", "POST", "/v1/can", `{"subject": {"name": "Principal1", "type": "Robot"}, "action": "READ", "resource": "Resource1"}`, 400, `{"error": "invalid SubjectType"}`},
+		{"who-can: Principals READ r1", "POST", "/v1/who-can", `{"action": "READ", "resource": "Resource1"}`, 200, `{"subjects": [{"name": "Principal1", "type": "Principal"}, {"name": "Principal2", "type": "Principal"}]}`},
+		{"who-can: Groups READ r2", "POST", "/v1/who-can", `{"subjectType": "Group", "action": "READ", "resource": "Resource2"}`, 200, `{"subjects": [{"name": "Group1", "type": "Group"}, {"name": "Group2", "type": "Group"}]}`},
+		{"what-can: p3 READ as admin UNDER r3", "POST", "/v1/what-can", `{"subject": {"name": "Principal3"}, "action": "READ", "under": "Resource3", "specifiers": {"Role": "admin"}}`, 200, `{"resources": ["Resource3", "Resource4"]}`},
+		{"what-can: p3 READ", "POST", "/v1/what-can", `{"subject": {"name": "Principal3"}, "action": "READ"}`, 200, `{"resources": []}`},
+		{"how-can: p2 READ r3 in prod", "POST", "/v1/how-can", `{"subject": {"name": "Principal2"}, "action": "READ", "resource": "Resource3", "specifiers": {"Env": "prod"}}`, 200, `{"specifierGroups": [{"Role": "admin"}, {"Role": "user"}]}`},
+		{"malformed body", "POST", "/v1/can", `{"subject": `, 400, ``},
+		{"unknown field", "POST", "/v1/can", `{"principal": "Principal1"}`, 400, ``},
+		{"wrong method", "GET", "/v1/can", ``, 405, ``},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doRequest(handler, tc.method, tc.path, tc.body)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("For %s, expected status %d, but got %d: %s", tc.name, tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tc.expected != "" {
+				assertJSONEqual(t, tc.expected, rec.Body.String(), sortHowCanGroups)
+			}
+		})
+	}
+}
+
+func TestPolicyEndpoints(t *testing.T) {
+	ctx := t.Context()
+	db.SetupMemoryInstance()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	handler := server.NewHandler()
+
+	rec := doRequest(handler, "POST", "/v1/policies", `{"subject": {"name": "Principal3"}, "resource": "Resource2", "action": "WRITE", "specifiers": {"Env": "dev"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 on create, but got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	id, _ := created["id"].(string)
+	if id == "" {
+		t.Fatalf("Expected created policy to have an id, got %s", rec.Body.String())
+	}
+
+	rec = doRequest(handler, "GET", "/v1/policies/"+id, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 on get, but got %d: %s", rec.Code, rec.Body.String())
+	}
+	assertJSONEqual(t, `{"id": "`+id+`", "subject": {"name": "Principal3", "type": "Principal"}, "resource": "Resource2", "action": "WRITE", "effect": "ALLOW", "effectiveActions": ["WRITE"], "specifiers": {"Env": "dev", "Role": "*"}}`, rec.Body.String(), nil)
+
+	rec = doRequest(handler, "GET", "/v1/policies?action=WRITE&with=Env=dev", "")
+	assertJSONEqual(t, `{"policies": [{"id": "`+id+`", "subject": {"name": "Principal3", "type": "Principal"}, "resource": "Resource2", "action": "WRITE", "effect": "ALLOW", "effectiveActions": ["WRITE"], "specifiers": {"Env": "dev", "Role": "*"}}]}`, rec.Body.String(), nil)
+
+	rec = doRequest(handler, "PUT", "/v1/policies/"+id, `{"subject": {"name": "Principal3"}, "resource": "Resource2", "action": "WRITE", "specifiers": {"Env": "prod"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 on update, but got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &updated)
+	updatedId, _ := updated["id"].(string)
+
+	rec = doRequest(handler, "POST", "/v1/can", `{"subject": {"name": "Principal3"}, "action": "WRITE", "resource": "Resource2", "specifiers": {"Env": "prod"}}`)
+	assertJSONEqual(t, `{"can": true}`, rec.Body.String(), nil)
+
+	rec = doRequest(handler, "PUT", "/v1/policies/"+updatedId, `{"subject": {"name": "Nobody"}, "resource": "Resource2", "action": "WRITE"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 on update with unknown subject, but got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(handler, "DELETE", "/v1/policies/"+updatedId, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 on delete, but got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(handler, "GET", "/v1/policies/"+updatedId, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 after delete, but got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(handler, "POST", "/v1/policies", `{"subject": {"name": "Nobody"}, "resource": "Resource2", "action": "READ"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 on create with unknown subject, but got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestEndpointsWithoutStore(t *testing.T) {
+	db.SetInstance(nil)
+	handler := server.NewHandler()
+
+	// Custom actions are looked up in the store, whose failure is not the fault of the client
+	for _, tc := range []struct{ method, path, body string }{
+		{"POST", "/v1/can", `{"subject": {"name": "Principal1"}, "action": "DEPLOY", "resource": "Resource1"}`},
+		{"POST", "/v1/who-can", `{"action": "DEPLOY", "resource": "Resource1"}`},
+		{"POST", "/v1/what-can", `{"subject": {"name": "Principal1"}, "action": "DEPLOY"}`},
+		{"POST", "/v1/how-can", `{"subject": {"name": "Principal1"}, "action": "DEPLOY", "resource": "Resource1"}`},
+		{"POST", "/v1/policies", `{"subject": {"name": "Principal1"}, "resource": "Resource1", "action": "DEPLOY"}`},
+		{"GET", "/v1/policies?action=DEPLOY", ``},
+	} {
+		if rec := doRequest(handler, tc.method, tc.path, tc.body); rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("For %s %s, expected status 503, but got %d: %s", tc.method, tc.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func doRequest(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// sortHowCanGroups orders the unordered specifier groups of a HowCan response.
+func sortHowCanGroups(v map[string]any) {
+	groups, ok := v["specifierGroups"].([]any)
+	if !ok {
+		return
+	}
+	slices.SortFunc(groups, func(a, b any) int {
+		aJSON, _ := json.Marshal(a)
+		bJSON, _ := json.Marshal(b)
+		return strings.Compare(string(aJSON), string(bJSON))
+	})
+}
+
+func assertJSONEqual(t *testing.T, expected, actual string, normalize func(map[string]any)) {
+	t.Helper()
+
+	var expectedVal, actualVal map[string]any
+	if err := json.Unmarshal([]byte(expected), &expectedVal); err != nil {
+		t.Fatalf("Invalid expected JSON %s: %v", expected, err)
+	}
+	if err := json.Unmarshal([]byte(actual), &actualVal); err != nil {
+		t.Fatalf("Invalid response JSON %s: %v", actual, err)
+	}
+	if normalize != nil {
+		normalize(expectedVal)
+		normalize(actualVal)
+	}
+
+	if !reflect.DeepEqual(expectedVal, actualVal) {
+		t.Errorf("Expected %v, but got %v", expectedVal, actualVal)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+type subjectBody struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+type policyBody struct {
+	Id         string            `json:"id,omitempty"`
+	Subject    subjectBody       `json:"subject"`
+	Resource   string            `json:"resource"`
+	Action     string            `json:"action"`
+	Effect     string            `json:"effect,omitempty"`
+	Specifiers map[string]string `json:"specifiers,omitempty"`
+	// EffectiveActions is only set in responses.
+	EffectiveActions []string `json:"effectiveActions,omitempty"`
+}
+
+type canRequest struct {
+	Subject    subjectBody       `json:"subject"`
+	Action     string            `json:"action"`
+	Resource   string            `json:"resource"`
+	Specifiers map[string]string `json:"specifiers,omitempty"`
+}
+
+type canResponse struct {
+	Can bool `json:"can"`
+}
+
+type canBatchRequest struct {
+	Checks []canRequest `json:"checks"`
+}
+
+type canBatchResult struct {
+	Can   bool   `json:"can"`
+	Error string `json:"error,omitempty"`
+}
+
+type canBatchResponse struct {
+	Results []canBatchResult `json:"results"`
+}
+
+type whoCanRequest struct {
+	SubjectType string            `json:"subjectType,omitempty"`
+	Action      string            `json:"action"`
+	Resource    string            `json:"resource"`
+	Specifiers  map[string]string `json:"specifiers,omitempty"`
+}
+
+type whoCanResponse struct {
+	Subjects []subjectBody `json:"subjects"`
+}
+
+type whatCanRequest struct {
+	Subject    subjectBody       `json:"subject"`
+	Action     string            `json:"action"`
+	Under      string            `json:"under,omitempty"`
+	Specifiers map[string]string `json:"specifiers,omitempty"`
+}
+
+type whatCanResponse struct {
+	Resources []string `json:"resources"`
+}
+
+type howCanRequest struct {
+	Subject    subjectBody       `json:"subject"`
+	Action     string            `json:"action"`
+	Resource   string            `json:"resource"`
+	Specifiers map[string]string `json:"specifiers,omitempty"`
+}
+
+type howCanResponse struct {
+	SpecifierGroups []map[string]string `json:"specifierGroups"`
+}
+
+type policiesResponse struct {
+	Policies []policyBody `json:"policies"`
+}
+
+// parseSubjectType converts the string to a SubjectType, defaulting to a Principal when empty.
+func parseSubjectType(s string) (subject.SubjectType, error) {
+	if s == "" {
+		return subject.SubjectTypePrincipal, nil
+	}
+	return subject.SubjectTypeFromString(s)
+}
+
+func (body subjectBody) toSubject() (subject.Subject, error) {
+	subjectType, err := parseSubjectType(body.Type)
+	if err != nil {
+		return subject.Subject{}, err
+	}
+	return subject.Subject{Name: body.Name, Type: subjectType}, nil
+}
+
+func fromSubject(s subject.Subject) subjectBody {
+	return subjectBody{Name: s.Name, Type: string(s.Type)}
+}
+
+func toSpecifierGroup(specifiers map[string]string) specifier.SpecifierGroup {
+	group := specifier.SpecifierGroup{Specifiers: []specifier.Specifier{}}
+	for k, v := range specifiers {
+		group.Specifiers = append(group.Specifiers, specifier.NewSpecifier(k, v))
+	}
+	return group
+}
+
+func (req canRequest) toQuery(ctx context.Context) (query.CanQueryBuilder, error) {
+	s, err := req.Subject.toSubject()
+	if err != nil {
+		return query.CanQueryBuilder{}, err
+	}
+
+	a, err := action.FromString(ctx, req.Action)
+	if err != nil {
+		return query.CanQueryBuilder{}, err
+	}
+
+	return query.Can(s).Perform(a).On(resource.NewResource(req.Resource)).With(toSpecifierGroup(req.Specifiers)).Validate()
+}
+
+func (body policyBody) toPolicy(ctx context.Context) (policy.Policy, error) {
+	policySubject, err := body.Subject.toSubject()
+	if err != nil {
+		return policy.Policy{}, err
+	}
+
+	policyAction, err := action.FromString(ctx, body.Action)
+	if err != nil {
+		return policy.Policy{}, err
+	}
+
+	policyEffect, err := policy.EffectFromString(body.Effect)
+	if err != nil {
+		return policy.Policy{}, err
+	}
+
+	return policy.Policy{
+		Id:         body.Id,
+		Subject:    policySubject,
+		Resource:   resource.NewResource(body.Resource),
+		Action:     policyAction,
+		Effect:     policyEffect,
+		Specifiers: toSpecifierGroup(body.Specifiers),
+	}, nil
+}
+
+func fromPolicy(p policy.Policy) policyBody {
+	return policyBody{
+		Id:               p.Id,
+		Subject:          fromSubject(p.Subject),
+		Resource:         p.Resource.Name,
+		Action:           string(p.Action),
+		Effect:           string(p.Effect),
+		Specifiers:       p.Specifiers.AsMap(),
+		EffectiveActions: fromActions(p.EffectiveActions),
+	}
+}
+
+func fromActions(actions []action.Action) []string {
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		names = append(names, string(a))
+	}
+	return names
+}
@@ -6,8 +6,8 @@ type Spec interface {
 }
 
 type Specifier struct {
-	Key   string
-	Value string
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
 }
 
 func NewSpecifier(key string, value string) Specifier {
@@ -1,44 +1,228 @@
 package specifier
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/namsnath/otter/db"
 )
 
-func (s Specifier) Create() Specifier {
-	db.ExecuteQuery(
-		"CREATE (r:Specifier {key: $key, value: $value})",
-		map[string]any{
-			"key":   s.Key,
-			"value": s.Value,
-		},
-	)
+var ErrSpecifierNotFound = db.NewError(db.ErrNotFound, "specifier not found")
+var ErrSpecifierExists = db.NewError(db.ErrConflict, "specifier already exists")
+var ErrSpecifierInUse = db.NewError(db.ErrConflict, "specifier is used by policies or has children")
 
-	return s
+// Record returns the storage representation of the specifier.
+func (s Specifier) Record() db.SpecifierRecord {
+	return db.SpecifierRecord{Key: s.Key, Value: s.Value}
 }
 
-func (s Specifier) CreateAsChildOf(parent Specifier) (Specifier, error) {
+// Create stores the specifier, failing with ErrSpecifierExists if it already exists, and with
+// ErrUnknownSpecifierValue if its key is registered and does not accept the value.
+func (s Specifier) Create(ctx context.Context) (Specifier, error) {
+	if err := s.checkValue(ctx); err != nil {
+		return Specifier{}, err
+	}
+
+	err := db.FromContext(ctx).CreateSpecifier(ctx, s.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Specifier{}, ErrSpecifierExists
+	}
+	if err != nil {
+		return Specifier{}, err
+	}
+
+	return s, nil
+}
+
+// CreateAsChildOf creates the specifier under the parent, failing with ErrSpecifierNotFound, and
+// creating nothing, if the parent does not exist.
+func (s Specifier) CreateAsChildOf(ctx context.Context, parent Specifier) (Specifier, error) {
 	if s.Key != parent.Key && parent.Key != "*" {
-		return Specifier{}, fmt.Errorf("cannot create child specifier with different key except under `*`: %s vs %s", s.Key, parent.Key)
+		return Specifier{}, db.Errorf(db.ErrInvalidInput, "cannot create child specifier with different key except under `*`: %s vs %s", s.Key, parent.Key)
 	}
 	if parent.Key == "*" && s.Key == "*" {
-		return Specifier{}, fmt.Errorf("cannot create child specifier with key `*` under another `*`. This is a special root node")
-	}
-
-	db.ExecuteQuery(`
-		CREATE (s:Specifier {key: $key, value: $value})
-		WITH s
-		MATCH (p:Specifier {key: $parentKey, value: $parentValue})
-		CREATE (s)-[:CHILD_OF]->(p)
-		`,
-		map[string]any{
-			"key":         s.Key,
-			"value":       s.Value,
-			"parentKey":   parent.Key,
-			"parentValue": parent.Value,
-		},
-	)
+		return Specifier{}, db.NewError(db.ErrInvalidInput, "cannot create child specifier with key `*` under another `*`. This is a special root node")
+	}
+	if err := s.checkValue(ctx); err != nil {
+		return Specifier{}, err
+	}
+	if err := s.checkParent(ctx, parent); err != nil {
+		return Specifier{}, err
+	}
+
+	err := db.FromContext(ctx).CreateSpecifierAsChildOf(ctx, s.Record(), parent.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Specifier{}, ErrSpecifierExists
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return Specifier{}, fmt.Errorf("parent %s=%s: %w", parent.Key, parent.Value, ErrSpecifierNotFound)
+	}
+	if err != nil {
+		return Specifier{}, err
+	}
 
 	return s, nil
 }
+
+// GetOrCreate returns the specifier if it exists, and creates it otherwise.
+func (s Specifier) GetOrCreate(ctx context.Context) (Specifier, error) {
+	return s.getOrCreate(ctx, func(ctx context.Context) (Specifier, error) {
+		return s.Create(ctx)
+	})
+}
+
+// GetOrCreateAsChildOf returns the specifier if it exists, and creates it under the parent otherwise.
+// The parents of an existing specifier are left unchanged.
+func (s Specifier) GetOrCreateAsChildOf(ctx context.Context, parent Specifier) (Specifier, error) {
+	return s.getOrCreate(ctx, func(ctx context.Context) (Specifier, error) {
+		return s.CreateAsChildOf(ctx, parent)
+	})
+}
+
+func (s Specifier) getOrCreate(ctx context.Context, create func(ctx context.Context) (Specifier, error)) (Specifier, error) {
+	var result Specifier
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		existing, err := Get(ctx, s.Key, s.Value)
+		if errors.Is(err, ErrSpecifierNotFound) {
+			result, err = create(ctx)
+			return err
+		}
+		result = existing
+		return err
+	})
+	if err != nil {
+		return Specifier{}, err
+	}
+
+	return result, nil
+}
+
+// Get returns the specifier with the key and value.
+func Get(ctx context.Context, key string, value string) (Specifier, error) {
+	_, found, err := db.FromContext(ctx).GetSpecifier(ctx, db.SpecifierRecord{Key: key, Value: value})
+	if err != nil {
+		return Specifier{}, err
+	}
+	if !found {
+		return Specifier{}, ErrSpecifierNotFound
+	}
+	return NewSpecifier(key, value), nil
+}
+
+// List returns the specifiers of the key sorted by key and value, or all of them if the key is empty.
+func List(ctx context.Context, key string) ([]Specifier, error) {
+	nodes, err := db.FromContext(ctx).GetSpecifiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	specifiers := []Specifier{}
+	for _, node := range nodes {
+		if key == "" || node.Specifier.Key == key {
+			specifiers = append(specifiers, NewSpecifier(node.Specifier.Key, node.Specifier.Value))
+		}
+	}
+	return specifiers, nil
+}
+
+// Parents returns the direct parents of the specifier, sorted by key and value.
+func (s Specifier) Parents(ctx context.Context) ([]Specifier, error) {
+	node, found, err := db.FromContext(ctx).GetSpecifier(ctx, s.Record())
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrSpecifierNotFound
+	}
+
+	parents := make([]Specifier, 0, len(node.Parents))
+	for _, parent := range node.Parents {
+		parents = append(parents, NewSpecifier(parent.Key, parent.Value))
+	}
+	return parents, nil
+}
+
+// Children returns the specifiers the specifier is a direct parent of, sorted by key and value.
+func (s Specifier) Children(ctx context.Context) ([]Specifier, error) {
+	if _, err := Get(ctx, s.Key, s.Value); err != nil {
+		return nil, err
+	}
+
+	nodes, err := db.FromContext(ctx).GetSpecifiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Specifier{}
+	for _, node := range nodes {
+		if slices.Contains(node.Parents, s.Record()) {
+			children = append(children, NewSpecifier(node.Specifier.Key, node.Specifier.Value))
+		}
+	}
+	return children, nil
+}
+
+// Delete removes the specifier. A policy never outlives a specifier it points at, since it would grant
+// something else without it. The mode selects what happens to those policies and to the children:
+// db.DeleteRefuse fails with ErrSpecifierInUse if there are any, db.DeleteCascade deletes the
+// children as well, with every policy pointing at one of the deleted specifiers, and db.DeleteReparent
+// deletes the policies pointing at the specifier and gives its children its parents instead.
+// Children are not detached, since policies on their ancestors would stop covering them.
+func (s Specifier) Delete(ctx context.Context, mode db.DeleteMode) error {
+	return db.InTx(ctx, func(ctx context.Context) error {
+		parents, err := s.Parents(ctx)
+		if err != nil {
+			return err
+		}
+		children, err := s.Children(ctx)
+		if err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		switch mode {
+		case db.DeleteRefuse:
+			used, err := s.usedByPolicies(ctx)
+			if err != nil {
+				return err
+			}
+			if used || len(children) > 0 {
+				return ErrSpecifierInUse
+			}
+		case db.DeleteCascade:
+			for _, child := range children {
+				if err := child.Delete(ctx, db.DeleteCascade); err != nil && !errors.Is(err, ErrSpecifierNotFound) {
+					return err
+				}
+			}
+		case db.DeleteReparent:
+			for _, child := range children {
+				for _, parent := range parents {
+					if err := store.AddSpecifierParent(ctx, child.Record(), parent.Record()); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			return db.ErrInvalidDeleteMode
+		}
+
+		return store.DeleteSpecifier(ctx, s.Record())
+	})
+}
+
+// usedByPolicies reports whether a policy points at the specifier.
+func (s Specifier) usedByPolicies(ctx context.Context) (bool, error) {
+	policies, err := db.FromContext(ctx).GetPolicies(ctx, db.PolicyFilter{})
+	if err != nil {
+		return false, err
+	}
+	for _, policy := range policies {
+		if slices.Contains(policy.Specifiers, s.Record()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
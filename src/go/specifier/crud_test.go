@@ -0,0 +1,111 @@
+package specifier_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/specifier"
+)
+
+func TestSpecifierDelete(t *testing.T) {
+	db.TestContainer(t)
+
+	testSpecifierDelete(t)
+}
+
+func TestSpecifierDeleteInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testSpecifierDelete(t)
+}
+
+func testSpecifierDelete(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	roleRoot := specifier.NewSpecifier("Role", "*")
+	roleAdmin := specifier.NewSpecifier("Role", "admin")
+	roleUser := specifier.NewSpecifier("Role", "user")
+	envRoot := specifier.NewSpecifier("Env", "*")
+	envProd := specifier.NewSpecifier("Env", "prod")
+
+	policiesOn := func(s specifier.Specifier) []db.PolicyRecord {
+		policies, err := db.GetInstance().GetPolicies(ctx, db.PolicyFilter{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		result := []db.PolicyRecord{}
+		for _, policy := range policies {
+			for _, record := range policy.Specifiers {
+				if record == s.Record() {
+					result = append(result, policy)
+				}
+			}
+		}
+		return result
+	}
+
+	t.Run("Missing parent", func(t *testing.T) {
+		if _, err := specifier.NewSpecifier("Role", "guest").CreateAsChildOf(ctx, specifier.NewSpecifier("Role", "visitor")); !errors.Is(err, specifier.ErrSpecifierNotFound) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierNotFound, err)
+		}
+		if _, err := specifier.Get(ctx, "Role", "guest"); !errors.Is(err, specifier.ErrSpecifierNotFound) {
+			t.Errorf("Expected nothing to be created, got %v", err)
+		}
+	})
+
+	t.Run("Refuse", func(t *testing.T) {
+		if err := envProd.Delete(ctx, db.DeleteRefuse); !errors.Is(err, specifier.ErrSpecifierInUse) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierInUse, err)
+		}
+		if err := roleAdmin.Delete(ctx, db.DeleteRefuse); !errors.Is(err, specifier.ErrSpecifierInUse) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierInUse, err)
+		}
+		if err := envProd.Delete(ctx, db.DeleteMode("orphan")); !errors.Is(err, db.ErrInvalidDeleteMode) {
+			t.Errorf("Expected %v, got %v", db.ErrInvalidDeleteMode, err)
+		}
+		if policies := policiesOn(envProd); len(policies) != 2 {
+			t.Errorf("Expected the policies on Env=prod to be kept, got %v", policies)
+		}
+	})
+
+	t.Run("Reparent", func(t *testing.T) {
+		// Role=user is left under Role=*, and the policies requiring Role=admin can no longer be kept
+		if err := roleAdmin.Delete(ctx, db.DeleteReparent); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if parents, _ := roleUser.Parents(ctx); !reflect.DeepEqual(parents, []specifier.Specifier{roleRoot}) {
+			t.Errorf("Expected %v, got %v", []specifier.Specifier{roleRoot}, parents)
+		}
+		if policies := policiesOn(roleAdmin); len(policies) != 0 {
+			t.Errorf("Expected the policies on Role=admin to be deleted, got %v", policies)
+		}
+		if _, err := specifier.Get(ctx, "Role", "admin"); !errors.Is(err, specifier.ErrSpecifierNotFound) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierNotFound, err)
+		}
+
+		if err := roleUser.Delete(ctx, db.DeleteRefuse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		if err := envRoot.Delete(ctx, db.DeleteCascade); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, value := range []string{"*", "prod", "dev"} {
+			if _, err := specifier.Get(ctx, "Env", value); !errors.Is(err, specifier.ErrSpecifierNotFound) {
+				t.Errorf("Expected Env=%s to be deleted, got %v", value, err)
+			}
+		}
+		if policies := policiesOn(envProd); len(policies) != 0 {
+			t.Errorf("Expected the policies on Env=prod to be deleted, got %v", policies)
+		}
+		if err := envRoot.Delete(ctx, db.DeleteCascade); !errors.Is(err, specifier.ErrSpecifierNotFound) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierNotFound, err)
+		}
+	})
+}
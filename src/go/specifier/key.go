@@ -0,0 +1,374 @@
+package specifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
+
+// SpecifierKey declares a specifier key. Values lists the values the key accepts, any value
+// being accepted when empty. Queries that do not pass the key use Default, if set, instead of
+// the `*` wildcard, and fail if the key is Required and has no default.
+//
+// The specifiers of a typed key are constraints of its Type, which the values passed by queries
+// are tested against. Such keys take no Values, and their Default is a value of the type.
+type SpecifierKey struct {
+	Name        string        `json:"name" yaml:"name"`
+	Type        SpecifierType `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Values      []string      `json:"values,omitempty" yaml:"values,omitempty"`
+	Default     string        `json:"default,omitempty" yaml:"default,omitempty"`
+	Required    bool          `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+var ErrSpecifierKeyNotFound = db.NewError(db.ErrNotFound, "specifier key not found")
+var ErrSpecifierKeyExists = db.NewError(db.ErrConflict, "specifier key already exists")
+var ErrInvalidSpecifierKey = db.NewError(db.ErrInvalidInput, "invalid specifier key")
+var ErrUnknownSpecifierKey = db.NewError(db.ErrInvalidInput, "unknown specifier key")
+var ErrUnknownSpecifierValue = db.NewError(db.ErrInvalidInput, "unknown specifier value")
+var ErrMissingSpecifier = db.NewError(db.ErrInvalidInput, "missing required specifier")
+
+// Record returns the storage representation of the specifier key.
+func (k SpecifierKey) Record() db.SpecifierKeyRecord {
+	return db.SpecifierKeyRecord{
+		Name:        k.Name,
+		Type:        string(k.Type),
+		Description: k.Description,
+		Values:      slices.Clone(k.Values),
+		Default:     k.Default,
+		Required:    k.Required,
+	}
+}
+
+func specifierKeyFromRecord(record db.SpecifierKeyRecord) SpecifierKey {
+	return SpecifierKey{
+		Name:        record.Name,
+		Type:        SpecifierType(record.Type),
+		Description: record.Description,
+		Values:      record.Values,
+		Default:     record.Default,
+		Required:    record.Required,
+	}
+}
+
+// Allows reports whether the key accepts the value as a specifier, which for a typed key is a
+// constraint. The `*` wildcard is always accepted.
+func (k SpecifierKey) Allows(value string) bool {
+	if k.Type.Typed() {
+		_, err := k.Type.ParseConstraint(value)
+		return value == "*" || err == nil
+	}
+	return value == "*" || len(k.Values) == 0 || slices.Contains(k.Values, value)
+}
+
+// Validate checks that the key can be registered.
+func (k SpecifierKey) Validate() error {
+	if k.Name == "" || k.Name == "*" {
+		return fmt.Errorf("%w: the name must be set and cannot be *", ErrInvalidSpecifierKey)
+	}
+	if _, err := SpecifierTypeFromString(string(k.Type)); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpecifierKey, err)
+	}
+	if slices.Contains(k.Values, "*") || slices.Contains(k.Values, "") {
+		return fmt.Errorf("%w: values cannot be empty or *", ErrInvalidSpecifierKey)
+	}
+	if k.Type.Typed() {
+		if len(k.Values) > 0 {
+			return fmt.Errorf("%w: %s keys take constraints, not values", ErrInvalidSpecifierKey, k.Type)
+		}
+		if err := k.Type.ParseValue(k.Default); k.Default != "" && err != nil {
+			return fmt.Errorf("%w: default %q is not a %s value", ErrInvalidSpecifierKey, k.Default, k.Type)
+		}
+		return nil
+	}
+	if k.Default == "*" || (k.Default != "" && !k.Allows(k.Default)) {
+		return fmt.Errorf("%w: default %q is not one of the values", ErrInvalidSpecifierKey, k.Default)
+	}
+	return nil
+}
+
+// Create registers the key, failing with ErrSpecifierKeyExists if it already is. The `*=*` root,
+// the `<key>=*` root and a specifier for every value and the default of an untyped key are
+// created if missing.
+func (k SpecifierKey) Create(ctx context.Context) (SpecifierKey, error) {
+	if err := k.Validate(); err != nil {
+		return SpecifierKey{}, err
+	}
+
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		err := db.FromContext(ctx).CreateSpecifierKey(ctx, k.Record())
+		if errors.Is(err, db.ErrConflict) {
+			return ErrSpecifierKeyExists
+		}
+		if err != nil {
+			return err
+		}
+
+		root, err := NewSpecifier("*", "*").GetOrCreate(ctx)
+		if err != nil {
+			return err
+		}
+		keyRoot, err := NewSpecifier(k.Name, "*").GetOrCreateAsChildOf(ctx, root)
+		if err != nil {
+			return err
+		}
+		for _, value := range k.Values {
+			if _, err := NewSpecifier(k.Name, value).GetOrCreateAsChildOf(ctx, keyRoot); err != nil {
+				return err
+			}
+		}
+		if k.Default != "" && !k.Type.Typed() {
+			_, err = NewSpecifier(k.Name, k.Default).GetOrCreateAsChildOf(ctx, keyRoot)
+		}
+		return err
+	})
+	if err != nil {
+		return SpecifierKey{}, err
+	}
+
+	return k, nil
+}
+
+// GetKey returns the registered key with the name.
+func GetKey(ctx context.Context, name string) (SpecifierKey, error) {
+	keys, err := ListKeys(ctx)
+	if err != nil {
+		return SpecifierKey{}, err
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return SpecifierKey{}, ErrSpecifierKeyNotFound
+}
+
+// ListKeys returns the registered keys, sorted by name.
+func ListKeys(ctx context.Context) ([]SpecifierKey, error) {
+	records, err := db.FromContext(ctx).GetSpecifierKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]SpecifierKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, specifierKeyFromRecord(record))
+	}
+	return keys, nil
+}
+
+// Delete unregisters the key. Its specifiers, and the policies pointing at them, are kept.
+func (k SpecifierKey) Delete(ctx context.Context) error {
+	if _, err := GetKey(ctx, k.Name); err != nil {
+		return err
+	}
+
+	return db.FromContext(ctx).DeleteSpecifierKey(ctx, k.Name)
+}
+
+// Registry holds the specifier keys and values known to the store, to check the specifiers
+// of policies and queries. A key is known if it is registered or has specifiers, so that
+// keys created before the registry existed keep working.
+type Registry struct {
+	keys        map[string]SpecifierKey
+	implicit    map[string]bool
+	values      map[db.SpecifierRecord]bool
+	constraints map[string]map[string]Constraint
+}
+
+// LoadRegistry reads the registered keys and the specifiers from the store.
+func LoadRegistry(ctx context.Context) (Registry, error) {
+	store := db.FromContext(ctx)
+	registry := Registry{
+		keys:        map[string]SpecifierKey{},
+		implicit:    map[string]bool{},
+		values:      map[db.SpecifierRecord]bool{},
+		constraints: map[string]map[string]Constraint{},
+	}
+
+	records, err := store.GetSpecifierKeys(ctx)
+	if err != nil {
+		return Registry{}, err
+	}
+	for _, record := range records {
+		registry.keys[record.Name] = specifierKeyFromRecord(record)
+	}
+
+	nodes, err := store.GetSpecifiers(ctx)
+	if err != nil {
+		return Registry{}, err
+	}
+	for _, node := range nodes {
+		registry.implicit[node.Specifier.Key] = true
+		registry.values[node.Specifier] = true
+
+		key := registry.keys[node.Specifier.Key]
+		if !key.Type.Typed() || node.Specifier.Value == "*" {
+			continue
+		}
+		if constraint, err := key.Type.ParseConstraint(node.Specifier.Value); err == nil {
+			if registry.constraints[key.Name] == nil {
+				registry.constraints[key.Name] = map[string]Constraint{}
+			}
+			registry.constraints[key.Name][node.Specifier.Value] = constraint
+		}
+	}
+	return registry, nil
+}
+
+// Check fails with ErrUnknownSpecifierKey or ErrUnknownSpecifierValue if a specifier has a key
+// or value that is not known, or that its registered key does not accept, and with
+// ErrInvalidConstraint if the value of a typed key is not a constraint.
+func (r Registry) Check(specifiers map[string]string) error {
+	for _, k := range slices.Sorted(maps.Keys(specifiers)) {
+		if err := r.checkKey(k); err != nil {
+			return err
+		}
+		if err := r.checkValue(k, specifiers[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Registry) checkKey(k string) error {
+	if _, registered := r.keys[k]; k == "*" || (!registered && !r.implicit[k]) {
+		return fmt.Errorf("%w: %q", ErrUnknownSpecifierKey, k)
+	}
+	return nil
+}
+
+// checkValue fails if the value is not accepted by the key, or has no specifier. For typed keys it
+// only fails with ErrInvalidConstraint if the value is not a constraint, as policies create their
+// constraints as needed.
+func (r Registry) checkValue(k string, v string) error {
+	key := r.keys[k]
+	if key.Type.Typed() && v != "*" {
+		_, err := key.Type.ParseConstraint(v)
+		return err
+	}
+	if !key.Allows(v) || !r.values[db.SpecifierRecord{Key: k, Value: v}] {
+		return fmt.Errorf("%w: %s=%s", ErrUnknownSpecifierValue, k, v)
+	}
+	return nil
+}
+
+// MissingConstraints returns the constraints of typed keys among the specifiers that have no
+// specifier yet, sorted by key.
+func (r Registry) MissingConstraints(specifiers map[string]string) []Specifier {
+	missing := []Specifier{}
+	for _, k := range slices.Sorted(maps.Keys(specifiers)) {
+		v := specifiers[k]
+		if r.keys[k].Type.Typed() && v != "*" && !r.values[db.SpecifierRecord{Key: k, Value: v}] {
+			missing = append(missing, NewSpecifier(k, v))
+		}
+	}
+	return missing
+}
+
+// Constrain checks the specifiers of a query, in which typed keys take values of their type rather
+// than constraints. It returns the specifiers with the values of typed keys replaced by the `*`
+// wildcard, and for each of these keys the constraints the value satisfies.
+func (r Registry) Constrain(specifiers map[string]string) (map[string]string, map[string][]string, error) {
+	resolved := map[string]string{}
+	constraints := map[string][]string{}
+	for _, k := range slices.Sorted(maps.Keys(specifiers)) {
+		v := specifiers[k]
+		if err := r.checkKey(k); err != nil {
+			return nil, nil, err
+		}
+
+		key := r.keys[k]
+		if !key.Type.Typed() || v == "*" {
+			if err := r.checkValue(k, v); err != nil {
+				return nil, nil, err
+			}
+			resolved[k] = v
+			continue
+		}
+
+		if err := key.Type.ParseValue(v); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s=%s is not a %s value", ErrUnknownSpecifierValue, k, v, key.Type)
+		}
+		resolved[k] = "*"
+		constraints[k] = []string{}
+		for _, value := range slices.Sorted(maps.Keys(r.constraints[k])) {
+			if r.constraints[k][value].Contains(v) {
+				constraints[k] = append(constraints[k], value)
+			}
+		}
+	}
+	return resolved, constraints, nil
+}
+
+// ForQuery checks the specifiers of a query and resolves them like Constrain, with the defaults
+// of the missing keys filled in. It fails with ErrMissingSpecifier if a required key without a
+// default is missing.
+func (r Registry) ForQuery(specifiers map[string]string) (map[string]string, map[string][]string, error) {
+	if _, _, err := r.Constrain(specifiers); err != nil {
+		return nil, nil, err
+	}
+
+	withDefaults := maps.Clone(specifiers)
+	if withDefaults == nil {
+		withDefaults = map[string]string{}
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.keys)) {
+		key := r.keys[name]
+		if _, exists := withDefaults[name]; exists {
+			continue
+		}
+		if key.Default != "" {
+			withDefaults[name] = key.Default
+		} else if key.Required {
+			return nil, nil, fmt.Errorf("%w: %q", ErrMissingSpecifier, name)
+		}
+	}
+	return r.Constrain(withDefaults)
+}
+
+// checkValue fails with ErrUnknownSpecifierValue if the key of the specifier is registered
+// and does not accept its value, and with ErrInvalidConstraint if it is typed and the value
+// is not a constraint.
+func (s Specifier) checkValue(ctx context.Context) error {
+	key, err := GetKey(ctx, s.Key)
+	if errors.Is(err, ErrSpecifierKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if key.Type.Typed() && s.Value != "*" {
+		_, err := key.Type.ParseConstraint(s.Value)
+		return err
+	}
+	if !key.Allows(s.Value) {
+		return fmt.Errorf("%w: %s=%s", ErrUnknownSpecifierValue, s.Key, s.Value)
+	}
+	return nil
+}
+
+// checkParent fails if the key of the specifier is typed and the parent is not its root: `<key>=*`
+// for constraints, which are matched by value rather than through the hierarchy, and `*=*` for `<key>=*`.
+func (s Specifier) checkParent(ctx context.Context, parent Specifier) error {
+	key, err := GetKey(ctx, s.Key)
+	if errors.Is(err, ErrSpecifierKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	root := NewSpecifier(s.Key, "*")
+	if s.Value == "*" {
+		root = NewSpecifier("*", "*")
+	}
+	if key.Type.Typed() && parent != root {
+		return db.Errorf(db.ErrInvalidInput, "%s=%s of a %s key can only be a child of %s=%s", s.Key, s.Value, key.Type, root.Key, root.Value)
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package specifier
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namsnath/otter/db"
+)
+
+// SpecifierType selects how the specifiers of a key match the values of queries. Specifiers of
+// the string type match through the specifier hierarchy. Specifiers of the other types are
+// constraints, such as a range or a CIDR block, that the concrete value of a query is tested against.
+type SpecifierType string
+
+const (
+	SpecifierTypeString SpecifierType = ""
+	// SpecifierTypeInt constraints are ranges of integers: `10..100`, `..100`, `10..` or `42`.
+	SpecifierTypeInt SpecifierType = "int"
+	// SpecifierTypeTime constraints are time of day windows, end excluded: `09:00-18:00`. A window
+	// ending before it starts wraps around midnight. Values are times of day: `13:30`.
+	SpecifierTypeTime SpecifierType = "time"
+	// SpecifierTypeDate constraints are ranges of dates, both ends included: `2026-01-01..2026-12-31`,
+	// `..2026-12-31`, `2026-01-01..` or `2026-01-01`.
+	SpecifierTypeDate SpecifierType = "date"
+	// SpecifierTypeCIDR constraints are IP prefixes: `10.0.0.0/8`. Values are IP addresses.
+	SpecifierTypeCIDR SpecifierType = "cidr"
+	// SpecifierTypeSemver constraints are space separated comparisons with versions: `>=1.2.0 <2.0.0`.
+	// A version without an operator must be equal. Versions are ordered by semver precedence, so
+	// pre-releases come before their release.
+	SpecifierTypeSemver SpecifierType = "semver"
+)
+
+var ErrInvalidSpecifierType = db.NewError(db.ErrInvalidInput, "invalid specifier type: must be string, int, time, date, cidr or semver")
+var ErrInvalidConstraint = db.NewError(db.ErrInvalidInput, "invalid specifier constraint")
+
+// SpecifierTypeFromString parses the type, defaulting to SpecifierTypeString when empty.
+func SpecifierTypeFromString(s string) (SpecifierType, error) {
+	switch SpecifierType(s) {
+	case SpecifierTypeString, "string":
+		return SpecifierTypeString, nil
+	case SpecifierTypeInt, SpecifierTypeTime, SpecifierTypeDate, SpecifierTypeCIDR, SpecifierTypeSemver:
+		return SpecifierType(s), nil
+	default:
+		return "", ErrInvalidSpecifierType
+	}
+}
+
+// String returns the name of the type, `string` for SpecifierTypeString.
+func (t SpecifierType) String() string {
+	if t == SpecifierTypeString {
+		return "string"
+	}
+	return string(t)
+}
+
+// Typed reports whether the specifiers of the type are constraints.
+func (t SpecifierType) Typed() bool {
+	return t != SpecifierTypeString
+}
+
+// Constraint is a set of values of a typed specifier key.
+type Constraint interface {
+	// Contains reports whether the value, parsed by the type of the constraint, is in the set.
+	Contains(value string) bool
+}
+
+// ParseConstraint parses a constraint of the type, failing with ErrInvalidConstraint.
+func (t SpecifierType) ParseConstraint(s string) (Constraint, error) {
+	var constraint Constraint
+	var err error
+	switch t {
+	case SpecifierTypeInt:
+		constraint, err = parseIntRange(s)
+	case SpecifierTypeTime:
+		constraint, err = parseTimeWindow(s)
+	case SpecifierTypeDate:
+		constraint, err = parseDateRange(s)
+	case SpecifierTypeCIDR:
+		constraint, err = parseCIDR(s)
+	case SpecifierTypeSemver:
+		constraint, err = parseSemverRange(s)
+	default:
+		return nil, fmt.Errorf("%w: %s specifiers are not constraints", ErrInvalidConstraint, t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a %s constraint: %v", ErrInvalidConstraint, s, t, err)
+	}
+	return constraint, nil
+}
+
+// ConstraintType returns the type the value is a constraint of, for values such as `10..100` that
+// are not also a value of the type, unlike `42`. Only the keys of that type give them their meaning.
+func ConstraintType(s string) (SpecifierType, bool) {
+	for _, t := range []SpecifierType{SpecifierTypeInt, SpecifierTypeTime, SpecifierTypeDate, SpecifierTypeCIDR, SpecifierTypeSemver} {
+		if _, err := t.ParseConstraint(s); err == nil && t.ParseValue(s) != nil {
+			return t, true
+		}
+	}
+	return SpecifierTypeString, false
+}
+
+// ParseValue checks that the value of a query is a value of the type.
+func (t SpecifierType) ParseValue(s string) error {
+	var err error
+	switch t {
+	case SpecifierTypeInt:
+		_, err = strconv.ParseInt(s, 10, 64)
+	case SpecifierTypeTime:
+		_, err = parseTimeOfDay(s)
+	case SpecifierTypeDate:
+		_, err = time.Parse(time.DateOnly, s)
+	case SpecifierTypeCIDR:
+		_, err = netip.ParseAddr(s)
+	case SpecifierTypeSemver:
+		_, err = parseSemver(s)
+	}
+	return err
+}
+
+type intRange struct {
+	min, max int64
+}
+
+func parseIntRange(s string) (Constraint, error) {
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange {
+		n, err := strconv.ParseInt(s, 10, 64)
+		return intRange{n, n}, err
+	}
+	if from == "" && to == "" {
+		return nil, fmt.Errorf("both ends are open, use * instead")
+	}
+
+	r := intRange{math.MinInt64, math.MaxInt64}
+	var err error
+	if from != "" {
+		if r.min, err = strconv.ParseInt(from, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if r.max, err = strconv.ParseInt(to, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if r.min > r.max {
+		return nil, fmt.Errorf("the range is empty")
+	}
+	return r, nil
+}
+
+func (r intRange) Contains(value string) bool {
+	n, err := strconv.ParseInt(value, 10, 64)
+	return err == nil && r.min <= n && n <= r.max
+}
+
+type timeWindow struct {
+	from, to time.Duration
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseTimeWindow(s string) (Constraint, error) {
+	from, to, isWindow := strings.Cut(s, "-")
+	if !isWindow {
+		return nil, fmt.Errorf("expected a window such as 09:00-18:00")
+	}
+
+	var w timeWindow
+	var err error
+	if w.from, err = parseTimeOfDay(from); err != nil {
+		return nil, err
+	}
+	if w.to, err = parseTimeOfDay(to); err != nil {
+		return nil, err
+	}
+	if w.from == w.to {
+		return nil, fmt.Errorf("the window is empty, use * for the whole day")
+	}
+	return w, nil
+}
+
+func (w timeWindow) Contains(value string) bool {
+	t, err := parseTimeOfDay(value)
+	if err != nil {
+		return false
+	}
+	// The window wraps around midnight when it ends before it starts
+	if w.from < w.to {
+		return w.from <= t && t < w.to
+	}
+	return w.from <= t || t < w.to
+}
+
+type dateRange struct {
+	from, to time.Time
+}
+
+func parseDateRange(s string) (Constraint, error) {
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange {
+		to = from
+	}
+	if from == "" && to == "" {
+		return nil, fmt.Errorf("both ends are open, use * instead")
+	}
+
+	var r dateRange
+	var err error
+	if from != "" {
+		if r.from, err = time.Parse(time.DateOnly, from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if r.to, err = time.Parse(time.DateOnly, to); err != nil {
+			return nil, err
+		}
+		if r.to.Before(r.from) {
+			return nil, fmt.Errorf("the range is empty")
+		}
+	}
+	return r, nil
+}
+
+func (r dateRange) Contains(value string) bool {
+	d, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return false
+	}
+	return (r.from.IsZero() || !d.Before(r.from)) && (r.to.IsZero() || !d.After(r.to))
+}
+
+type cidr struct {
+	prefix netip.Prefix
+}
+
+func parseCIDR(s string) (Constraint, error) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return nil, err
+	}
+	// Requiring the canonical form keeps a single specifier per block
+	if prefix != prefix.Masked() {
+		return nil, fmt.Errorf("host bits are set, expected %s", prefix.Masked())
+	}
+	return cidr{prefix}, nil
+}
+
+func (c cidr) Contains(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && c.prefix.Contains(addr.Unmap())
+}
+
+// semver is a version of the form MAJOR.MINOR.PATCH, with an optional pre-release. Build metadata
+// is ignored, as it does not take part in the precedence.
+type semver struct {
+	core       [3]uint64
+	prerelease []string
+}
+
+func parseSemver(s string) (semver, error) {
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+
+	var v semver
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return semver{}, fmt.Errorf("expected a version such as 1.2.3, got %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || (len(part) > 1 && part[0] == '0') {
+			return semver{}, fmt.Errorf("invalid version number %q", part)
+		}
+		v.core[i] = n
+	}
+	if hasPrerelease {
+		v.prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range v.prerelease {
+			if identifier == "" {
+				return semver{}, fmt.Errorf("empty pre-release identifier")
+			}
+		}
+	}
+	return v, nil
+}
+
+// compare orders the versions by semver precedence.
+func (v semver) compare(other semver) int {
+	for i := range v.core {
+		if v.core[i] != other.core[i] {
+			if v.core[i] < other.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release comes before the release
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		if a == b {
+			continue
+		}
+		aNum, aErr := strconv.ParseUint(a, 10, 64)
+		bNum, bErr := strconv.ParseUint(b, 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		// Numeric identifiers come before alphanumeric ones
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	}
+	return len(v.prerelease) - len(other.prerelease)
+}
+
+type semverComparison struct {
+	operator string
+	version  semver
+}
+
+type semverRange []semverComparison
+
+func parseSemverRange(s string) (Constraint, error) {
+	r := semverRange{}
+	for _, field := range strings.Fields(s) {
+		operator := ""
+		for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(field, candidate) {
+				operator = candidate
+				break
+			}
+		}
+
+		version, err := parseSemver(strings.TrimPrefix(field, operator))
+		if err != nil {
+			return nil, err
+		}
+		if operator == "" {
+			operator = "="
+		}
+		r = append(r, semverComparison{operator, version})
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("no comparison")
+	}
+	return r, nil
+}
+
+func (r semverRange) Contains(value string) bool {
+	version, err := parseSemver(value)
+	if err != nil {
+		return false
+	}
+
+	for _, comparison := range r {
+		c := version.compare(comparison.version)
+		var ok bool
+		switch comparison.operator {
+		case ">=":
+			ok = c >= 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case "<":
+			ok = c < 0
+		default:
+			ok = c == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package specifier_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/namsnath/otter/specifier"
+)
+
+func TestConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		specifierType specifier.SpecifierType
+		constraint    string
+		inside        []string
+		outside       []string
+	}{
+		{specifier.SpecifierTypeInt, "10..100", []string{"10", "55", "100"}, []string{"9", "101", "ten"}},
+		{specifier.SpecifierTypeInt, "..0", []string{"-5", "0"}, []string{"1"}},
+		{specifier.SpecifierTypeInt, "42", []string{"42"}, []string{"41", "43"}},
+		{specifier.SpecifierTypeTime, "09:00-18:00", []string{"09:00", "17:59"}, []string{"08:59", "18:00", "23:00"}},
+		{specifier.SpecifierTypeTime, "22:00-06:00", []string{"23:30", "00:00", "05:59"}, []string{"06:00", "12:00"}},
+		{specifier.SpecifierTypeDate, "2026-01-01..2026-12-31", []string{"2026-01-01", "2026-12-31"}, []string{"2025-12-31", "2027-01-01"}},
+		{specifier.SpecifierTypeDate, "2026-07-01..", []string{"2026-07-01", "2099-01-01"}, []string{"2026-06-30"}},
+		{specifier.SpecifierTypeCIDR, "10.0.0.0/8", []string{"10.1.2.3", "::ffff:10.0.0.1"}, []string{"11.0.0.1", "fd00::1", "10.0.0.0/8"}},
+		{specifier.SpecifierTypeCIDR, "fd00::/8", []string{"fd12::1"}, []string{"fe80::1"}},
+		{specifier.SpecifierTypeSemver, ">=1.2.0 <2.0.0", []string{"1.2.0", "1.10.0+build", "2.0.0-rc.1"}, []string{"1.1.9", "1.2.0-rc.1", "2.0.0", "1.2"}},
+		{specifier.SpecifierTypeSemver, "1.0.0-rc.2", []string{"1.0.0-rc.2"}, []string{"1.0.0"}},
+		{specifier.SpecifierTypeSemver, ">1.0.0-alpha.1 <1.0.0", []string{"1.0.0-alpha.2", "1.0.0-beta", "1.0.0-alpha.1.1"}, []string{"1.0.0-alpha", "1.0.0-alpha.1"}},
+	} {
+		t.Run(string(tc.specifierType)+" "+tc.constraint, func(t *testing.T) {
+			constraint, err := tc.specifierType.ParseConstraint(tc.constraint)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, value := range tc.inside {
+				if !constraint.Contains(value) {
+					t.Errorf("Expected %s to contain %s", tc.constraint, value)
+				}
+			}
+			for _, value := range tc.outside {
+				if constraint.Contains(value) {
+					t.Errorf("Expected %s not to contain %s", tc.constraint, value)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		specifierType specifier.SpecifierType
+		constraint    string
+	}{
+		{specifier.SpecifierTypeInt, "100..10"},
+		{specifier.SpecifierTypeInt, ".."},
+		{specifier.SpecifierTypeTime, "09:00"},
+		{specifier.SpecifierTypeTime, "09:00-09:00"},
+		{specifier.SpecifierTypeTime, "9am-5pm"},
+		{specifier.SpecifierTypeDate, "2026-12-31..2026-01-01"},
+		{specifier.SpecifierTypeCIDR, "10.0.0.1/8"},
+		{specifier.SpecifierTypeCIDR, "10.0.0.1"},
+		{specifier.SpecifierTypeSemver, ">=1.2"},
+		{specifier.SpecifierTypeSemver, "~1.2.0"},
+		{specifier.SpecifierTypeString, "prod"},
+	} {
+		t.Run(string(tc.specifierType)+" "+tc.constraint, func(t *testing.T) {
+			if _, err := tc.specifierType.ParseConstraint(tc.constraint); !errors.Is(err, specifier.ErrInvalidConstraint) {
+				t.Errorf("Expected %v, got %v", specifier.ErrInvalidConstraint, err)
+			}
+		})
+	}
+}
+
+func TestConstraintType(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected specifier.SpecifierType
+		found    bool
+	}{
+		{"10..100", specifier.SpecifierTypeInt, true},
+		{"09:00-18:00", specifier.SpecifierTypeTime, true},
+		{"2026-01-01..", specifier.SpecifierTypeDate, true},
+		{"10.0.0.0/8", specifier.SpecifierTypeCIDR, true},
+		{">=1.2.0 <2.0.0", specifier.SpecifierTypeSemver, true},
+		{"42", specifier.SpecifierTypeString, false},
+		{"1.2.0", specifier.SpecifierTypeString, false},
+		{"prod", specifier.SpecifierTypeString, false},
+	} {
+		if actual, found := specifier.ConstraintType(tc.value); actual != tc.expected || found != tc.found {
+			t.Errorf("For %q, expected %s, %v, got %s, %v", tc.value, tc.expected, tc.found, actual, found)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/state"
+	"github.com/namsnath/otter/subject"
+)
+
+const testState = `
+version: 1
+actions:
+  - name: DEPLOY
+  - name: WRITE
+    implies: [READ]
+subjects:
+  - name: admins
+    type: Group
+  - name: alice
+    type: Principal
+    groups: [admins]
+resources:
+  - name: _
+  - name: docs
+    parent: _
+specifiers:
+  Env:
+    - value: prod
+    - value: dev
+  Role:
+    - value: admin
+    - value: user
+      parent: admin
+policies:
+  - id: admins-write-docs
+    subject: admins
+    resource: docs
+    action: WRITE
+    specifiers:
+      Env: prod
+  - subject: alice
+    resource: _
+    action: DEPLOY
+`
+
+func TestApply(t *testing.T) {
+	db.TestContainer(t)
+
+	testApply(t)
+}
+
+func TestApplyInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testApply(t)
+}
+
+func apply(t *testing.T, s state.State, options state.Options) state.Plan {
+	t.Helper()
+	ctx := t.Context()
+
+	plan, err := s.Plan(ctx, options)
+	if err != nil {
+		t.Fatalf("Unexpected error planning: %v", err)
+	}
+	if err := plan.Apply(ctx); err != nil {
+		t.Fatalf("Unexpected error applying: %v", err)
+	}
+	return plan
+}
+
+func can(ctx context.Context, s subject.Subject, a action.Action, r string, specifiers ...specifier.Specifier) bool {
+	return query.Can(s).Perform(a).On(resource.NewResource(r)).With(specifier.SpecifierGroup{Specifiers: specifiers}).Query(ctx).Can
+}
+
+func testApply(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupIndexes(ctx)
+
+	s, err := state.Parse([]byte(testState))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing: %v", err)
+	}
+
+	alice := subject.Subject{Name: "alice", Type: subject.SubjectTypePrincipal}
+	deployId := s.Policies[1].StableId()
+
+	t.Run("Create", func(t *testing.T) {
+		plan := apply(t, s, state.Options{})
+		if plan.Count(state.OperationCreate) == 0 || plan.Count(state.OperationUpdate) != 0 || plan.Count(state.OperationDelete) != 0 {
+			t.Errorf("Expected only creations, got:\n%s", plan)
+		}
+
+		if !can(ctx, alice, action.ActionRead, "docs", specifier.NewSpecifier("Env", "prod")) {
+			t.Errorf("Expected alice to READ docs in prod through the group and the WRITE implication")
+		}
+		if can(ctx, alice, action.ActionRead, "docs", specifier.NewSpecifier("Env", "dev")) {
+			t.Errorf("Expected alice not to READ docs in dev")
+		}
+		if !can(ctx, alice, action.Action("DEPLOY"), "docs") {
+			t.Errorf("Expected alice to DEPLOY docs through the root resource")
+		}
+
+		if p, err := (policy.Policy{Id: "admins-write-docs"}).GetById(ctx); err != nil || p.Action != action.ActionWrite {
+			t.Errorf("Expected the declared policy ID to be kept, got %v, %v", p, err)
+		}
+		if p, err := (policy.Policy{Id: deployId}).GetById(ctx); err != nil || p.Action != action.Action("DEPLOY") {
+			t.Errorf("Expected the derived policy ID to be used, got %v, %v", p, err)
+		}
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		plan, err := s.Plan(ctx, state.Options{Prune: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !plan.Empty() {
+			t.Errorf("Expected no changes, got:\n%s", plan)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated := s
+		updated.Policies = append([]state.Policy{}, s.Policies...)
+		updated.Policies[0].Specifiers = map[string]string{"Env": "dev"}
+
+		plan := apply(t, updated, state.Options{})
+		if plan.Count(state.OperationUpdate) != 1 || len(plan.Changes) != 1 {
+			t.Errorf("Expected a single policy update, got:\n%s", plan)
+		}
+
+		if !can(ctx, alice, action.ActionWrite, "docs", specifier.NewSpecifier("Env", "dev")) {
+			t.Errorf("Expected alice to WRITE docs in dev after the update")
+		}
+		if can(ctx, alice, action.ActionWrite, "docs", specifier.NewSpecifier("Env", "prod")) {
+			t.Errorf("Expected alice not to WRITE docs in prod after the update")
+		}
+		if p, err := (policy.Policy{Id: "admins-write-docs"}).GetById(ctx); err != nil || p.Id == "" {
+			t.Errorf("Expected the updated policy to keep its ID, got %v, %v", p, err)
+		}
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		bob := subject.Subject{Name: "bob", Type: subject.SubjectTypePrincipal}
+		bob.CreateAsChildOf(ctx, subject.Subject{Name: "admins", Type: subject.SubjectTypeGroup})
+
+		kept, err := s.Plan(ctx, state.Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if kept.Count(state.OperationDelete) != 0 {
+			t.Errorf("Expected nothing deleted without pruning, got:\n%s", kept)
+		}
+
+		pruned := s
+		pruned.Policies = s.Policies[:1]
+		apply(t, pruned, state.Options{Prune: true})
+
+		if can(ctx, alice, action.Action("DEPLOY"), "docs") {
+			t.Errorf("Expected the undeclared DEPLOY policy to be pruned")
+		}
+		if can(ctx, bob, action.ActionRead, "docs", specifier.NewSpecifier("Env", "prod")) {
+			t.Errorf("Expected the undeclared subject bob to be pruned")
+		}
+		if p, err := (policy.Policy{Id: deployId}).GetById(ctx); err != nil || p.Id != "" {
+			t.Errorf("Expected the pruned policy to be gone")
+		}
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		withKeys := s
+		withKeys.Keys = []state.Key{{Name: "Tier", Values: []string{"gold", "silver"}}}
+		withKeys.Policies = append([]state.Policy{}, s.Policies[:1]...)
+		withKeys.Policies = append(withKeys.Policies, state.Policy{Subject: "alice", Resource: "docs", Action: "READ", Specifiers: map[string]string{"Tier": "gold"}})
+		apply(t, withKeys, state.Options{})
+
+		if key, err := specifier.GetKey(ctx, "Tier"); err != nil || !reflect.DeepEqual(key.Values, []string{"gold", "silver"}) {
+			t.Errorf("Expected the declared key to be registered, got %+v, %v", key, err)
+		}
+		if !can(ctx, alice, action.ActionRead, "docs", specifier.NewSpecifier("Tier", "gold")) {
+			t.Errorf("Expected the values of the declared key to be usable by policies")
+		}
+
+		withKeys.Keys = []state.Key{{Name: "Tier", Description: "Support tier", Values: []string{"gold", "silver"}}}
+		plan := apply(t, withKeys, state.Options{})
+		if plan.Count(state.OperationUpdate) != 1 || len(plan.Changes) != 1 {
+			t.Errorf("Expected a single key update, got:\n%s", plan)
+		}
+		if key, err := specifier.GetKey(ctx, "Tier"); err != nil || key.Description != "Support tier" {
+			t.Errorf("Expected the key to be updated, got %+v, %v", key, err)
+		}
+
+		withKeys.Keys = nil
+		withKeys.Policies = s.Policies[:1]
+		apply(t, withKeys, state.Options{Prune: true})
+		if _, err := specifier.GetKey(ctx, "Tier"); !errors.Is(err, specifier.ErrSpecifierKeyNotFound) {
+			t.Errorf("Expected %v, got %v", specifier.ErrSpecifierKeyNotFound, err)
+		}
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		extended := s
+		extended.Subjects = append([]state.Subject{}, s.Subjects...)
+		extended.Subjects = append(extended.Subjects, state.Subject{Name: "dave", Type: "Principal"})
+		extended.Policies = append([]state.Policy{}, s.Policies...)
+		extended.Policies = append(extended.Policies, state.Policy{Subject: "dave", Resource: "docs", Action: "READ"})
+
+		plan, err := extended.Plan(ctx, state.Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error planning: %v", err)
+		}
+
+		// The policy of the plan can no longer be created once its resource is gone
+		if err := resource.NewResource("docs").Delete(ctx, db.DeleteCascade); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := plan.Apply(ctx); !errors.Is(err, policy.ErrPolicyNotCreated) {
+			t.Errorf("Expected %v, got %v", policy.ErrPolicyNotCreated, err)
+		}
+		if _, err := subject.Get(ctx, "dave"); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected the failed apply to be rolled back, got %v", err)
+		}
+	})
+
+	t.Run("Checked", func(t *testing.T) {
+		extended := s
+		extended.Policies = append([]state.Policy{}, s.Policies...)
+		extended.Policies = append(extended.Policies, state.Policy{Subject: "alice", Resource: "docs", Action: "READ", Specifiers: map[string]string{"Env": "dev"}})
+
+		plan, err := extended.Plan(ctx, state.Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error planning: %v", err)
+		}
+
+		// Policies of the plan are checked against the keys registered since
+		if _, err := (specifier.SpecifierKey{Name: "Env", Values: []string{"prod"}}).Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := plan.Apply(ctx); !errors.Is(err, specifier.ErrUnknownSpecifierValue) {
+			t.Errorf("Expected %v, got %v", specifier.ErrUnknownSpecifierValue, err)
+		}
+		if can(ctx, alice, action.ActionRead, "docs", specifier.NewSpecifier("Env", "dev")) {
+			t.Errorf("Expected the policy not to be created")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := (specifier.SpecifierKey{Name: "Role", Values: []string{"admin", "user"}}).Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := (specifier.SpecifierKey{Name: "Port", Type: specifier.SpecifierTypeInt}).Create(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		testCases := []struct {
+			name  string
+			state string
+			err   error
+		}{
+			{"Version", "version: 2", state.ErrUnsupportedVersion},
+			{"Unknown field", "version: 1\nusers: []", state.ErrInvalidState},
+			{"Unknown group", "version: 1\nsubjects:\n  - {name: carol, type: Principal, groups: [nobody]}", state.ErrInvalidState},
+			{"Principal group", "version: 1\nsubjects:\n  - {name: carol, type: Principal, groups: [alice]}", state.ErrInvalidState},
+			{"Type change", "version: 1\nsubjects:\n  - {name: alice, type: Group}", state.ErrInvalidState},
+			{"Unknown parent", "version: 1\nresources:\n  - {name: notes, parent: nowhere}", state.ErrInvalidState},
+			{"Unknown specifier", "version: 1\npolicies:\n  - {subject: alice, resource: docs, action: READ, specifiers: {Env: qa}}", state.ErrInvalidState},
+			{"Value not accepted", "version: 1\nspecifiers:\n  Role:\n    - value: guest", state.ErrInvalidState},
+			{"Invalid constraint", "version: 1\nspecifiers:\n  Port:\n    - value: 100..10", state.ErrInvalidState},
+			{"Undeclared constraint", "version: 1\nspecifiers:\n  Amount:\n    - value: 10..100", state.ErrInvalidState},
+			{"Invalid key type", "version: 1\nkeys:\n  - {name: Amount, type: float}", state.ErrInvalidState},
+			{"Key declared twice", "version: 1\nkeys:\n  - name: Tier\n  - name: Tier", state.ErrInvalidState},
+			{"Invalid key", "version: 1\nkeys:\n  - {name: Tier, values: [gold], default: bronze}", state.ErrInvalidState},
+			{"Value not declared", "version: 1\nkeys:\n  - {name: Tier, values: [gold]}\nspecifiers:\n  Tier:\n    - value: silver", state.ErrInvalidState},
+			{"Constraint parent", "version: 1\nspecifiers:\n  Port:\n    - value: 1..100\n    - {value: 80, parent: 1..100}", state.ErrInvalidState},
+			{"Group cycle", "version: 1\nsubjects:\n  - {name: g1, type: Group, groups: [g2]}\n  - {name: g2, type: Group, groups: [g1]}", state.ErrInvalidState},
+			{"Resource cycle", "version: 1\nresources:\n  - {name: a, parent: b}\n  - {name: b, parent: a}", state.ErrInvalidState},
+			{"Implication cycle", "version: 1\nactions:\n  - {name: READ, implies: [WRITE]}", state.ErrInvalidState},
+			{"JSON", `{"version": 1, "subjects": [{"name": "carol", "type": "Robot"}]}`, state.ErrInvalidState},
+		}
+
+		for _, tc := range testCases {
+			s, err := state.Parse([]byte(tc.state))
+			if err == nil {
+				_, err = s.Plan(ctx, state.Options{})
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.err, err)
+			}
+		}
+	})
+}
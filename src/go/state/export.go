@@ -0,0 +1,139 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+)
+
+var ErrNotExportable = db.NewError(db.ErrInvalidInput, "graph cannot be exported")
+
+// Export returns the full contents of the store as a State, with the ID of every policy.
+// Applying it to any store, without pruning, reproduces the graph.
+func Export(ctx context.Context) (State, error) {
+	start := time.Now()
+
+	g, err := readGraph(ctx, db.FromContext(ctx))
+	if err != nil {
+		return State{}, err
+	}
+
+	s := State{
+		Version:    Version,
+		Actions:    []Action{},
+		Subjects:   []Subject{},
+		Resources:  []Resource{},
+		Keys:       []Key{},
+		Specifiers: map[string][]Specifier{},
+		Policies:   []Policy{},
+	}
+
+	// Built-in actions are only listed for their implications
+	names := slices.Collect(maps.Keys(g.actions))
+	for name := range g.implications {
+		if action.Action(name).IsBuiltin() {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		s.Actions = append(s.Actions, Action{Name: name, Implies: g.implications[name]})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(g.subjects)) {
+		s.Subjects = append(s.Subjects, Subject{Name: name, Type: g.subjects[name].Type, Groups: g.subjectParents[name]})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(g.resources)) {
+		resource := Resource{Name: name}
+		parents := g.resourceParents[name]
+		if len(parents) > 0 {
+			resource.Parent = parents[0]
+		}
+		if len(parents) > 1 {
+			resource.Parents = parents[1:]
+		}
+		s.Resources = append(s.Resources, resource)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(g.registeredKeys)) {
+		record := g.registeredKeys[name]
+		key := Key{Name: name, Type: record.Type, Description: record.Description, Default: record.Default, Required: record.Required}
+		if len(record.Values) > 0 {
+			key.Values = record.Values
+		}
+		s.Keys = append(s.Keys, key)
+	}
+
+	for _, record := range slices.SortedFunc(maps.Keys(g.specifiers), compareSpecifiers) {
+		if record.Key == "*" {
+			continue
+		}
+		if _, declared := s.Specifiers[record.Key]; !declared {
+			s.Specifiers[record.Key] = []Specifier{}
+		}
+		// The `<key>=*` roots are implicit, as is the parent of values right below them
+		if record.Value == "*" {
+			continue
+		}
+
+		parents := []string{}
+		for _, parent := range g.specifiers[record] {
+			if parent.Key != record.Key {
+				return State{}, fmt.Errorf("%w: specifier %s=%s has a parent of another key, %s=%s", ErrNotExportable, record.Key, record.Value, parent.Key, parent.Value)
+			}
+			if parent.Value != "*" {
+				parents = append(parents, parent.Value)
+			}
+		}
+
+		specifier := Specifier{Value: record.Value}
+		if len(parents) > 0 {
+			specifier.Parent = parents[0]
+		}
+		if len(parents) > 1 {
+			specifier.Parents = parents[1:]
+		}
+		s.Specifiers[record.Key] = append(s.Specifiers[record.Key], specifier)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(g.policies)) {
+		records := g.policies[id]
+		if len(records) != 1 {
+			return State{}, fmt.Errorf("%w: policy %s has more than one action", ErrNotExportable, id)
+		}
+
+		record := records[0]
+		p := Policy{
+			Id:       id,
+			Subject:  record.Subject.Name,
+			Resource: record.Resource.Name,
+			Action:   record.Action,
+			Effect:   record.Effect,
+		}
+		for _, specifier := range record.Specifiers {
+			if specifier.Value == "*" {
+				continue
+			}
+			if p.Specifiers == nil {
+				p.Specifiers = map[string]string{}
+			}
+			p.Specifiers[specifier.Key] = specifier.Value
+		}
+		s.Policies = append(s.Policies, p)
+	}
+
+	slog.Info("State.Export",
+		"subjects", len(s.Subjects),
+		"resources", len(s.Resources),
+		"policies", len(s.Policies),
+		"duration", time.Since(start),
+	)
+	return s, nil
+}
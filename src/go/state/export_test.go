@@ -0,0 +1,139 @@
+package state_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/resource"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/state"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestExport(t *testing.T) {
+	db.TestContainer(t)
+
+	testExport(t)
+}
+
+func TestExportInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testExport(t)
+}
+
+func testExport(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	deploy, _ := action.Action("DEPLOY").Create(ctx)
+	deploy.Implies(ctx, action.ActionRead)
+	resource.Resource{Name: "Resource5"}.CreateAsChildOf(ctx, resource.Resource{Name: "Resource1"})
+	policy.Policy{
+		Subject:    subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal},
+		Resource:   resource.Resource{Name: "Resource5"},
+		Action:     deploy,
+		Effect:     policy.EffectDeny,
+		Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Env", "dev")}},
+	}.Create(ctx)
+
+	env := specifier.SpecifierKey{Name: "Env", Description: "Deployment environment", Values: []string{"dev", "prod"}, Default: "dev", Required: true}
+	amount := specifier.SpecifierKey{Name: "Amount", Type: specifier.SpecifierTypeInt}
+	for _, key := range []specifier.SpecifierKey{env, amount} {
+		if _, err := key.Create(ctx); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", key.Name, err)
+		}
+	}
+	p2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
+	policy.Policy{
+		Subject:    p2,
+		Resource:   resource.Resource{Name: "Resource5"},
+		Action:     deploy,
+		Specifiers: specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Amount", "10..100")}},
+	}.Create(ctx)
+
+	exported, err := state.Export(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error exporting: %v", err)
+	}
+	if len(exported.Policies) != 8 {
+		t.Fatalf("Expected 8 policies, got %d", len(exported.Policies))
+	}
+	expectedKeys := []state.Key{
+		{Name: "Amount", Type: "int"},
+		{Name: "Env", Description: "Deployment environment", Values: []string{"dev", "prod"}, Default: "dev", Required: true},
+	}
+	if !reflect.DeepEqual(exported.Keys, expectedKeys) {
+		t.Fatalf("Expected the keys %+v, got %+v", expectedKeys, exported.Keys)
+	}
+
+	var ndjson bytes.Buffer
+	if err := state.EncodeNDJSON(&ndjson, exported); err != nil {
+		t.Fatalf("Unexpected error encoding NDJSON: %v", err)
+	}
+	fromNDJSON, err := state.DecodeNDJSON(&ndjson)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding NDJSON: %v", err)
+	}
+
+	data, _ := json.Marshal(exported)
+	fromJSON, err := state.Parse(data)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing JSON: %v", err)
+	}
+
+	for name, document := range map[string]state.State{"NDJSON": fromNDJSON, "JSON": fromJSON} {
+		t.Run(name, func(t *testing.T) {
+			query.DeleteEverything(ctx)
+
+			plan := apply(t, document, state.Options{})
+			if plan.Count(state.OperationUpdate) != 0 || plan.Count(state.OperationDelete) != 0 {
+				t.Errorf("Expected only creations into an empty store, got:\n%s", plan)
+			}
+
+			roundTrip, err := state.Export(ctx)
+			if err != nil {
+				t.Fatalf("Unexpected error exporting: %v", err)
+			}
+			if !reflect.DeepEqual(roundTrip, exported) {
+				t.Errorf("Expected the round trip to preserve the graph\nexpected: %+v\ngot:      %+v", exported, roundTrip)
+			}
+
+			// Importing into a store that already holds the graph changes nothing
+			again, err := document.Plan(ctx, state.Options{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !again.Empty() {
+				t.Errorf("Expected the import to be idempotent, got:\n%s", again)
+			}
+
+			can := query.Can(subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}).
+				Perform(action.ActionRead).
+				On(resource.Resource{Name: "Resource5"}).
+				With(specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Env", "dev")}}).
+				Query(ctx)
+			if can.Err != nil || can.Can {
+				t.Errorf("Expected the imported DENY policy to apply through the DEPLOY implication, got %v, %v", can.Can, can.Err)
+			}
+
+			// The constraint keeps matching as a range, not as the string `10..100`
+			for amount, expected := range map[string]bool{"50": true, "500": false} {
+				can := query.Can(p2).
+					Perform(deploy).
+					On(resource.Resource{Name: "Resource5"}).
+					With(specifier.SpecifierGroup{Specifiers: []specifier.Specifier{specifier.NewSpecifier("Amount", amount)}}).
+					Query(ctx)
+				if can.Err != nil || can.Can != expected {
+					t.Errorf("For Amount=%s, expected %v, got %v, %v", amount, expected, can.Can, can.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,544 @@
+package state
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/namsnath/otter/action"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"github.com/namsnath/otter/specifier"
+	"github.com/namsnath/otter/subject"
+)
+
+// graph is a normalized view of the store contents, either as they are or as the state wants them.
+type graph struct {
+	// actions only holds the custom actions, built-in ones always exist.
+	actions         map[string]bool
+	implications    map[string][]string
+	subjects        map[string]db.SubjectRecord
+	subjectParents  map[string][]string
+	resources       map[string]bool
+	resourceParents map[string][]string
+	specifiers      map[db.SpecifierRecord][]db.SpecifierRecord
+	policies        map[string][]db.PolicyRecord
+	registeredKeys  map[string]db.SpecifierKeyRecord
+}
+
+func newGraph() graph {
+	return graph{
+		actions:         map[string]bool{},
+		implications:    map[string][]string{},
+		subjects:        map[string]db.SubjectRecord{},
+		subjectParents:  map[string][]string{},
+		resources:       map[string]bool{},
+		resourceParents: map[string][]string{},
+		specifiers:      map[db.SpecifierRecord][]db.SpecifierRecord{},
+		policies:        map[string][]db.PolicyRecord{},
+		registeredKeys:  map[string]db.SpecifierKeyRecord{},
+	}
+}
+
+func (g graph) clone() graph {
+	return graph{
+		actions:         maps.Clone(g.actions),
+		implications:    maps.Clone(g.implications),
+		subjects:        maps.Clone(g.subjects),
+		subjectParents:  maps.Clone(g.subjectParents),
+		resources:       maps.Clone(g.resources),
+		resourceParents: maps.Clone(g.resourceParents),
+		specifiers:      maps.Clone(g.specifiers),
+		policies:        maps.Clone(g.policies),
+		registeredKeys:  maps.Clone(g.registeredKeys),
+	}
+}
+
+func compareSpecifiers(a, b db.SpecifierRecord) int {
+	return cmp.Or(strings.Compare(a.Key, b.Key), strings.Compare(a.Value, b.Value))
+}
+
+// normalizePolicy sorts the specifiers and fills in the default effect, so records can be compared.
+func normalizePolicy(record db.PolicyRecord) db.PolicyRecord {
+	record.Specifiers = slices.Clone(record.Specifiers)
+	slices.SortFunc(record.Specifiers, compareSpecifiers)
+	if record.Effect == "" {
+		record.Effect = db.EffectAllow
+	}
+	return record
+}
+
+// readGraph reads the current contents of the store.
+func readGraph(ctx context.Context, store db.Store) (graph, error) {
+	g := newGraph()
+
+	actions, err := store.GetActions(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for _, name := range actions {
+		if !action.Action(name).IsBuiltin() {
+			g.actions[name] = true
+		}
+	}
+
+	implications, err := store.GetActionImplications(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for name, implied := range implications {
+		g.implications[name] = slices.Sorted(slices.Values(implied))
+	}
+
+	subjects, err := store.GetSubjects(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for _, node := range subjects {
+		g.subjects[node.Subject.Name] = node.Subject
+		for _, parent := range node.Parents {
+			g.subjectParents[node.Subject.Name] = append(g.subjectParents[node.Subject.Name], parent.Name)
+		}
+	}
+
+	resources, err := store.GetResources(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for _, node := range resources {
+		g.resources[node.Resource.Name] = true
+		for _, parent := range node.Parents {
+			g.resourceParents[node.Resource.Name] = append(g.resourceParents[node.Resource.Name], parent.Name)
+		}
+	}
+
+	specifiers, err := store.GetSpecifiers(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for _, node := range specifiers {
+		g.specifiers[node.Specifier] = nil
+		if len(node.Parents) > 0 {
+			g.specifiers[node.Specifier] = node.Parents
+		}
+	}
+
+	specifierKeys, err := store.GetSpecifierKeys(ctx)
+	if err != nil {
+		return graph{}, err
+	}
+	for _, key := range specifierKeys {
+		g.registeredKeys[key.Name] = key
+	}
+
+	policies, err := store.GetPolicies(ctx, db.PolicyFilter{})
+	if err != nil {
+		return graph{}, err
+	}
+	for _, record := range policies {
+		g.policies[record.Id] = append(g.policies[record.Id], normalizePolicy(record))
+	}
+
+	return g, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidState, fmt.Sprintf(format, args...))
+}
+
+// desiredGraph validates the state and returns the graph it describes. Unless pruning, everything
+// in the current graph that the state does not mention is kept as it is.
+func (s State) desiredGraph(current graph, prune bool) (graph, error) {
+	g := newGraph()
+	if !prune {
+		g = current.clone()
+	}
+
+	if err := s.desiredActions(g); err != nil {
+		return graph{}, err
+	}
+	if err := s.desiredKeys(g); err != nil {
+		return graph{}, err
+	}
+	if err := s.desiredSubjects(g, current); err != nil {
+		return graph{}, err
+	}
+	if err := s.desiredResources(g); err != nil {
+		return graph{}, err
+	}
+	if err := s.desiredSpecifiers(g); err != nil {
+		return graph{}, err
+	}
+	if err := s.desiredPolicies(g); err != nil {
+		return graph{}, err
+	}
+	return g, nil
+}
+
+func (g graph) actionExists(name string) bool {
+	return action.Action(name).IsBuiltin() || g.actions[name]
+}
+
+func (s State) desiredActions(g graph) error {
+	declared := map[string]bool{}
+	for _, a := range s.Actions {
+		if err := action.ValidateName(a.Name); err != nil {
+			return invalid("action %q: %v", a.Name, err)
+		}
+		if declared[a.Name] {
+			return invalid("action %q is declared twice", a.Name)
+		}
+		declared[a.Name] = true
+
+		if !action.Action(a.Name).IsBuiltin() {
+			g.actions[a.Name] = true
+		}
+	}
+
+	// The implications of declared actions are replaced by the declared ones
+	for _, a := range s.Actions {
+		implied := []string{}
+		for _, name := range a.Implies {
+			if !g.actionExists(name) {
+				return invalid("action %q implies unknown action %q", a.Name, name)
+			}
+			if !slices.Contains(implied, name) {
+				implied = append(implied, name)
+			}
+		}
+
+		delete(g.implications, a.Name)
+		if len(implied) > 0 {
+			g.implications[a.Name] = slices.Sorted(slices.Values(implied))
+		}
+	}
+
+	if name, found := findCycle(g.implications); found {
+		return invalid("action implications form a cycle through %q", name)
+	}
+	return nil
+}
+
+// findCycle reports whether the edges form a cycle, and a node on it.
+func findCycle(edges map[string][]string) (string, bool) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+
+	var visit func(node string) (string, bool)
+	visit = func(node string) (string, bool) {
+		switch state[node] {
+		case visiting:
+			return node, true
+		case done:
+			return "", false
+		}
+
+		state[node] = visiting
+		for _, next := range edges[node] {
+			if cycleNode, found := visit(next); found {
+				return cycleNode, true
+			}
+		}
+		state[node] = done
+		return "", false
+	}
+
+	for _, node := range slices.Sorted(maps.Keys(edges)) {
+		if cycleNode, found := visit(node); found {
+			return cycleNode, true
+		}
+	}
+	return "", false
+}
+
+func (s State) desiredSubjects(g graph, current graph) error {
+	seen := map[string]bool{}
+	for _, declared := range s.Subjects {
+		if declared.Name == "" {
+			return invalid("subject without a name")
+		}
+		if seen[declared.Name] {
+			return invalid("subject %q is declared twice", declared.Name)
+		}
+		seen[declared.Name] = true
+
+		subjectType, err := subject.SubjectTypeFromString(declared.Type)
+		if err != nil {
+			return invalid("subject %q: %v", declared.Name, err)
+		}
+		if existing, exists := current.subjects[declared.Name]; exists && existing.Type != string(subjectType) {
+			return invalid("subject %q: cannot change type from %s to %s", declared.Name, existing.Type, subjectType)
+		}
+		g.subjects[declared.Name] = db.SubjectRecord{Name: declared.Name, Type: string(subjectType)}
+	}
+
+	for _, declared := range s.Subjects {
+		groups := []string{}
+		for _, group := range declared.Groups {
+			parent, exists := g.subjects[group]
+			if !exists {
+				return invalid("subject %q: unknown group %q", declared.Name, group)
+			}
+			if parent.Type != string(subject.SubjectTypeGroup) {
+				return invalid("subject %q: %q is not a Group", declared.Name, group)
+			}
+			if !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+
+		delete(g.subjectParents, declared.Name)
+		if len(groups) > 0 {
+			g.subjectParents[declared.Name] = slices.Sorted(slices.Values(groups))
+		}
+	}
+
+	if name, found := findCycle(g.subjectParents); found {
+		return invalid("groups form a cycle through %q", name)
+	}
+	return nil
+}
+
+func (s State) desiredResources(g graph) error {
+	seen := map[string]bool{}
+	for _, declared := range s.Resources {
+		if declared.Name == "" {
+			return invalid("resource without a name")
+		}
+		if seen[declared.Name] {
+			return invalid("resource %q is declared twice", declared.Name)
+		}
+		seen[declared.Name] = true
+		g.resources[declared.Name] = true
+	}
+
+	for _, declared := range s.Resources {
+		parents := []string{}
+		for _, parent := range declared.parents() {
+			if parent == declared.Name || !g.resources[parent] {
+				return invalid("resource %q: unknown parent %q", declared.Name, parent)
+			}
+			if !slices.Contains(parents, parent) {
+				parents = append(parents, parent)
+			}
+		}
+
+		delete(g.resourceParents, declared.Name)
+		if len(parents) > 0 {
+			g.resourceParents[declared.Name] = slices.Sorted(slices.Values(parents))
+		}
+	}
+
+	if name, found := findCycle(g.resourceParents); found {
+		return invalid("resource parents form a cycle through %q", name)
+	}
+	return nil
+}
+
+func (s State) desiredKeys(g graph) error {
+	root := db.SpecifierRecord{Key: "*", Value: "*"}
+
+	seen := map[string]bool{}
+	for _, declared := range s.Keys {
+		if seen[declared.Name] {
+			return invalid("specifier key %q is declared twice", declared.Name)
+		}
+		seen[declared.Name] = true
+
+		keyType, err := specifier.SpecifierTypeFromString(declared.Type)
+		if err != nil {
+			return invalid("specifier key %q: %v", declared.Name, err)
+		}
+		key := specifier.SpecifierKey{
+			Name:        declared.Name,
+			Type:        keyType,
+			Description: declared.Description,
+			Values:      declared.Values,
+			Default:     declared.Default,
+			Required:    declared.Required,
+		}
+		if err := key.Validate(); err != nil {
+			return invalid("specifier key %q: %v", declared.Name, err)
+		}
+		g.registeredKeys[declared.Name] = key.Record()
+
+		// The specifiers SpecifierKey.Create would create
+		if _, exists := g.specifiers[root]; !exists {
+			g.specifiers[root] = nil
+		}
+		keyRoot := db.SpecifierRecord{Key: key.Name, Value: "*"}
+		g.specifiers[keyRoot] = []db.SpecifierRecord{root}
+
+		values := slices.Clone(key.Values)
+		if key.Default != "" && !key.Type.Typed() {
+			values = append(values, key.Default)
+		}
+		for _, value := range values {
+			if _, exists := g.specifiers[db.SpecifierRecord{Key: key.Name, Value: value}]; !exists {
+				g.specifiers[db.SpecifierRecord{Key: key.Name, Value: value}] = []db.SpecifierRecord{keyRoot}
+			}
+		}
+	}
+	return nil
+}
+
+func (s State) desiredSpecifiers(g graph) error {
+	root := db.SpecifierRecord{Key: "*", Value: "*"}
+	if _, exists := g.specifiers[root]; !exists {
+		g.specifiers[root] = nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(s.Specifiers)) {
+		if key == "" || key == "*" {
+			return invalid("invalid specifier key %q", key)
+		}
+
+		keyRoot := db.SpecifierRecord{Key: key, Value: "*"}
+		g.specifiers[keyRoot] = []db.SpecifierRecord{root}
+
+		seen := map[string]bool{}
+		for _, declared := range s.Specifiers[key] {
+			if declared.Value == "" || declared.Value == "*" {
+				return invalid("specifier %s: invalid value %q", key, declared.Value)
+			}
+			if seen[declared.Value] {
+				return invalid("specifier %s=%s is declared twice", key, declared.Value)
+			}
+			seen[declared.Value] = true
+			if !g.allows(key, declared.Value) {
+				return invalid("specifier %s=%s: value not accepted by the specifier key", key, declared.Value)
+			}
+			if err := g.checkConstraint(key, declared.Value); err != nil {
+				return invalid("specifier %s=%s: %v", key, declared.Value, err)
+			}
+			if t := specifier.SpecifierType(g.registeredKeys[key].Type); t.Typed() && len(declared.parents()) > 0 {
+				return invalid("specifier %s=%s: constraints of a %s key cannot have parents", key, declared.Value, t)
+			}
+
+			if _, exists := g.specifiers[db.SpecifierRecord{Key: key, Value: declared.Value}]; !exists {
+				g.specifiers[db.SpecifierRecord{Key: key, Value: declared.Value}] = nil
+			}
+		}
+
+		for _, declared := range s.Specifiers[key] {
+			parents := []db.SpecifierRecord{}
+			for _, value := range declared.parents() {
+				parent := db.SpecifierRecord{Key: key, Value: value}
+				if _, exists := g.specifiers[parent]; !exists || value == declared.Value {
+					return invalid("specifier %s=%s: unknown parent %q", key, declared.Value, value)
+				}
+				if !slices.Contains(parents, parent) {
+					parents = append(parents, parent)
+				}
+			}
+			if len(parents) == 0 {
+				parents = append(parents, keyRoot)
+			}
+
+			slices.SortFunc(parents, compareSpecifiers)
+			g.specifiers[db.SpecifierRecord{Key: key, Value: declared.Value}] = parents
+		}
+	}
+	return nil
+}
+
+// allows reports whether the registered specifier key, if any, accepts the value, which for a
+// typed key is a constraint.
+func (g graph) allows(key string, value string) bool {
+	registered, exists := g.registeredKeys[key]
+	if !exists || value == "*" {
+		return true
+	}
+	if t := specifier.SpecifierType(registered.Type); t.Typed() {
+		_, err := t.ParseConstraint(value)
+		return err == nil
+	}
+	return len(registered.Values) == 0 || slices.Contains(registered.Values, value)
+}
+
+// checkConstraint fails if the value is a constraint of a type but its key is neither declared nor
+// registered, which would make it a plain string specifier.
+func (g graph) checkConstraint(key string, value string) error {
+	if _, registered := g.registeredKeys[key]; registered {
+		return nil
+	}
+	if t, isConstraint := specifier.ConstraintType(value); isConstraint {
+		return fmt.Errorf("%s constraint of an undeclared key, declare %s with its type", t, key)
+	}
+	return nil
+}
+
+// specifierKeys returns the keys of the graph, the ones every policy gets an edge for.
+func (g graph) specifierKeys() []string {
+	keys := []string{}
+	for specifier := range g.specifiers {
+		if specifier.Key != "*" && !slices.Contains(keys, specifier.Key) {
+			keys = append(keys, specifier.Key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (s State) desiredPolicies(g graph) error {
+	keys := g.specifierKeys()
+
+	seen := map[string]bool{}
+	for _, declared := range s.Policies {
+		id := declared.StableId()
+		if seen[id] {
+			return invalid("policy %s is declared twice", id)
+		}
+		seen[id] = true
+
+		policySubject, exists := g.subjects[declared.Subject]
+		if !exists {
+			return invalid("policy %s: unknown subject %q", id, declared.Subject)
+		}
+		if !g.resources[declared.Resource] {
+			return invalid("policy %s: unknown resource %q", id, declared.Resource)
+		}
+		if !g.actionExists(declared.Action) {
+			return invalid("policy %s: unknown action %q", id, declared.Action)
+		}
+		effect, err := policy.EffectFromString(declared.Effect)
+		if err != nil {
+			return invalid("policy %s: %v", id, err)
+		}
+
+		for k, v := range declared.Specifiers {
+			if _, exists := g.specifiers[db.SpecifierRecord{Key: k, Value: v}]; !exists || k == "*" || !g.allows(k, v) {
+				return invalid("policy %s: unknown specifier %s=%s", id, k, v)
+			}
+			if err := g.checkConstraint(k, v); err != nil {
+				return invalid("policy %s: specifier %s=%s: %v", id, k, v, err)
+			}
+		}
+
+		// Policies get an edge for every key, the undeclared ones being wildcards
+		specifiers := []db.SpecifierRecord{}
+		for _, k := range keys {
+			v, declaredKey := declared.Specifiers[k]
+			if !declaredKey {
+				v = "*"
+			}
+			specifiers = append(specifiers, db.SpecifierRecord{Key: k, Value: v})
+		}
+
+		g.policies[id] = []db.PolicyRecord{{
+			Id:         id,
+			Subject:    policySubject,
+			Resource:   db.ResourceRecord{Name: declared.Resource},
+			Action:     declared.Action,
+			Effect:     string(effect),
+			Specifiers: specifiers,
+		}}
+	}
+	return nil
+}
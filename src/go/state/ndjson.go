@@ -0,0 +1,182 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// The NDJSON encoding of a State holds one JSON object per line, tagged with its kind.
+// The first line is the header carrying the version, e.g.
+//
+//	{"kind":"state","version":1}
+//	{"kind":"subject","name":"Principal1","type":"Principal","groups":["Group1"]}
+//	{"kind":"key","name":"Env","values":["dev","prod"],"default":"dev"}
+//	{"kind":"specifier","key":"Env","value":"prod"}
+const (
+	kindState     = "state"
+	kindAction    = "action"
+	kindSubject   = "subject"
+	kindResource  = "resource"
+	kindKey       = "key"
+	kindSpecifier = "specifier"
+	kindPolicy    = "policy"
+)
+
+type ndjsonHeader struct {
+	Kind    string `json:"kind"`
+	Version int    `json:"version,omitempty"`
+}
+
+// keyedSpecifier is a specifier along with the key it is listed under in the State.
+type keyedSpecifier struct {
+	Key string `json:"key"`
+	Specifier
+}
+
+// writeLine writes the value as a JSON object, with the kind as its first field.
+func writeLine(w io.Writer, kind string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if len(data) <= 2 {
+		_, err = fmt.Fprintf(w, "{\"kind\":%q}\n", kind)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "{\"kind\":%q,%s\n", kind, data[1:])
+	return err
+}
+
+// EncodeNDJSON writes the state as NDJSON, one entity per line.
+func EncodeNDJSON(w io.Writer, s State) error {
+	if err := json.NewEncoder(w).Encode(ndjsonHeader{Kind: kindState, Version: s.Version}); err != nil {
+		return err
+	}
+
+	for _, a := range s.Actions {
+		if err := writeLine(w, kindAction, a); err != nil {
+			return err
+		}
+	}
+	for _, subject := range s.Subjects {
+		if err := writeLine(w, kindSubject, subject); err != nil {
+			return err
+		}
+	}
+	for _, resource := range s.Resources {
+		if err := writeLine(w, kindResource, resource); err != nil {
+			return err
+		}
+	}
+	for _, key := range s.Keys {
+		if err := writeLine(w, kindKey, key); err != nil {
+			return err
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.Specifiers)) {
+		// Keys without values still need a line, for their `<key>=*` root
+		if len(s.Specifiers[key]) == 0 {
+			if err := writeLine(w, kindSpecifier, struct {
+				Key string `json:"key"`
+			}{key}); err != nil {
+				return err
+			}
+		}
+		for _, specifier := range s.Specifiers[key] {
+			if err := writeLine(w, kindSpecifier, keyedSpecifier{Key: key, Specifier: specifier}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, policy := range s.Policies {
+		if err := writeLine(w, kindPolicy, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeNDJSON reads a state written by EncodeNDJSON.
+func DecodeNDJSON(r io.Reader) (State, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	s := State{Specifiers: map[string][]Specifier{}}
+	header := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var tag ndjsonHeader
+		if err := json.Unmarshal(data, &tag); err != nil {
+			return State{}, fmt.Errorf("%w: line %d: %w", ErrInvalidState, line, err)
+		}
+
+		if !header {
+			if tag.Kind != kindState {
+				return State{}, fmt.Errorf("%w: line %d: expected the %q header first", ErrInvalidState, line, kindState)
+			}
+			if tag.Version != Version {
+				return State{}, fmt.Errorf("%w: %d, expected %d", ErrUnsupportedVersion, tag.Version, Version)
+			}
+			s.Version = tag.Version
+			header = true
+			continue
+		}
+
+		var err error
+		switch tag.Kind {
+		case kindAction:
+			var a Action
+			err = json.Unmarshal(data, &a)
+			s.Actions = append(s.Actions, a)
+		case kindSubject:
+			var subject Subject
+			err = json.Unmarshal(data, &subject)
+			s.Subjects = append(s.Subjects, subject)
+		case kindResource:
+			var resource Resource
+			err = json.Unmarshal(data, &resource)
+			s.Resources = append(s.Resources, resource)
+		case kindKey:
+			var key Key
+			err = json.Unmarshal(data, &key)
+			s.Keys = append(s.Keys, key)
+		case kindSpecifier:
+			var specifier keyedSpecifier
+			err = json.Unmarshal(data, &specifier)
+			if specifier.Value == "" {
+				if _, exists := s.Specifiers[specifier.Key]; !exists {
+					s.Specifiers[specifier.Key] = []Specifier{}
+				}
+				break
+			}
+			s.Specifiers[specifier.Key] = append(s.Specifiers[specifier.Key], specifier.Specifier)
+		case kindPolicy:
+			var policy Policy
+			err = json.Unmarshal(data, &policy)
+			s.Policies = append(s.Policies, policy)
+		default:
+			err = fmt.Errorf("unknown kind %q", tag.Kind)
+		}
+		if err != nil {
+			return State{}, fmt.Errorf("%w: line %d: %w", ErrInvalidState, line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return State{}, err
+	}
+	if !header {
+		return State{}, fmt.Errorf("%w: empty state", ErrInvalidState)
+	}
+	return s, nil
+}
@@ -0,0 +1,399 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+)
+
+type Operation string
+
+const (
+	OperationCreate Operation = "+"
+	OperationUpdate Operation = "~"
+	OperationDelete Operation = "-"
+)
+
+// Change is a single step of a Plan.
+type Change struct {
+	Operation Operation `json:"operation" yaml:"operation"`
+	Kind      string    `json:"kind" yaml:"kind"`
+	Name      string    `json:"name" yaml:"name"`
+	Detail    string    `json:"detail,omitempty" yaml:"detail,omitempty"`
+
+	apply func(ctx context.Context, store db.Store) error
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Operation, c.Kind, c.Name)
+	if c.Detail != "" {
+		s += fmt.Sprintf(" (%s)", c.Detail)
+	}
+	return s
+}
+
+// Plan is the ordered list of changes reconciling the store with a State.
+type Plan struct {
+	Changes []Change
+}
+
+// Options tune how a State is reconciled.
+type Options struct {
+	// Prune deletes everything the state does not declare. Otherwise it is left untouched.
+	Prune bool
+}
+
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the operation.
+func (p Plan) Count(operation Operation) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Operation == operation {
+			count++
+		}
+	}
+	return count
+}
+
+// String renders the plan one change per line, followed by a summary.
+func (p Plan) String() string {
+	if p.Empty() {
+		return "No changes. The store matches the state."
+	}
+
+	var b strings.Builder
+	for _, change := range p.Changes {
+		b.WriteString(change.String())
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.",
+		p.Count(OperationCreate), p.Count(OperationUpdate), p.Count(OperationDelete))
+	return b.String()
+}
+
+// Plan compares the state with the store contents and returns the changes reconciling them.
+// The state is validated against what the store will contain once the plan is applied.
+func (s State) Plan(ctx context.Context, options Options) (Plan, error) {
+	start := time.Now()
+
+	current, err := readGraph(ctx, db.FromContext(ctx))
+	if err != nil {
+		return Plan{}, err
+	}
+
+	desired, err := s.desiredGraph(current, options.Prune)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{Changes: diff(current, desired)}
+
+	slog.Info("State.Plan",
+		"prune", options.Prune,
+		"changes", len(plan.Changes),
+		"duration", time.Since(start),
+	)
+	return plan, nil
+}
+
+// Apply runs the changes in order in a single transaction. It stops at the first failing change,
+// leaving the store as it was before.
+func (p Plan) Apply(ctx context.Context) error {
+	start := time.Now()
+
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		store := db.FromContext(ctx)
+		for _, change := range p.Changes {
+			if err := change.apply(ctx, store); err != nil {
+				return fmt.Errorf("%s: %w", change, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Plan.Apply",
+		"changes", len(p.Changes),
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// missing returns the sorted elements of desired that are not in current.
+func missing[S ~[]E, E comparable](desired S, current S, compare func(a, b E) int) S {
+	result := S{}
+	for _, e := range desired {
+		if !slices.Contains(current, e) {
+			result = append(result, e)
+		}
+	}
+	slices.SortFunc(result, compare)
+	return result
+}
+
+func describePolicy(record db.PolicyRecord) string {
+	description := fmt.Sprintf("%s %s %s on %s", record.Effect, record.Subject.Name, record.Action, record.Resource.Name)
+
+	specifiers := []string{}
+	for _, specifier := range record.Specifiers {
+		if specifier.Value != "*" {
+			specifiers = append(specifiers, specifier.Key+"="+specifier.Value)
+		}
+	}
+	if len(specifiers) > 0 {
+		description += " with " + strings.Join(specifiers, ",")
+	}
+	return description
+}
+
+func samePolicy(a []db.PolicyRecord, b []db.PolicyRecord) bool {
+	return len(a) == 1 && len(b) == 1 &&
+		a[0].Subject.Name == b[0].Subject.Name &&
+		a[0].Resource.Name == b[0].Resource.Name &&
+		a[0].Action == b[0].Action &&
+		a[0].Effect == b[0].Effect &&
+		slices.Equal(a[0].Specifiers, b[0].Specifiers)
+}
+
+func sameKey(a db.SpecifierKeyRecord, b db.SpecifierKeyRecord) bool {
+	return a.Type == b.Type &&
+		a.Description == b.Description &&
+		slices.Equal(a.Values, b.Values) &&
+		a.Default == b.Default &&
+		a.Required == b.Required
+}
+
+// createPolicy creates the policy with the stable ID of the state, after the checks of Policy.Create.
+// Like Policy.Create, it creates the constraints of typed keys the store does not have yet.
+func createPolicy(ctx context.Context, store db.Store, record db.PolicyRecord) error {
+	p, err := policy.ProcessPolicyRecord(ctx, record)
+	if err != nil {
+		return err
+	}
+	p, missingConstraints, err := p.Check(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, constraint := range missingConstraints {
+		if err := store.CreateSpecifierAsChildOf(ctx, constraint.Record(), db.SpecifierRecord{Key: constraint.Key, Value: "*"}); err != nil {
+			return err
+		}
+	}
+
+	id, err := store.CreatePolicy(ctx, p.Record())
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return policy.ErrPolicyNotCreated
+	}
+	return nil
+}
+
+// diff returns the changes turning current into desired. Nodes are created before the edges
+// and policies using them, and deleted after everything pointing at them.
+func diff(current graph, desired graph) []Change {
+	changes := []Change{}
+
+	for _, name := range slices.Sorted(maps.Keys(desired.actions)) {
+		if !current.actions[name] {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "action", Name: name,
+				apply: func(ctx context.Context, store db.Store) error { return store.CreateAction(ctx, name) }})
+		}
+	}
+	// Keys are replaced as a whole, and before the specifiers and policies they check
+	for _, name := range slices.Sorted(maps.Keys(desired.registeredKeys)) {
+		record := desired.registeredKeys[name]
+		existing, exists := current.registeredKeys[name]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "specifier key", Name: name, Detail: record.Type,
+				apply: func(ctx context.Context, store db.Store) error { return store.CreateSpecifierKey(ctx, record) }})
+		case !sameKey(existing, record):
+			changes = append(changes, Change{Operation: OperationUpdate, Kind: "specifier key", Name: name, Detail: record.Type,
+				apply: func(ctx context.Context, store db.Store) error {
+					if err := store.DeleteSpecifierKey(ctx, name); err != nil {
+						return err
+					}
+					return store.CreateSpecifierKey(ctx, record)
+				}})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(desired.subjects)) {
+		if _, exists := current.subjects[name]; !exists {
+			record := desired.subjects[name]
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "subject", Name: name, Detail: record.Type,
+				apply: func(ctx context.Context, store db.Store) error { return store.CreateSubject(ctx, record) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(desired.resources)) {
+		if !current.resources[name] {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "resource", Name: name,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.CreateResource(ctx, db.ResourceRecord{Name: name})
+				}})
+		}
+	}
+	for _, specifier := range slices.SortedFunc(maps.Keys(desired.specifiers), compareSpecifiers) {
+		if _, exists := current.specifiers[specifier]; !exists {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "specifier", Name: specifier.Key + "=" + specifier.Value,
+				apply: func(ctx context.Context, store db.Store) error { return store.CreateSpecifier(ctx, specifier) }})
+		}
+	}
+
+	// Edges to add
+	for _, name := range slices.Sorted(maps.Keys(desired.implications)) {
+		for _, implied := range missing(desired.implications[name], current.implications[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "implication", Name: name + " -> " + implied,
+				apply: func(ctx context.Context, store db.Store) error { return store.AddActionImplication(ctx, name, implied) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(desired.subjectParents)) {
+		for _, parent := range missing(desired.subjectParents[name], current.subjectParents[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "group", Name: name + " -> " + parent,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.AddSubjectParent(ctx, desired.subjects[name], desired.subjects[parent])
+				}})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(desired.resourceParents)) {
+		for _, parent := range missing(desired.resourceParents[name], current.resourceParents[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "resource parent", Name: name + " -> " + parent,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.AddResourceParent(ctx, db.ResourceRecord{Name: name}, db.ResourceRecord{Name: parent})
+				}})
+		}
+	}
+	for _, specifier := range slices.SortedFunc(maps.Keys(desired.specifiers), compareSpecifiers) {
+		for _, parent := range missing(desired.specifiers[specifier], current.specifiers[specifier], compareSpecifiers) {
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "specifier parent",
+				Name: fmt.Sprintf("%s=%s -> %s=%s", specifier.Key, specifier.Value, parent.Key, parent.Value),
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.AddSpecifierParent(ctx, specifier, parent)
+				}})
+		}
+	}
+
+	// Edges to remove, on the nodes that are kept
+	for _, name := range slices.Sorted(maps.Keys(current.implications)) {
+		if current.actions[name] && !desired.actions[name] {
+			continue
+		}
+		for _, implied := range missing(current.implications[name], desired.implications[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "implication", Name: name + " -> " + implied,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.RemoveActionImplication(ctx, name, implied)
+				}})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.subjectParents)) {
+		if _, kept := desired.subjects[name]; !kept {
+			continue
+		}
+		for _, parent := range missing(current.subjectParents[name], desired.subjectParents[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "group", Name: name + " -> " + parent,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.RemoveSubjectParent(ctx, current.subjects[name], current.subjects[parent])
+				}})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.resourceParents)) {
+		if !desired.resources[name] {
+			continue
+		}
+		for _, parent := range missing(current.resourceParents[name], desired.resourceParents[name], strings.Compare) {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "resource parent", Name: name + " -> " + parent,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.RemoveResourceParent(ctx, db.ResourceRecord{Name: name}, db.ResourceRecord{Name: parent})
+				}})
+		}
+	}
+	for _, specifier := range slices.SortedFunc(maps.Keys(current.specifiers), compareSpecifiers) {
+		if _, kept := desired.specifiers[specifier]; !kept {
+			continue
+		}
+		for _, parent := range missing(current.specifiers[specifier], desired.specifiers[specifier], compareSpecifiers) {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "specifier parent",
+				Name: fmt.Sprintf("%s=%s -> %s=%s", specifier.Key, specifier.Value, parent.Key, parent.Value),
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.RemoveSpecifierParent(ctx, specifier, parent)
+				}})
+		}
+	}
+
+	// Policies are replaced as a whole, keeping their ID
+	for _, id := range slices.Sorted(maps.Keys(desired.policies)) {
+		records := desired.policies[id]
+		existing, exists := current.policies[id]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Operation: OperationCreate, Kind: "policy", Name: id, Detail: describePolicy(records[0]),
+				apply: func(ctx context.Context, store db.Store) error { return createPolicy(ctx, store, records[0]) }})
+		case !samePolicy(existing, records):
+			changes = append(changes, Change{Operation: OperationUpdate, Kind: "policy", Name: id, Detail: describePolicy(records[0]),
+				apply: func(ctx context.Context, store db.Store) error {
+					if err := store.DeletePolicy(ctx, id); err != nil {
+						return err
+					}
+					return createPolicy(ctx, store, records[0])
+				}})
+		}
+	}
+
+	// Everything left undeclared, only when pruning
+	for _, id := range slices.Sorted(maps.Keys(current.policies)) {
+		if _, kept := desired.policies[id]; !kept {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "policy", Name: id, Detail: describePolicy(current.policies[id][0]),
+				apply: func(ctx context.Context, store db.Store) error { return store.DeletePolicy(ctx, id) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.subjects)) {
+		if _, kept := desired.subjects[name]; !kept {
+			record := current.subjects[name]
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "subject", Name: name, Detail: record.Type,
+				apply: func(ctx context.Context, store db.Store) error { return store.DeleteSubject(ctx, record) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.resources)) {
+		if !desired.resources[name] {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "resource", Name: name,
+				apply: func(ctx context.Context, store db.Store) error {
+					return store.DeleteResource(ctx, db.ResourceRecord{Name: name})
+				}})
+		}
+	}
+	for _, specifier := range slices.SortedFunc(maps.Keys(current.specifiers), compareSpecifiers) {
+		if _, kept := desired.specifiers[specifier]; !kept {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "specifier", Name: specifier.Key + "=" + specifier.Value,
+				apply: func(ctx context.Context, store db.Store) error { return store.DeleteSpecifier(ctx, specifier) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.registeredKeys)) {
+		if _, kept := desired.registeredKeys[name]; !kept {
+			record := current.registeredKeys[name]
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "specifier key", Name: name, Detail: record.Type,
+				apply: func(ctx context.Context, store db.Store) error { return store.DeleteSpecifierKey(ctx, name) }})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.actions)) {
+		if !desired.actions[name] {
+			changes = append(changes, Change{Operation: OperationDelete, Kind: "action", Name: name,
+				apply: func(ctx context.Context, store db.Store) error { return store.DeleteAction(ctx, name) }})
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,162 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the version of the state file format understood by this package.
+const Version = 1
+
+var ErrUnsupportedVersion = db.NewError(db.ErrInvalidInput, "unsupported state version")
+var ErrInvalidState = db.NewError(db.ErrInvalidInput, "invalid state")
+
+// policyNamespace is the UUIDv5 namespace of the stable policy IDs.
+var policyNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/namsnath/otter/policy"))
+
+// State is the declarative description of the graph: actions, subjects with their groups,
+// the resource tree, the registered specifier keys, the specifier tree and the policies.
+//
+// The root specifier `*=*` and the `<key>=*` root of every key are implicit.
+type State struct {
+	Version    int                    `yaml:"version" json:"version"`
+	Actions    []Action               `yaml:"actions,omitempty" json:"actions,omitempty"`
+	Subjects   []Subject              `yaml:"subjects,omitempty" json:"subjects,omitempty"`
+	Resources  []Resource             `yaml:"resources,omitempty" json:"resources,omitempty"`
+	Keys       []Key                  `yaml:"keys,omitempty" json:"keys,omitempty"`
+	Specifiers map[string][]Specifier `yaml:"specifiers,omitempty" json:"specifiers,omitempty"`
+	Policies   []Policy               `yaml:"policies,omitempty" json:"policies,omitempty"`
+}
+
+// Action declares a custom action, or the implications of a built-in one.
+type Action struct {
+	Name    string   `yaml:"name" json:"name"`
+	Implies []string `yaml:"implies,omitempty" json:"implies,omitempty"`
+}
+
+type Subject struct {
+	Name   string   `yaml:"name" json:"name"`
+	Type   string   `yaml:"type" json:"type"`
+	Groups []string `yaml:"groups,omitempty" json:"groups,omitempty"`
+}
+
+// Resource declares a resource. Resources without a parent are roots.
+// Parents lists additional parents, for resources with more than one.
+type Resource struct {
+	Name    string   `yaml:"name" json:"name"`
+	Parent  string   `yaml:"parent,omitempty" json:"parent,omitempty"`
+	Parents []string `yaml:"parents,omitempty" json:"parents,omitempty"`
+}
+
+func (r Resource) parents() []string {
+	if r.Parent == "" {
+		return r.Parents
+	}
+	return append([]string{r.Parent}, r.Parents...)
+}
+
+// Key registers a specifier key, see specifier.SpecifierKey. Like registering it, declaring an
+// untyped key declares a specifier for each of its values and its default.
+//
+// Constraints such as `10..100` are only accepted for the keys of their type, declared or registered,
+// so that they are not silently taken as plain string specifiers.
+type Key struct {
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type,omitempty" json:"type,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Values      []string `yaml:"values,omitempty" json:"values,omitempty"`
+	Default     string   `yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool     `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+// Specifier declares a value of the key it is listed under.
+// Values without a parent are children of the `<key>=*` root.
+// Parents lists additional parents, for values with more than one.
+type Specifier struct {
+	Value   string   `yaml:"value" json:"value"`
+	Parent  string   `yaml:"parent,omitempty" json:"parent,omitempty"`
+	Parents []string `yaml:"parents,omitempty" json:"parents,omitempty"`
+}
+
+func (s Specifier) parents() []string {
+	if s.Parent == "" {
+		return s.Parents
+	}
+	return append([]string{s.Parent}, s.Parents...)
+}
+
+// Policy declares a policy. Keys missing from the specifiers are wildcards.
+// When the ID is empty, a stable one is derived from the rest of the policy.
+type Policy struct {
+	Id         string            `yaml:"id,omitempty" json:"id,omitempty"`
+	Subject    string            `yaml:"subject" json:"subject"`
+	Resource   string            `yaml:"resource" json:"resource"`
+	Action     string            `yaml:"action" json:"action"`
+	Effect     string            `yaml:"effect,omitempty" json:"effect,omitempty"`
+	Specifiers map[string]string `yaml:"specifiers,omitempty" json:"specifiers,omitempty"`
+}
+
+// StableId returns the ID of the policy: the declared one, or a UUIDv5 of its contents.
+// Changing anything but the ID of an undeclared-ID policy therefore gives it a new identity.
+func (p Policy) StableId() string {
+	if p.Id != "" {
+		return p.Id
+	}
+
+	effect, err := policy.EffectFromString(p.Effect)
+	if err != nil {
+		effect = policy.Effect(p.Effect)
+	}
+
+	parts := []string{p.Subject, p.Resource, p.Action, string(effect)}
+	for _, k := range slices.Sorted(maps.Keys(p.Specifiers)) {
+		parts = append(parts, k+"="+p.Specifiers[k])
+	}
+	return uuid.NewSHA1(policyNamespace, []byte(strings.Join(parts, "\x00"))).String()
+}
+
+// Parse reads a state from YAML. JSON is accepted as well, being a subset of YAML.
+func Parse(data []byte) (State, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var state State
+	if err := decoder.Decode(&state); err != nil {
+		if errors.Is(err, io.EOF) {
+			return State{}, fmt.Errorf("%w: empty state", ErrInvalidState)
+		}
+		return State{}, fmt.Errorf("%w: %w", ErrInvalidState, err)
+	}
+
+	if state.Version != Version {
+		return State{}, fmt.Errorf("%w: %d, expected %d", ErrUnsupportedVersion, state.Version, Version)
+	}
+	return state, nil
+}
+
+// Load reads the state from the file at path, or from stdin if the path is `-`.
+func Load(path string) (State, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	return Parse(data)
+}
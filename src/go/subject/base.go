@@ -1,6 +1,6 @@
 package subject
 
-import "fmt"
+import "github.com/namsnath/otter/db"
 
 type SubjectType string
 
@@ -9,7 +9,7 @@ const (
 	SubjectTypeGroup     SubjectType = "Group"
 )
 
-var ErrInvalidSubjectType = fmt.Errorf("invalid SubjectType")
+var ErrInvalidSubjectType = db.NewError(db.ErrInvalidInput, "invalid SubjectType")
 
 func SubjectTypeFromString(s string) (SubjectType, error) {
 	switch s {
@@ -23,6 +23,6 @@ func SubjectTypeFromString(s string) (SubjectType, error) {
 }
 
 type Subject struct {
-	Name string
-	Type SubjectType
+	Name string      `json:"name" yaml:"name"`
+	Type SubjectType `json:"type" yaml:"type"`
 }
@@ -1,39 +1,88 @@
 package subject
 
-import "github.com/namsnath/otter/db"
-
-func (subject Subject) Create() Subject {
-	db.ExecuteQuery(`
-		CREATE (s:Subject {name: $name, type: $type})
-		`,
-		map[string]any{
-			"name": subject.Name,
-			"type": subject.Type,
-		},
-	)
-
-	return subject
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/namsnath/otter/db"
+)
+
+// Record returns the storage representation of the subject.
+func (subject Subject) Record() db.SubjectRecord {
+	return db.SubjectRecord{Name: subject.Name, Type: string(subject.Type)}
+}
+
+// Create stores the subject, failing with ErrSubjectExists if a subject has the same name.
+func (subject Subject) Create(ctx context.Context) (Subject, error) {
+	err := db.FromContext(ctx).CreateSubject(ctx, subject.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Subject{}, ErrSubjectExists
+	}
+	if err != nil {
+		return Subject{}, err
+	}
+
+	return subject, nil
 }
 
-func (subject Subject) CreateAsChildOf(parent Subject) Subject {
-	// TODO: Make these functions return an error instead of panicking
+// CreateAsChildOf creates the subject as a member of the parent, which must be an existing Group.
+// It fails with ErrSubjectNotFound, creating nothing, if the parent does not exist.
+func (subject Subject) CreateAsChildOf(ctx context.Context, parent Subject) (Subject, error) {
 	if parent.Type != SubjectTypeGroup {
-		panic("can only create child subjects under Group type subjects")
+		return Subject{}, ErrNotAGroup
+	}
+
+	err := db.FromContext(ctx).CreateSubjectAsChildOf(ctx, subject.Record(), parent.Record())
+	if errors.Is(err, db.ErrConflict) {
+		return Subject{}, ErrSubjectExists
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return Subject{}, fmt.Errorf("group %s: %w", parent.Name, ErrSubjectNotFound)
+	}
+	if err != nil {
+		return Subject{}, err
+	}
+
+	return subject, nil
+}
+
+// GetOrCreate returns the subject if it exists, and creates it otherwise. A subject with the same
+// name and another type is a conflict.
+func (subject Subject) GetOrCreate(ctx context.Context) (Subject, error) {
+	return subject.getOrCreate(ctx, func(ctx context.Context) (Subject, error) {
+		return subject.Create(ctx)
+	})
+}
+
+// GetOrCreateAsChildOf returns the subject if it exists, and creates it as a member of the parent otherwise.
+// The groups of an existing subject are left unchanged.
+func (subject Subject) GetOrCreateAsChildOf(ctx context.Context, parent Subject) (Subject, error) {
+	return subject.getOrCreate(ctx, func(ctx context.Context) (Subject, error) {
+		return subject.CreateAsChildOf(ctx, parent)
+	})
+}
+
+func (subject Subject) getOrCreate(ctx context.Context, create func(ctx context.Context) (Subject, error)) (Subject, error) {
+	var result Subject
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		existing, err := Get(ctx, subject.Name)
+		if errors.Is(err, ErrSubjectNotFound) {
+			result, err = create(ctx)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Type != subject.Type {
+			return ErrSubjectExists
+		}
+		result = existing
+		return nil
+	})
+	if err != nil {
+		return Subject{}, err
 	}
 
-	db.ExecuteQuery(`
-		CREATE (s:Subject {name: $name, type: $type})
-		WITH s
-		MATCH (p:Subject {name: $parentName, type: $parentType})
-		CREATE (s)-[:CHILD_OF]->(p)
-		`,
-		map[string]any{
-			"name":       subject.Name,
-			"type":       subject.Type,
-			"parentName": parent.Name,
-			"parentType": parent.Type,
-		},
-	)
-
-	return subject
+	return result, nil
 }
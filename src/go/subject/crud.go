@@ -0,0 +1,257 @@
+package subject
+
+import (
+	"context"
+	"slices"
+
+	"github.com/namsnath/otter/db"
+)
+
+var ErrSubjectNotFound = db.NewError(db.ErrNotFound, "subject not found")
+var ErrSubjectExists = db.NewError(db.ErrConflict, "subject already exists")
+var ErrNotAGroup = db.NewError(db.ErrInvalidInput, "subject is not a Group")
+var ErrNotInGroup = db.NewError(db.ErrInvalidInput, "subject is not a member of the group")
+var ErrGroupCycle = db.NewError(db.ErrConflict, "group cannot be a member of itself or one of its members")
+var ErrSubjectInUse = db.NewError(db.ErrConflict, "subject holds policies or has members")
+
+func fromRecord(record db.SubjectRecord) (Subject, error) {
+	subjectType, err := SubjectTypeFromString(record.Type)
+	if err != nil {
+		return Subject{}, err
+	}
+	return Subject{Name: record.Name, Type: subjectType}, nil
+}
+
+// Get returns the subject with the name.
+func Get(ctx context.Context, name string) (Subject, error) {
+	node, found, err := db.FromContext(ctx).GetSubject(ctx, name)
+	if err != nil {
+		return Subject{}, err
+	}
+	if !found {
+		return Subject{}, ErrSubjectNotFound
+	}
+	return fromRecord(node.Subject)
+}
+
+// GetGroup returns the subject with the name, which must be a Group.
+func GetGroup(ctx context.Context, name string) (Subject, error) {
+	group, err := Get(ctx, name)
+	if err != nil {
+		return Subject{}, err
+	}
+	if group.Type != SubjectTypeGroup {
+		return Subject{}, ErrNotAGroup
+	}
+	return group, nil
+}
+
+// List returns the subjects of the type sorted by name, or all of them if the type is empty.
+func List(ctx context.Context, subjectType SubjectType) ([]Subject, error) {
+	nodes, err := db.FromContext(ctx).GetSubjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := []Subject{}
+	for _, node := range nodes {
+		if subjectType != "" && node.Subject.Type != string(subjectType) {
+			continue
+		}
+		s, err := fromRecord(node.Subject)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, nil
+}
+
+// Groups returns the groups the subject is a direct member of, sorted by name.
+func (subject Subject) Groups(ctx context.Context) ([]Subject, error) {
+	node, found, err := db.FromContext(ctx).GetSubject(ctx, subject.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !found || node.Subject.Type != string(subject.Type) {
+		return nil, ErrSubjectNotFound
+	}
+
+	groups := []Subject{}
+	for _, parent := range node.Parents {
+		group, err := fromRecord(parent)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// AddToGroup makes the subject a direct member of the group. Both must exist, and a group cannot
+// join itself or one of its members.
+func (subject Subject) AddToGroup(ctx context.Context, group Subject) error {
+	// The checks and the edge share a transaction, so a concurrent move cannot close a cycle
+	return db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := subject.Groups(ctx); err != nil {
+			return err
+		}
+		if err := subject.checkGroup(ctx, group); err != nil {
+			return err
+		}
+		return db.FromContext(ctx).AddSubjectParent(ctx, subject.Record(), group.Record())
+	})
+}
+
+// MoveTo replaces the groups of the subject with the given one, which must exist and not be the
+// subject or one of its members. The policies of the subject are kept.
+func (subject Subject) MoveTo(ctx context.Context, group Subject) error {
+	return db.InTx(ctx, func(ctx context.Context) error {
+		groups, err := subject.Groups(ctx)
+		if err != nil {
+			return err
+		}
+		if err := subject.checkGroup(ctx, group); err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		for _, g := range groups {
+			if g == group {
+				continue
+			}
+			if err := store.RemoveSubjectParent(ctx, subject.Record(), g.Record()); err != nil {
+				return err
+			}
+		}
+		return store.AddSubjectParent(ctx, subject.Record(), group.Record())
+	})
+}
+
+// checkGroup checks that the group exists and that the subject can join it without a cycle.
+func (subject Subject) checkGroup(ctx context.Context, group Subject) error {
+	if group.Type != SubjectTypeGroup {
+		return ErrNotAGroup
+	}
+	if existing, err := Get(ctx, group.Name); err != nil {
+		return err
+	} else if existing.Type != group.Type {
+		return ErrNotAGroup
+	}
+
+	cycle, err := subject.isAncestorOf(ctx, group)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrGroupCycle
+	}
+	return nil
+}
+
+// isAncestorOf reports whether the subject is the other one, or one of the groups it belongs to
+// directly or transitively.
+func (subject Subject) isAncestorOf(ctx context.Context, other Subject) (bool, error) {
+	visited := []Subject{other}
+	queue := []Subject{other}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == subject {
+			return true, nil
+		}
+
+		groups, err := current.Groups(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, group := range groups {
+			if !slices.Contains(visited, group) {
+				visited = append(visited, group)
+				queue = append(queue, group)
+			}
+		}
+	}
+	return false, nil
+}
+
+// RemoveFromGroup removes the subject from a group it is a direct member of.
+func (subject Subject) RemoveFromGroup(ctx context.Context, group Subject) error {
+	groups, err := subject.Groups(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		if g == group {
+			return db.FromContext(ctx).RemoveSubjectParent(ctx, subject.Record(), group.Record())
+		}
+	}
+	return ErrNotInGroup
+}
+
+// Members returns the subjects that are direct members of the subject, sorted by name.
+func (subject Subject) Members(ctx context.Context) ([]Subject, error) {
+	if _, err := subject.Groups(ctx); err != nil {
+		return nil, err
+	}
+
+	nodes, err := db.FromContext(ctx).GetSubjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []Subject{}
+	for _, node := range nodes {
+		if slices.Contains(node.Parents, subject.Record()) {
+			member, err := fromRecord(node.Subject)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// Delete removes the subject. The mode selects what happens to the policies it holds and to its members:
+// db.DeleteRefuse fails with ErrSubjectInUse if it has any, db.DeleteCascade deletes the policies
+// and removes the members from the group, and db.DeleteReparent deletes the policies and makes the
+// members join the groups of the subject instead.
+func (subject Subject) Delete(ctx context.Context, mode db.DeleteMode) error {
+	return db.InTx(ctx, func(ctx context.Context) error {
+		groups, err := subject.Groups(ctx)
+		if err != nil {
+			return err
+		}
+		members, err := subject.Members(ctx)
+		if err != nil {
+			return err
+		}
+
+		store := db.FromContext(ctx)
+		switch mode {
+		case db.DeleteRefuse:
+			policies, err := store.GetPolicies(ctx, db.PolicyFilter{SubjectName: subject.Name})
+			if err != nil {
+				return err
+			}
+			if len(policies) > 0 || len(members) > 0 {
+				return ErrSubjectInUse
+			}
+		case db.DeleteCascade:
+		case db.DeleteReparent:
+			for _, member := range members {
+				for _, group := range groups {
+					if err := store.AddSubjectParent(ctx, member.Record(), group.Record()); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			return db.ErrInvalidDeleteMode
+		}
+
+		return store.DeleteSubject(ctx, subject.Record())
+	})
+}
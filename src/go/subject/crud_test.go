@@ -0,0 +1,190 @@
+package subject_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/namsnath/otter/db"
+	"github.com/namsnath/otter/query"
+	"github.com/namsnath/otter/subject"
+)
+
+func TestSubjectCrud(t *testing.T) {
+	db.TestContainer(t)
+
+	testSubjectCrud(t)
+}
+
+func TestSubjectCrudInMemory(t *testing.T) {
+	db.SetupMemoryInstance()
+	testSubjectCrud(t)
+}
+
+func testSubjectCrud(t *testing.T) {
+	ctx := t.Context()
+	query.DeleteEverything(ctx)
+	query.SetupTestState(ctx)
+
+	principal1 := subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}
+	group1 := subject.Subject{Name: "Group1", Type: subject.SubjectTypeGroup}
+	group2 := subject.Subject{Name: "Group2", Type: subject.SubjectTypeGroup}
+
+	t.Run("Get and list", func(t *testing.T) {
+		s, err := subject.Get(ctx, "Principal1")
+		if err != nil || s != principal1 {
+			t.Errorf("Expected %v, got %v, %v", principal1, s, err)
+		}
+		if _, err := subject.Get(ctx, "Nobody"); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectNotFound, err)
+		}
+		if _, err := subject.GetGroup(ctx, "Principal1"); !errors.Is(err, subject.ErrNotAGroup) {
+			t.Errorf("Expected %v, got %v", subject.ErrNotAGroup, err)
+		}
+
+		groups, err := subject.List(ctx, subject.SubjectTypeGroup)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := []subject.Subject{group1, group2}; !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, got %v", expected, groups)
+		}
+	})
+
+	t.Run("Missing parent", func(t *testing.T) {
+		orphan := subject.Subject{Name: "Orphan", Type: subject.SubjectTypePrincipal}
+		for _, parent := range []subject.Subject{{Name: "Nobody", Type: subject.SubjectTypeGroup}, {Name: "Principal1", Type: subject.SubjectTypeGroup}} {
+			if _, err := orphan.CreateAsChildOf(ctx, parent); !errors.Is(err, subject.ErrSubjectNotFound) {
+				t.Errorf("Expected %v, got %v", subject.ErrSubjectNotFound, err)
+			}
+		}
+		if _, err := subject.Get(ctx, "Orphan"); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected nothing to be created, got %v", err)
+		}
+	})
+
+	t.Run("Create once", func(t *testing.T) {
+		if _, err := principal1.Create(ctx); !errors.Is(err, subject.ErrSubjectExists) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectExists, err)
+		}
+		if _, err := principal1.CreateAsChildOf(ctx, group2); !errors.Is(err, subject.ErrSubjectExists) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectExists, err)
+		}
+
+		if s, err := principal1.GetOrCreate(ctx); err != nil || s != principal1 {
+			t.Errorf("Expected %v, got %v, %v", principal1, s, err)
+		}
+		if _, err := (subject.Subject{Name: "Principal1", Type: subject.SubjectTypeGroup}).GetOrCreate(ctx); !errors.Is(err, subject.ErrSubjectExists) {
+			t.Errorf("Expected a type mismatch to be a conflict, got %v", err)
+		}
+		if groups, _ := principal1.Groups(ctx); !reflect.DeepEqual(groups, []subject.Subject{group1}) {
+			t.Errorf("Expected the groups of the existing subject to be kept, got %v", groups)
+		}
+
+		principal5 := subject.Subject{Name: "Principal5", Type: subject.SubjectTypePrincipal}
+		for range 2 {
+			if s, err := principal5.GetOrCreateAsChildOf(ctx, group2); err != nil || s != principal5 {
+				t.Errorf("Expected %v, got %v, %v", principal5, s, err)
+			}
+		}
+		if groups, _ := principal5.Groups(ctx); !reflect.DeepEqual(groups, []subject.Subject{group2}) {
+			t.Errorf("Expected %v, got %v", []subject.Subject{group2}, groups)
+		}
+	})
+
+	t.Run("Add to and remove from groups", func(t *testing.T) {
+		if err := principal1.AddToGroup(ctx, group2); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		groups, _ := principal1.Groups(ctx)
+		if expected := []subject.Subject{group1, group2}; !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, got %v", expected, groups)
+		}
+
+		if err := principal1.RemoveFromGroup(ctx, group1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := principal1.RemoveFromGroup(ctx, group1); !errors.Is(err, subject.ErrNotInGroup) {
+			t.Errorf("Expected %v, got %v", subject.ErrNotInGroup, err)
+		}
+		groups, _ = principal1.Groups(ctx)
+		if expected := []subject.Subject{group2}; !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected %v, got %v", expected, groups)
+		}
+
+		principal2 := subject.Subject{Name: "Principal2", Type: subject.SubjectTypePrincipal}
+		if err := principal1.AddToGroup(ctx, principal2); !errors.Is(err, subject.ErrNotAGroup) {
+			t.Errorf("Expected %v, got %v", subject.ErrNotAGroup, err)
+		}
+		if err := principal1.AddToGroup(ctx, subject.Subject{Name: "Nobody", Type: subject.SubjectTypeGroup}); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectNotFound, err)
+		}
+	})
+
+	t.Run("Error kinds", func(t *testing.T) {
+		if _, err := subject.Get(ctx, "Nobody"); !errors.Is(err, db.ErrNotFound) {
+			t.Errorf("Expected %v, got %v", db.ErrNotFound, err)
+		}
+		principal4 := subject.Subject{Name: "Principal4", Type: subject.SubjectTypePrincipal}
+		if _, err := principal4.CreateAsChildOf(ctx, principal1); !errors.Is(err, subject.ErrNotAGroup) || !errors.Is(err, db.ErrInvalidInput) {
+			t.Errorf("Expected %v, got %v", subject.ErrNotAGroup, err)
+		}
+
+		var kindErr *db.Error
+		if _, err := subject.Get(ctx, "Nobody"); !errors.As(err, &kindErr) || kindErr.Kind != db.ErrNotFound {
+			t.Errorf("Expected an error of kind %v, got %v", db.ErrNotFound, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := principal1.Delete(ctx, db.DeleteRefuse); !errors.Is(err, subject.ErrSubjectInUse) || !errors.Is(err, db.ErrConflict) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectInUse, err)
+		}
+		if err := group2.Delete(ctx, db.DeleteRefuse); !errors.Is(err, subject.ErrSubjectInUse) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectInUse, err)
+		}
+		if err := group1.Delete(ctx, db.DeleteMode("orphan")); !errors.Is(err, db.ErrInvalidDeleteMode) {
+			t.Errorf("Expected %v, got %v", db.ErrInvalidDeleteMode, err)
+		}
+
+		// The members of Group1 join Group2 in its place, and its policies go away with it
+		principal5 := subject.Subject{Name: "Principal5", Type: subject.SubjectTypePrincipal}
+		if err := principal5.AddToGroup(ctx, group1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := group1.Delete(ctx, db.DeleteReparent); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if groups, _ := principal5.Groups(ctx); !reflect.DeepEqual(groups, []subject.Subject{group2}) {
+			t.Errorf("Expected %v, got %v", []subject.Subject{group2}, groups)
+		}
+		if policies, _ := db.GetInstance().GetPolicies(ctx, db.PolicyFilter{SubjectName: "Group1"}); len(policies) != 0 {
+			t.Errorf("Expected the policies of Group1 to be deleted, got %v", policies)
+		}
+
+		if err := principal1.Delete(ctx, db.DeleteCascade); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := subject.Get(ctx, "Principal1"); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectNotFound, err)
+		}
+		if policies, _ := db.GetInstance().GetPolicies(ctx, db.PolicyFilter{SubjectName: "Principal1"}); len(policies) != 0 {
+			t.Errorf("Expected the policies of Principal1 to be deleted, got %v", policies)
+		}
+		if err := principal1.Delete(ctx, db.DeleteCascade); !errors.Is(err, subject.ErrSubjectNotFound) {
+			t.Errorf("Expected %v, got %v", subject.ErrSubjectNotFound, err)
+		}
+	})
+}
+
+func TestSubjectWithoutStore(t *testing.T) {
+	ctx := t.Context()
+	db.SetInstance(nil)
+
+	if _, err := (subject.Subject{Name: "Principal1", Type: subject.SubjectTypePrincipal}).Create(ctx); !errors.Is(err, db.ErrBackendUnavailable) {
+		t.Errorf("Expected %v, got %v", db.ErrBackendUnavailable, err)
+	}
+	if _, err := subject.Get(ctx, "Principal1"); !errors.Is(err, db.ErrNotInitialized) {
+		t.Errorf("Expected %v, got %v", db.ErrNotInitialized, err)
+	}
+}